@@ -0,0 +1,351 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldFlag is a set of flags specifying various characteristics of the field.
+type fieldFlag int
+
+const (
+	// fieldFlagReadOnly : The user may not change the value of the field.
+	fieldFlagReadOnly fieldFlag = 1 << 0
+	// fieldFlagRequired : The field shall have a value at the time it is exported by a submit-form action.
+	fieldFlagRequired fieldFlag = 1 << 1
+	// fieldFlagNoExport : The field shall not be exported by a submit-form action.
+	fieldFlagNoExport fieldFlag = 1 << 2
+)
+
+// formField is a terminal field of the interactive form.
+// A terminal field is merged with its widget annotation, except for a radio button group.
+type formField interface {
+	stringObject
+	// fullName returns the fully qualified field name.
+	fullName() string
+	// setParent sets the parent field and the partial field name.
+	setParent(parent *fieldNode, partialName string)
+	// buildField completes the field with the default resources of the interactive form.
+	buildField(dr *resource) error
+}
+
+// fieldName is the position of a field in the field hierarchy.
+type fieldName struct {
+	name        string
+	partialName string
+	parent      *fieldNode
+}
+
+func newFieldName(name string) fieldName {
+	return fieldName{
+		name:        name,
+		partialName: name,
+	}
+}
+
+func (n *fieldName) fullName() string {
+	return n.name
+}
+
+func (n *fieldName) setParent(parent *fieldNode, partialName string) {
+	n.parent = parent
+	n.partialName = partialName
+}
+
+// entries returns the partial field name and the reference to the parent field.
+func (n *fieldName) entries() []string {
+	dict := []string{fmt.Sprintf("/T %s", textString(n.partialName))}
+	if n.parent != nil {
+		dict = append(dict, fmt.Sprintf("/Parent %s", n.parent.indirectReference()))
+	}
+	return dict
+}
+
+// field provides a common functionality of terminal fields.
+type field struct {
+	annotation
+	fieldName
+	fieldType  string
+	flags      fieldFlag
+	appearance *stream
+}
+
+func newField(fieldType string, name string, page Page, rect *Box) field {
+	return field{
+		annotation: newAnnotation("/Widget", page, rect),
+		fieldName:  newFieldName(name),
+		fieldType:  fieldType,
+		appearance: newFormXObject(rect.rightTopX-rect.leftBottomX, rect.rightTopY-rect.leftBottomY),
+	}
+}
+
+// width is the width of the widget annotation rectangle.
+func (f *field) width() int {
+	return f.rect.rightTopX - f.rect.leftBottomX
+}
+
+// height is the height of the widget annotation rectangle.
+func (f *field) height() int {
+	return f.rect.rightTopY - f.rect.leftBottomY
+}
+
+// bbox is the bounding box of the appearance stream.
+func (f *field) bbox() *Box {
+	return NewBox(0, 0, f.width(), f.height())
+}
+
+// compileHelper compiles the field dictionary merged with the widget annotation.
+func (f *field) compileHelper(entries ...string) string {
+	dict := make([]string, 0, 5+len(entries))
+	dict = append(dict, fmt.Sprintf("/FT %s", f.fieldType))
+	dict = append(dict, f.fieldName.entries()...)
+	if f.flags != 0 {
+		dict = append(dict, fmt.Sprintf("/Ff %d", f.flags))
+	}
+	if f.appearance != nil {
+		dict = append(dict, fmt.Sprintf("/AP <</N %s>>", f.appearance.indirectReference()))
+	}
+	return f.annotation.compileHelper(append(dict, entries...)...)
+}
+
+// variableText is the font of a field containing variable text.
+type variableText struct {
+	font     Font
+	fontSize int
+}
+
+// resolveFont uses the first font of the default resources if the font is not specified.
+// The specified font must be in the default resources, which viewers use to regenerate the appearance.
+func (vt *variableText) resolveFont(kind string, dr *resource) error {
+	if vt.font != nil {
+		if dr == nil || dr.font[vt.font.resourceName()] != vt.font {
+			return fmt.Errorf("%s: font %s is not registered by Builder.AddFont", kind, vt.font.resourceName())
+		}
+		return nil
+	}
+	if dr != nil {
+		if names := dr.fontNames(); len(names) > 0 {
+			vt.font = dr.font[names[0]]
+		}
+	}
+	if vt.font == nil {
+		return fmt.Errorf("%s: font is not specified, and no font is registered by Builder.AddFont", kind)
+	}
+	return nil
+}
+
+// da returns the default appearance string.
+func (vt *variableText) da() string {
+	return fmt.Sprintf("/DA (%s %d Tf 0 g)", vt.font.resourceName(), vt.fontSize)
+}
+
+// fieldNode is a non-terminal field.
+// It is created for each prefix of dotted field names, and has child fields.
+type fieldNode struct {
+	objectIdentifier
+	partialName string
+	parent      *fieldNode
+	kids        []pdfObject
+}
+
+func newFieldNode(partialName string, parent *fieldNode) *fieldNode {
+	return &fieldNode{
+		objectIdentifier: objectIdentifier{},
+		partialName:      partialName,
+		parent:           parent,
+		kids:             make([]pdfObject, 0),
+	}
+}
+
+func (fn *fieldNode) compile() string {
+	dict := make([]string, 0, 3)
+	dict = append(dict, fmt.Sprintf("/T %s", textString(fn.partialName)))
+	if fn.parent != nil {
+		dict = append(dict, fmt.Sprintf("/Parent %s", fn.parent.indirectReference()))
+	}
+	dict = append(dict, fmt.Sprintf("/Kids %s", references(fn.kids)))
+	return fn.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
+}
+
+func (fn *fieldNode) walk(walker func(obj pdfObject)) {
+	walker(fn)
+	walkFields(fn.kids, walker)
+}
+
+// walkFields walks the fields which are not merged with widget annotations.
+// Widget annotations are walked by their pages.
+func walkFields(fields []pdfObject, walker func(obj pdfObject)) {
+	for _, f := range fields {
+		switch n := f.(type) {
+		case *fieldNode:
+			n.walk(walker)
+		case *radioGroup:
+			n.walk(walker)
+		}
+	}
+}
+
+// acroForm is an interactive form dictionary.
+// Terminal fields are collected from the pages when the document is built.
+type acroForm struct {
+	fields    []pdfObject
+	nodes     map[string]*fieldNode
+	terminals map[string]formField
+	// order is the terminal fields in the order in which they were added.
+	order []formField
+	dr    *resource
+	// sigFlags specifies document-level characteristics related to signature fields.
+	sigFlags int
+}
+
+func newAcroForm(dr *resource) *acroForm {
+	return &acroForm{
+		fields:    make([]pdfObject, 0),
+		nodes:     make(map[string]*fieldNode),
+		terminals: make(map[string]formField),
+		order:     make([]formField, 0),
+		dr:        dr,
+	}
+}
+
+// node returns the non-terminal field of the fully qualified name.
+// If the field has not been created, creates it with its ancestors.
+func (af *acroForm) node(name string) (*fieldNode, error) {
+	if n, ok := af.nodes[name]; ok {
+		return n, nil
+	}
+	if _, ok := af.terminals[name]; ok {
+		return nil, fmt.Errorf("form field: terminal field cannot have kids: %s", name)
+	}
+	i := strings.LastIndex(name, ".")
+	var n *fieldNode
+	if i < 0 {
+		n = newFieldNode(name, nil)
+		af.fields = append(af.fields, n)
+	} else {
+		parent, err := af.node(name[:i])
+		if err != nil {
+			return nil, err
+		}
+		n = newFieldNode(name[i+1:], parent)
+		parent.kids = append(parent.kids, n)
+	}
+	af.nodes[name] = n
+	return n, nil
+}
+
+// addField adds the terminal field to the field hierarchy.
+func (af *acroForm) addField(f formField) error {
+	name := f.fullName()
+	_, isNode := af.nodes[name]
+	_, isTerminal := af.terminals[name]
+	if isNode || isTerminal {
+		return fmt.Errorf("form field: duplicate name: %s", name)
+	}
+	if i := strings.LastIndex(name, "."); i < 0 {
+		f.setParent(nil, name)
+		af.fields = append(af.fields, f)
+	} else {
+		parent, err := af.node(name[:i])
+		if err != nil {
+			return err
+		}
+		f.setParent(parent, name[i+1:])
+		parent.kids = append(parent.kids, f)
+	}
+	af.terminals[name] = f
+	af.order = append(af.order, f)
+	if sf, ok := f.(*signatureField); ok {
+		af.sigFlags |= sigFlagSignaturesExist
+		if sf.value != nil {
+			af.sigFlags |= sigFlagAppendOnly
+		}
+	}
+	return nil
+}
+
+// addRadioButton adds the radio button to the radio button group of the same name.
+// If the group has not been added, adds it to the field hierarchy.
+func (af *acroForm) addRadioButton(rb *radioButton) error {
+	if f, ok := af.terminals[rb.name]; ok {
+		group, isGroup := f.(*radioGroup)
+		if !isGroup {
+			return fmt.Errorf("form field: duplicate name: %s", rb.name)
+		}
+		return group.addButton(rb)
+	}
+	group := newRadioGroup(rb.name)
+	if err := af.addField(group); err != nil {
+		return err
+	}
+	return group.addButton(rb)
+}
+
+// keepNumbers gives the non-terminal fields and the radio button groups the object numbers of the fields
+// of the same names in the previous form, so that an incremental update does not write them as new objects.
+func (af *acroForm) keepNumbers(previous *acroForm) {
+	for name, n := range af.nodes {
+		if p, ok := previous.nodes[name]; ok {
+			n.objectIdentifier = p.objectIdentifier
+		}
+	}
+	for name, f := range af.terminals {
+		g, isGroup := f.(*radioGroup)
+		p, wasGroup := previous.terminals[name].(*radioGroup)
+		if isGroup && wasGroup {
+			g.objectIdentifier = p.objectIdentifier
+		}
+	}
+}
+
+// build builds the terminal fields after all fields have been added.
+func (af *acroForm) build() error {
+	for _, f := range af.order {
+		if err := f.buildField(af.dr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultFont returns the font used when the font of the field is not specified.
+func (af *acroForm) defaultFont() Font {
+	if af.dr == nil {
+		return nil
+	}
+	names := af.dr.fontNames()
+	if len(names) == 0 {
+		return nil
+	}
+	return af.dr.font[names[0]]
+}
+
+func (af *acroForm) compile() string {
+	dict := make([]string, 0, 4)
+	dict = append(dict, fmt.Sprintf("/Fields %s", references(af.fields)))
+	if af.dr != nil {
+		dict = append(dict, fmt.Sprintf("/DR %s", af.dr.indirectReference()))
+	}
+	if f := af.defaultFont(); f != nil {
+		dict = append(dict, fmt.Sprintf("/DA (%s 0 Tf 0 g)", f.resourceName()))
+	}
+	if af.sigFlags != 0 {
+		dict = append(dict, fmt.Sprintf("/SigFlags %d", af.sigFlags))
+	}
+	return fmt.Sprintf("<<%s>>", strings.Join(dict, " "))
+}
+
+func (af *acroForm) walk(walker func(obj pdfObject)) {
+	if af != nil {
+		walkFields(af.fields, walker)
+	}
+}
+
+// references returns the array of indirect references to the objects.
+func references(objs []pdfObject) string {
+	refs := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		refs = append(refs, obj.indirectReference())
+	}
+	return fmt.Sprintf("[%s]", strings.Join(refs, " "))
+}
@@ -0,0 +1,132 @@
+package pdf
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestDefaultResource returns the default resource in which the fonts are registered.
+func newTestDefaultResource(fonts ...Font) *resource {
+	dr := newResource()
+	for _, f := range fonts {
+		dr.addFont(f)
+	}
+	return dr
+}
+
+func TestAcroFormHierarchy(t *testing.T) {
+	p := newTestPage()
+	af := newAcroForm(nil)
+	city := newTextField("address.city", p, NewBox(0, 0, 10, 10))
+	zip := newTextField("address.zip", p, NewBox(0, 0, 10, 10))
+	name := newTextField("name", p, NewBox(0, 0, 10, 10))
+	f := newFontType1("/F0", "/Helvetica")
+	for _, tf := range []*textField{city, zip, name} {
+		tf.Font(f, 10)
+		if err := af.addField(tf); err != nil {
+			t.Fatalf("addField: unexpected error:%s", err)
+		}
+	}
+	if len(af.fields) != 2 {
+		t.Fatalf("addField: root field size: expected:2 actual:%d", len(af.fields))
+	}
+	address, ok := af.fields[0].(*fieldNode)
+	if !ok {
+		t.Fatal("addField: non-terminal field is not created")
+	}
+	if city.parent != address || zip.parent != address || name.parent != nil {
+		t.Error("addField: parent is not set")
+	}
+	testCompillation(t, "city", city.partialName)
+	testCompillation(t, "name", name.partialName)
+	address.objectNumber = 5
+	city.objectNumber = 6
+	zip.objectNumber = 7
+	testCompillation(t, "5 0 obj\n<</T (address) /Kids [6 0 R 7 0 R]>>\nendobj\n", address.compile())
+	if err := af.addField(newTextField("name", p, NewBox(0, 0, 10, 10))); err == nil {
+		t.Error("addField: duplicate name must be error")
+	}
+	if err := af.addField(newTextField("address", p, NewBox(0, 0, 10, 10))); err == nil {
+		t.Error("addField: name of non-terminal field must be error")
+	}
+	if err := af.addField(newTextField("name.first", p, NewBox(0, 0, 10, 10))); err == nil {
+		t.Error("addField: terminal field must not have kids")
+	}
+	nums := 0
+	af.walk(func(obj pdfObject) {
+		nums++
+	})
+	if nums != 1 {
+		t.Errorf("walk: only non-terminal fields must be walked: %d", nums)
+	}
+}
+
+func TestAcroFormCompile(t *testing.T) {
+	dr := newResource()
+	dr.objectNumber = 4
+	f := newFontType1("/F0", "/Helvetica")
+	dr.addFont(f)
+	af := newAcroForm(dr)
+	tf := newTextField("name", newTestPage(), NewBox(0, 0, 10, 10))
+	tf.objectNumber = 5
+	if err := af.addField(tf); err != nil {
+		t.Fatalf("addField: unexpected error:%s", err)
+	}
+	if err := af.build(); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	if tf.font != f {
+		t.Error("buildField: default font is not used")
+	}
+	testCompillation(t, "<</Fields [5 0 R] /DR 4 0 R /DA (/F0 0 Tf 0 g)>>", af.compile())
+}
+
+func TestBuilderAcroForm(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddFont(b.NewFontType1("/Helvetica"))
+	p1 := b.AddPage()
+	p2 := b.AddPage()
+	p1.TextField("person.name", 0, 0, 100, 20)
+	p2.TextField("person.mail", 0, 0, 100, 20)
+	p1.Link(0, 0, 10, 10, LinkURI("https://example.com"))
+	if err := b.build(); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	if b.dc.form == nil || len(b.dc.form.fields) != 1 {
+		t.Fatal("build: form fields are not collected")
+	}
+	if b.dc.form.fields[0].refNo() == 0 {
+		t.Error("build: non-terminal field is not numbered")
+	}
+}
+
+func TestBuilderAcroFormWithoutFont(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage().TextField("name", 0, 0, 100, 20)
+	if err := b.build(); err == nil {
+		t.Error("build: text field without fonts must be error")
+	}
+}
+
+func TestBuilderAcroFormUnregisteredFont(t *testing.T) {
+	for _, c := range []struct {
+		add      func(p Page, f Font)
+		expected string
+	}{
+		{func(p Page, f Font) { p.TextField("name", 0, 0, 100, 20).Font(f, 10) }, "text field: font /F1 is not registered by Builder.AddFont"},
+		{func(p Page, f Font) { p.ComboBox("country", 0, 0, 100, 20).Font(f, 10) }, "choice field: font /F1 is not registered by Builder.AddFont"},
+	} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		b.AddFont(b.NewFontType1("/Helvetica"))
+		c.add(b.AddPage(), b.NewFontType1("/Courier"))
+		if err := b.build(); err == nil || err.Error() != c.expected {
+			t.Errorf("build: unexpected error:%v", err)
+		}
+	}
+	sf := newSignatureField("approval", newTestPage(), NewBox(0, 0, 100, 40))
+	sf.Font(newFontType1("/F1", "/Courier"), 8)
+	sf.sign("signer", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), 10)
+	if err := sf.buildField(newTestDefaultResource(newFontType1("/F0", "/Helvetica"))); err == nil || err.Error() != "signature field: font /F1 is not registered by Builder.AddFont" {
+		t.Errorf("buildField: unexpected error:%v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Annotation is an object associated with a location on a page.
+type Annotation interface {
+	stringObject
+	traversableObject
+}
+
+// annotationFlag is a set of flags specifying various characteristics of the annotation.
+type annotationFlag int
+
+const (
+	// annotationFlagInvisible : Do not display the annotation if it does not belong to one of the standard annotation types.
+	annotationFlagInvisible annotationFlag = 1 << 0
+	// annotationFlagHidden : Do not display or print the annotation.
+	annotationFlagHidden annotationFlag = 1 << 1
+	// annotationFlagPrint : Print the annotation when the page is printed.
+	annotationFlagPrint annotationFlag = 1 << 2
+	// annotationFlagNoZoom : Do not scale the annotation’s appearance to match the magnification of the page.
+	annotationFlagNoZoom annotationFlag = 1 << 3
+	// annotationFlagNoRotate : Do not rotate the annotation’s appearance to match the rotation of the page.
+	annotationFlagNoRotate annotationFlag = 1 << 4
+	// annotationFlagReadOnly : Do not allow the annotation to interact with the user.
+	annotationFlagReadOnly annotationFlag = 1 << 6
+	// annotationFlagLocked : Do not allow the annotation to be deleted or its properties to be modified by the user.
+	annotationFlagLocked annotationFlag = 1 << 7
+)
+
+// annotation provides a common functionality of annotation dictionaries.
+type annotation struct {
+	objectIdentifier
+	subtype  string
+	page     Page
+	rect     *Box
+	flags    annotationFlag
+	color    Color
+	contents string
+}
+
+func newAnnotation(subtype string, page Page, rect *Box) annotation {
+	return annotation{
+		objectIdentifier: objectIdentifier{},
+		subtype:          subtype,
+		page:             page,
+		rect:             rect,
+		flags:            annotationFlagPrint,
+		color:            newColorUndef(),
+	}
+}
+
+// dict returns the entries common to all annotation dictionaries.
+func (a *annotation) dict() []string {
+	dict := make([]string, 0, 10)
+	dict = append(dict, fmt.Sprintf("/Type /Annot /Subtype %s /Rect %s", a.subtype, a.rect.compile()))
+	if a.page != nil {
+		dict = append(dict, fmt.Sprintf("/P %s", a.page.indirectReference()))
+	}
+	if a.flags != 0 {
+		dict = append(dict, fmt.Sprintf("/F %d", a.flags))
+	}
+	if a.color.colorSpace() != colorSpaceUndefined {
+		dict = append(dict, fmt.Sprintf("/C %s", a.color.components()))
+	}
+	if a.contents != "" {
+		dict = append(dict, fmt.Sprintf("/Contents %s", textString(a.contents)))
+	}
+	return dict
+}
+
+// compileHelper compiles the annotation dictionary with the subtype-specific entries.
+func (a *annotation) compileHelper(entries ...string) string {
+	dict := append(a.dict(), entries...)
+	return a.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
+}
+
+// BorderStyle is a style of the annotation border.
+type BorderStyle int
+
+const (
+	// BorderStyleSolid : A solid rectangle surrounding the annotation.
+	BorderStyleSolid BorderStyle = iota
+	// BorderStyleDashed : A dashed rectangle surrounding the annotation.
+	BorderStyleDashed
+	// BorderStyleBeveled : A simulated embossed rectangle that appears to be raised above the surface of the page.
+	BorderStyleBeveled
+	// BorderStyleInset : A simulated engraved rectangle that appears to be recessed below the surface of the page.
+	BorderStyleInset
+	// BorderStyleUnderline : A single line along the bottom of the annotation rectangle.
+	BorderStyleUnderline
+)
+
+func (bs BorderStyle) compile() string {
+	switch bs {
+	case BorderStyleDashed:
+		return "/D"
+	case BorderStyleBeveled:
+		return "/B"
+	case BorderStyleInset:
+		return "/I"
+	case BorderStyleUnderline:
+		return "/U"
+	default:
+		return "/S"
+	}
+}
+
+// border is a border style dictionary.
+type border struct {
+	width int
+	style BorderStyle
+}
+
+func (b *border) compile() string {
+	return fmt.Sprintf("<</W %d /S %s>>", b.width, b.style.compile())
+}
+
+// HighlightMode is the visual effect when the mouse button is pressed or held down inside the annotation.
+type HighlightMode int
+
+const (
+	// HighlightModeInvert : Invert the contents of the annotation rectangle.
+	HighlightModeInvert HighlightMode = iota
+	// HighlightModeNone : No highlighting.
+	HighlightModeNone
+	// HighlightModeOutline : Invert the annotation’s border.
+	HighlightModeOutline
+	// HighlightModePush : Display the annotation as if it were being pushed below the surface of the page.
+	HighlightModePush
+)
+
+func (hm HighlightMode) compile() string {
+	switch hm {
+	case HighlightModeNone:
+		return "/N"
+	case HighlightModeOutline:
+		return "/O"
+	case HighlightModePush:
+		return "/P"
+	default:
+		return "/I"
+	}
+}
+
+// LinkTarget is a destination or an action of a link annotation.
+type LinkTarget interface {
+	compile() string
+}
+
+type uriLinkTarget struct {
+	uri string
+}
+
+// LinkURI returns a LinkTarget that resolves the uniform resource identifier.
+func LinkURI(uri string) LinkTarget {
+	return &uriLinkTarget{uri}
+}
+
+func (t *uriLinkTarget) compile() string {
+	return fmt.Sprintf("/A <</S /URI /URI %s>>", literalString(t.uri))
+}
+
+type pageLinkTarget struct {
+	page     Page
+	destType OutlineDestination
+}
+
+// LinkPage returns a LinkTarget that jumps to the page.
+func LinkPage(page Page, destType OutlineDestination) LinkTarget {
+	return &pageLinkTarget{page, destType}
+}
+
+func (t *pageLinkTarget) compile() string {
+	return fmt.Sprintf("/A <</S /GoTo /D %s>>", explicitDestination(t.page, t.destType))
+}
+
+type namedLinkTarget struct {
+	name string
+}
+
+// LinkNamed returns a LinkTarget that jumps to the named destination.
+// The destination must be registered by Page.AddDestination, otherwise Build returns an error.
+func LinkNamed(name string) LinkTarget {
+	return &namedLinkTarget{name}
+}
+
+func (t *namedLinkTarget) compile() string {
+	return fmt.Sprintf("/A <</S /GoTo /D %s>>", textString(t.name))
+}
+
+// Link is a link annotation.
+// A link annotation represents either a hypertext link to a destination elsewhere in the document or an action to be performed.
+type Link interface {
+	Annotation
+	// Border specifies the width of the border. If the width is 0, no border is drawn.
+	Border(width int) Link
+	// BorderStyle specifies the style of the border.
+	BorderStyle(style BorderStyle) Link
+	// Color specifies the color of the border.
+	Color(c Color) Link
+	// Highlight specifies the visual effect when the link is clicked.
+	Highlight(mode HighlightMode) Link
+}
+
+// link is a implementation of a Link interface.
+type link struct {
+	annotation
+	target    LinkTarget
+	border    *border
+	highlight HighlightMode
+}
+
+// newLink returns a link annotation without border.
+func newLink(page Page, rect *Box, target LinkTarget) *link {
+	return &link{
+		annotation: newAnnotation("/Link", page, rect),
+		target:     target,
+		border:     &border{0, BorderStyleSolid},
+		highlight:  HighlightModeInvert,
+	}
+}
+
+func (l *link) Border(width int) Link {
+	l.border.width = width
+	return l
+}
+
+func (l *link) BorderStyle(style BorderStyle) Link {
+	l.border.style = style
+	return l
+}
+
+func (l *link) Color(c Color) Link {
+	l.color = c
+	return l
+}
+
+func (l *link) Highlight(mode HighlightMode) Link {
+	l.highlight = mode
+	return l
+}
+
+func (l *link) compile() string {
+	return l.compileHelper(
+		l.target.compile(),
+		fmt.Sprintf("/BS %s", l.border.compile()),
+		fmt.Sprintf("/H %s", l.highlight.compile()))
+}
+
+func (l *link) walk(walker func(obj pdfObject)) {
+	walker(l)
+}
@@ -0,0 +1,67 @@
+package pdf
+
+import "testing"
+
+func TestBorderStyle(t *testing.T) {
+	styles := map[BorderStyle]string{
+		BorderStyleSolid:     "/S",
+		BorderStyleDashed:    "/D",
+		BorderStyleBeveled:   "/B",
+		BorderStyleInset:     "/I",
+		BorderStyleUnderline: "/U",
+	}
+	for bs, expected := range styles {
+		testCompillation(t, expected, bs.compile())
+	}
+}
+
+func TestHighlightMode(t *testing.T) {
+	modes := map[HighlightMode]string{
+		HighlightModeInvert:  "/I",
+		HighlightModeNone:    "/N",
+		HighlightModeOutline: "/O",
+		HighlightModePush:    "/P",
+	}
+	for hm, expected := range modes {
+		testCompillation(t, expected, hm.compile())
+	}
+}
+
+func TestLinkTarget(t *testing.T) {
+	p := &mockPage{}
+	p.objectNumber = 3
+	testCompillation(t, "/A <</S /URI /URI (https://example.com/a\\(1\\))>>", LinkURI("https://example.com/a(1)").compile())
+	testCompillation(t, "/A <</S /GoTo /D [3 0 R /Fit]>>", LinkPage(p, OutlineDestinationBasic()).compile())
+	testCompillation(t, "/A <</S /GoTo /D (chapter-3)>>", LinkNamed("chapter-3").compile())
+}
+
+func TestLink(t *testing.T) {
+	pl := newRootPage(NewBox(0, 0, 100, 200), NewBox(10, 20, 90, 180))
+	p := pl.newPage(nil, nil, nil)
+	p.(*page).objectNumber = 3
+	l := p.Link(5, 10, 30, 20, LinkURI("https://example.com"))
+	if len(p.annotations()) != 1 || p.annotations()[0] != l {
+		t.Fatal("Link: link is not added to the page")
+	}
+	lnk := l.(*link)
+	lnk.objectNumber = 4
+	isEqualBox(t, 15, 150, 45, 170, lnk.rect)
+	expected1 := "4 0 obj\n<</Type /Annot /Subtype /Link /Rect [15 150 45 170] /P 3 0 R /F 4 /A <</S /URI /URI (https://example.com)>> /BS <</W 0 /S /S>> /H /I>>\nendobj\n"
+	testCompillation(t, expected1, lnk.compile())
+	l.Border(2).BorderStyle(BorderStyleDashed).Color(NewColorRGB(1, 0, 0)).Highlight(HighlightModeOutline)
+	expected2 := "4 0 obj\n<</Type /Annot /Subtype /Link /Rect [15 150 45 170] /P 3 0 R /F 4 /C [1.000000 0.000000 0.000000] /A <</S /URI /URI (https://example.com)>> /BS <</W 2 /S /D>> /H /O>>\nendobj\n"
+	testCompillation(t, expected2, lnk.compile())
+	p.(*page).contents.objectNumber = 5
+	actual := p.compile()
+	expected3 := "3 0 obj\n<</Type /Page /Parent 2 0 R /Contents [5 0 R] /Annots [4 0 R]>>\nendobj\n"
+	testCompillation(t, expected3, actual)
+	walked := false
+	p.walk(func(obj pdfObject) {
+		if obj == l {
+			walked = true
+		}
+	})
+	if !walked {
+		t.Error("walk: link is not walked")
+	}
+}
@@ -45,6 +45,12 @@ func (r *Box) NewInnerBox(topMargin, rightMargin, bottomMargin, leftMargin int)
 	return NewBox(r.leftBottomX+leftMargin, r.leftBottomY+bottomMargin, r.rightTopX-rightMargin, r.rightTopY-topMargin)
 }
 
+// contains returns whether the box contains the other box.
+func (r *Box) contains(other *Box) bool {
+	return r.leftBottomX <= other.leftBottomX && r.leftBottomY <= other.leftBottomY &&
+		other.rightTopX <= r.rightTopX && other.rightTopY <= r.rightTopY
+}
+
 func (r *Box) compile() string {
 	return fmt.Sprintf("[%d %d %d %d]", r.leftBottomX, r.leftBottomY, r.rightTopX, r.rightTopY)
 }
@@ -37,3 +37,13 @@ func TestBox(t *testing.T) {
 	actual := inner.compile()
 	testCompillation(t, expected, actual)
 }
+
+func TestBoxContains(t *testing.T) {
+	b := NewBox(10, 20, 30, 40)
+	if !b.contains(b) || !b.contains(NewBox(11, 21, 29, 39)) {
+		t.Error("contains: inner box must be contained")
+	}
+	if b.contains(NewBox(9, 21, 29, 39)) || b.contains(NewBox(11, 21, 29, 41)) {
+		t.Error("contains: outer box must not be contained")
+	}
+}
@@ -1,15 +1,26 @@
 package pdf
 
 import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	pdfVersion    = "1.7"
 	pageTreeOrder = 6
+	// objectStreamVersion is the lowest version which supports object streams and cross-reference streams.
+	objectStreamVersion = "1.5"
+	// pdfxHighestVersion is the highest version which PDF/X-4 allows.
+	pdfxHighestVersion = "1.6"
 )
 
 // Builder is a pdf builder.
@@ -21,6 +32,58 @@ type Builder struct {
 	fnm     *fontNameManager
 	inm     *imageNameManager
 	order   int
+	info    *documentInfo
+	now     func() time.Time
+	// reproducible is true if the output depends only on the input.
+	reproducible bool
+	// random is the source of encryption keys, salts and initialization vectors.
+	random     io.Reader
+	encryption *encryption
+	// id is the file identifier determined before writing, or nil.
+	id []byte
+	// trailer is the trailer of the written document, or nil.
+	trailer *trailer
+	// timestamped is true if a document timestamp has been embedded.
+	// Its signature field cannot be written again by Build, since the timestamp is not issued again.
+	timestamped bool
+	// signed is true if a signature has been embedded.
+	// Its signature field cannot be written again by Build, since the signature is not embedded again.
+	signed bool
+	// revision are the digests of the objects in the written document by their numbers, or nil.
+	revision map[int][md5.Size]byte
+	// files are the attached files in the order in which they were attached.
+	files []*embeddedFile
+	// conformance is the standard to which the document conforms.
+	conformance Conformance
+	// outputCondition is the printing condition of PDF/X, or nil if it is not specified.
+	outputCondition *OutputCondition
+	// facturX is the profile of the attached Factur-X invoice.
+	facturX FacturXProfile
+	// objectStreams is true if the objects are packed into object streams.
+	objectStreams bool
+	// stream is the state of the streaming output, or nil if the document is written at once.
+	stream *streamState
+	// linearization is the layout of the linearized document, or nil.
+	linearization *linearization
+}
+
+// BuildOption is an option of Builder.Build.
+type BuildOption func(o *buildOptions)
+
+// buildOptions are the options specified to Builder.Build.
+type buildOptions struct {
+	encryption Encryption
+	signature  *signature
+	timestamp  *documentTimestamp
+	linearized bool
+}
+
+// WithEncryption encrypts the document with the security handler.
+// Encrypted documents are not reproducible, since random salts and initialization vectors are used.
+func WithEncryption(e Encryption) BuildOption {
+	return func(o *buildOptions) {
+		o.encryption = e
+	}
 }
 
 // NewBuilder returns a Builder.
@@ -33,6 +96,9 @@ func NewBuilder(mb, cb *Box) *Builder {
 		fnm:     newFontNameManager(),
 		inm:     newImageNameManager(),
 		order:   pageTreeOrder,
+		info:    nil,
+		now:     time.Now,
+		random:  rand.Reader,
 	}
 	b.dc.pages.resource = newResource()
 	return b
@@ -70,6 +136,34 @@ func (b *Builder) AddImage(i *ImageResource) {
 	b.dc.pages.resource.addImage(i)
 }
 
+// AddLayer declares the layer, whose contents are shown when the document is opened if visible is true.
+// The layer is added to default resource.
+func (b *Builder) AddLayer(name string, visible bool) Layer {
+	ocg := newOptionalContentGroup(name, fmt.Sprintf("/OC%d", len(b.dc.layers)), visible)
+	b.dc.layers = append(b.dc.layers, ocg)
+	b.dc.pages.resource.addProperties(ocg.resourceName, ocg)
+	return ocg
+}
+
+// AttachFile embeds the file read from r in the document with its name and MIME type such as "text/csv".
+// The file is compressed, and listed in the attachments of the document.
+// It may also be shown on a page by Page.FileAttachment.
+func (b *Builder) AttachFile(name, mimeType string, r io.Reader) (EmbeddedFile, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("embedded file: %s", err)
+	}
+	ef := newEmbeddedFile(name, mimeType, data)
+	if b.dc.names.embeddedFiles == nil {
+		b.dc.names.embeddedFiles = newNameTree()
+	}
+	if err := b.dc.names.embeddedFiles.add(name, &reference{ef}); err != nil {
+		return nil, err
+	}
+	b.files = append(b.files, ef)
+	return ef, nil
+}
+
 // AddPage adds the new Page.
 func (b *Builder) AddPage() Page {
 	return b.AddPageWithBox(nil, nil)
@@ -77,28 +171,208 @@ func (b *Builder) AddPage() Page {
 
 // AddPageWithBox adds the new Page with the specified box.
 func (b *Builder) AddPageWithBox(mb, cb *Box) Page {
+	if b.stream != nil {
+		p := newPage(b.dc.pages, mb, cb, nil)
+		b.addStreamedPage(p)
+		return p
+	}
 	return b.dc.pages.newPage(mb, cb, nil)
 }
 
+// SetDefaultBleedBox specifies the bleed box of the pages which do not specify it.
+func (b *Builder) SetDefaultBleedBox(box *Box) {
+	b.dc.pages.SetBleedBox(box)
+}
+
+// SetDefaultTrimBox specifies the trim box of the pages which do not specify it.
+func (b *Builder) SetDefaultTrimBox(box *Box) {
+	b.dc.pages.SetTrimBox(box)
+}
+
+// SetDefaultArtBox specifies the art box of the pages which do not specify it.
+func (b *Builder) SetDefaultArtBox(box *Box) {
+	b.dc.pages.SetArtBox(box)
+}
+
+// SetDefaultRotate specifies the rotation of the pages which do not specify it.
+func (b *Builder) SetDefaultRotate(degrees int) {
+	b.dc.pages.SetRotate(degrees)
+}
+
+// SetDefaultUserUnit specifies the user unit of the pages which do not specify it.
+func (b *Builder) SetDefaultUserUnit(unit float64) {
+	b.dc.pages.SetUserUnit(unit)
+}
+
 // Outline returns a document outline.
 // If a outline has not been created, creates new outline and returns it.
 func (b *Builder) Outline() Outline {
 	return b.dc.Outline()
 }
 
+// AddPageLabel labels the page and the following pages until the next labeled page.
+// Numbers of labels begin with start, and prefix is prepended to them.
+func (b *Builder) AddPageLabel(page Page, style PageLabelStyle, prefix string, start int) {
+	b.dc.pageLabels().add(page, style, prefix, start)
+}
+
+// SetPageMode specifies how the document is displayed when opened.
+func (b *Builder) SetPageMode(pm PageMode) {
+	b.dc.pageMode = pm
+}
+
+// SetPageLayout specifies the page layout when the document is opened.
+func (b *Builder) SetPageLayout(pl PageLayout) {
+	b.dc.pageLayout = pl
+}
+
+// SetViewerPreferences specifies the way the document is presented on the screen or in print.
+func (b *Builder) SetViewerPreferences(vp ViewerPreferences) {
+	b.dc.viewerPreferences = &vp
+}
+
+// SetOpenAction specifies the page and the destination displayed when the document is opened.
+func (b *Builder) SetOpenAction(page Page, destType OutlineDestination) {
+	b.dc.openAction = &openAction{page, destType}
+}
+
+// StructureTree returns the document structure element, which is the root of the logical structure.
+// If the logical structure has not been created, creates it and the document becomes a tagged pdf.
+func (b *Builder) StructureTree() StructureElement {
+	if b.dc.structTree == nil {
+		b.dc.structTree = newStructTreeRoot()
+	}
+	return b.dc.structTree.document
+}
+
+// SetLanguage specifies the natural language of the texts in the document, such as "en-US".
+func (b *Builder) SetLanguage(lang string) {
+	b.dc.lang = lang
+}
+
+// SetInfo sets the document information.
+// The same values are written to the document information dictionary and the XMP metadata stream.
+func (b *Builder) SetInfo(info DocumentInfo) {
+	b.info = newDocumentInfo(info)
+}
+
+// SetConformance makes the document conform to the standard.
+// Build emits the objects required by the standard, and returns an error if the document cannot conform to it.
+// All fonts must be embedded, and the document must not be encrypted.
+func (b *Builder) SetConformance(c Conformance) {
+	b.conformance = c
+}
+
+// SetOutputCondition specifies the printing condition of PDF/X, whose profile is embedded as the output intent.
+// The condition is usually provided by the print vendor, and is required by PDF/X.
+func (b *Builder) SetOutputCondition(oc *OutputCondition) {
+	b.outputCondition = oc
+	// The output intent is made again for the condition.
+	b.dc.outputIntents = nil
+}
+
+// SetObjectStreams makes the output smaller by packing the objects other than streams into compressed object streams,
+// and writing the cross-reference stream instead of the cross-reference table.
+// The output requires pdf 1.5 or later.
+func (b *Builder) SetObjectStreams(enabled bool) {
+	b.objectStreams = enabled
+}
+
+// SetReproducible makes the output byte-identical for identical input.
+// Dates which are not specified explicitly are fixed to the argument date,
+// and the file identifier is derived only from the content.
+func (b *Builder) SetReproducible(date time.Time) {
+	b.reproducible = true
+	b.now = func() time.Time { return date }
+}
+
 // Build creates a pdf.
-func (b *Builder) Build(w io.Writer) error {
+func (b *Builder) Build(w io.Writer, options ...BuildOption) error {
+	if b.stream != nil {
+		return errors.New("streaming: document is being written, use FinishStreaming")
+	}
+	if b.timestamped {
+		return errors.New("timestamp: document has been time-stamped, use Update to append revisions")
+	}
+	if b.signed {
+		return errors.New("signature: document has been signed, use Update to append revisions")
+	}
+	o := &buildOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	if o.signature != nil {
+		if err := o.signature.prepare(b.now()); err != nil {
+			return err
+		}
+	}
+	if o.encryption != nil && b.conformance != conformanceUndefined {
+		return fmt.Errorf("%s: encryption is not allowed", b.conformance)
+	}
+	if o.timestamp != nil && o.timestamp.tsa == nil {
+		return errors.New("timestamp: time-stamp authority is not specified")
+	}
+	if o.linearized && b.objectStreams {
+		return errors.New("linearization: object streams are not supported")
+	}
 	err := b.build()
 	if err != nil {
 		return err
 	}
-	return b.write(w)
+	if o.encryption != nil {
+		if err := b.buildEncryption(o.encryption); err != nil {
+			return err
+		}
+	}
+	b.linearization = nil
+	if o.linearized {
+		if err := b.linearize(); err != nil {
+			return err
+		}
+	}
+	if o.signature == nil && o.timestamp == nil {
+		return b.write(w)
+	}
+	// The document is signed after it is written, since the signature covers the written bytes.
+	var buf bytes.Buffer
+	if err := b.write(&buf); err != nil {
+		return err
+	}
+	pdf := buf.Bytes()
+	if o.signature != nil {
+		if pdf, err = o.signature.sign(pdf, b.random); err != nil {
+			return err
+		}
+		b.signed = true
+	}
+	if o.timestamp != nil {
+		if pdf, err = o.timestamp.update(b, pdf); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(pdf)
+	return err
 }
 
 func (b *Builder) build() error {
-	b.dc.pages.buildPageTree(b.order)
+	if b.stream != nil {
+		// The leaves of the page tree have been made while the pages were added.
+		b.dc.pages.buildSubPageTree(b.order)
+	} else {
+		b.dc.pages.buildPageTree(b.order)
+	}
+	if err := b.dc.build(); err != nil {
+		return err
+	}
+	now := b.now()
+	b.buildConformance()
+	b.buildMetadata(now)
+	b.buildAnnotations(now)
+	for _, f := range b.files {
+		f.build(now)
+	}
 	errs := make([]string, 0)
-	b.dc.walk(func(obj pdfObject) {
+	walker := func(obj pdfObject) {
 		obj.number(b.c)
 		if font, isFont := obj.(Font); isFont {
 			err := font.build()
@@ -106,18 +380,123 @@ func (b *Builder) build() error {
 				errs = append(errs, err.Error())
 			}
 		}
-	})
+	}
+	b.dc.walk(walker)
+	b.info.walk(walker)
+	b.stream.walk(walker)
+	errs = append(errs, b.validateConformance()...)
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, ", "))
 	}
 	return nil
 }
 
-func (b *Builder) write(w io.Writer) (err error) {
-	return newWriter(w).
-		start(b.version).
-		writeTraversable(b.dc).
-		finish(b.dc.objectIdentifier)
+// buildMetadata completes the document information and synchronizes the XMP metadata with it.
+func (b *Builder) buildMetadata(now time.Time) {
+	if b.info == nil {
+		return
+	}
+	b.info.fillDates(now)
+	m := newXMPMetadataFromInfo(b.info.info)
+	b.buildConformanceMetadata(m)
+	s := m.asStream()
+	if b.dc.metadata != nil {
+		// The metadata of the written document is replaced by an incremental update.
+		s.objectIdentifier = b.dc.metadata.objectIdentifier
+	}
+	b.dc.metadata = s
+}
+
+// buildAnnotations completes the annotations on the pages.
+func (b *Builder) buildAnnotations(now time.Time) {
+	for _, p := range b.dc.pages.allPages() {
+		for _, a := range p.annotations() {
+			if ab, ok := a.(annotationBuilder); ok {
+				ab.build(now)
+			}
+		}
+	}
+}
+
+func (b *Builder) write(w io.Writer) error {
+	if b.linearization != nil {
+		return b.writeLinearized(w)
+	}
+	return b.finish(b.newWriter(w).start(b.headerVersion()))
+}
+
+// finish writes the objects which have not been written by the writer, and the trailer.
+func (b *Builder) finish(pw *writer) error {
+	t := newTrailer(b.dc.objectIdentifier, 0, 0)
+	if b.info != nil {
+		t.info = b.info
+	}
+	if b.encryption != nil {
+		t.encrypt = b.encryption
+	}
+	pw.writeTraversable(b.dc).
+		writeTraversable(b.info).
+		writeTraversable(b.stream).
+		writeTraversable(b.encryption)
+	id := b.id
+	if id == nil {
+		id = b.fileID(pw.digest.Sum(nil))
+	}
+	t.id = [][]byte{id, id}
+	b.trailer = t
+	b.revision = pw.digests
+	return pw.finishWith(t)
+}
+
+// newWriter returns the writer of the document, which encrypts and packs the objects if they are specified.
+func (b *Builder) newWriter(w io.Writer) *writer {
+	pw := newWriter(w).encrypt(b.encryption)
+	if b.objectStreams {
+		pw.packObjects(b.c)
+	}
+	return pw
+}
+
+// headerVersion returns the version of the header, which is raised if the output requires it,
+// and is lowered if the conformance does not allow it.
+func (b *Builder) headerVersion() string {
+	if b.objectStreams && b.version < objectStreamVersion {
+		return objectStreamVersion
+	}
+	if b.conformance.isPDFX() && b.version > pdfxHighestVersion {
+		return pdfxHighestVersion
+	}
+	return b.version
+}
+
+// buildEncryption determines the file identifier, and computes the encryption dictionary for it.
+// The identifier cannot be derived from the content, since the encryption key depends on it.
+func (b *Builder) buildEncryption(e Encryption) error {
+	nonce := make([]byte, 16)
+	if _, err := io.ReadFull(b.random, nonce); err != nil {
+		return err
+	}
+	b.id = b.fileID(nonce)
+	enc, err := e.newEncryption(b.id, b.random)
+	if err != nil {
+		return err
+	}
+	enc.number(b.c)
+	b.encryption = enc
+	b.dc.extensionLevel = enc.extensionLevel
+	return nil
+}
+
+// fileID returns the file identifier from the digest of the content.
+// Unless the builder is reproducible, the time of creation is also mixed in the identifier
+// so that different files have different identifiers.
+func (b *Builder) fileID(digest []byte) []byte {
+	h := md5.New()
+	h.Write(digest)
+	if !b.reproducible {
+		binary.Write(h, binary.BigEndian, b.now().UnixNano())
+	}
+	return h.Sum(nil)
 }
 
 type fontNameManager struct {
@@ -1,8 +1,13 @@
 package pdf
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuilder(t *testing.T) {
@@ -53,3 +58,181 @@ func ExampleBuilder() {
 	defer f.Close()
 	b.Build(f)
 }
+
+func TestBuilderInfo(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	b.SetInfo(DocumentInfo{Title: "title"})
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	actual := buf.String()
+	info := fmt.Sprintf("%d 0 obj\n<</Title (title) /CreationDate (D:20180102030405Z) /ModDate (D:20180102030405Z)>>\nendobj\n", b.info.refNo())
+	if !strings.Contains(actual, info) {
+		t.Errorf("Build: info dictionary is not written:\n%s", actual)
+	}
+	if !strings.Contains(actual, fmt.Sprintf("/Info %d 0 R", b.info.refNo())) {
+		t.Error("Build: info dictionary is not referred from the trailer")
+	}
+	if !strings.Contains(actual, fmt.Sprintf("/Metadata %d 0 R", b.dc.metadata.refNo())) {
+		t.Error("Build: metadata stream is not referred from the catalog")
+	}
+	if !strings.Contains(actual, "<xmp:CreateDate>2018-01-02T03:04:05Z</xmp:CreateDate>") {
+		t.Error("Build: metadata stream is not synchronized with info")
+	}
+}
+
+func buildReproducible(t *testing.T) []byte {
+	t.Helper()
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetReproducible(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	b.SetInfo(DocumentInfo{Title: "title"})
+	for i := 0; i < 10; i++ {
+		b.AddFont(b.NewFontType1(fmt.Sprintf("/Font%d", i)))
+		b.AddImage(b.NewImageResource(1, 1, 8, []byte{byte(i)}))
+	}
+	p := b.AddPage()
+	p.Rectangle(10, 10, 100, 100).Render()
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestBuilderReproducible(t *testing.T) {
+	expected := buildReproducible(t)
+	for i := 0; i < 5; i++ {
+		actual := buildReproducible(t)
+		if !bytes.Equal(expected, actual) {
+			t.Fatalf("reproducible output differs\nexpected:%q\nactual  :%q", expected, actual)
+		}
+	}
+	if !bytes.Contains(expected, []byte("/CreationDate (D:20180102030405Z)")) {
+		t.Error("date is not fixed")
+	}
+}
+
+func TestBuilderFileID(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	ptn := regexp.MustCompile(`/ID \[<([0-9A-F]{32})> <([0-9A-F]{32})>\]`)
+	m := ptn.FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("ID is not found in the trailer:\n%s", buf.String())
+	}
+	if m[1] != m[2] {
+		t.Errorf("ID: both parts must be the same on creation: %s %s", m[1], m[2])
+	}
+}
+
+func TestBuilderEncryption(t *testing.T) {
+	for _, algorithm := range []EncryptionAlgorithm{EncryptionRC4, EncryptionAES128, EncryptionAES256} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		b.SetInfo(DocumentInfo{Title: "Payroll"})
+		b.AddPage().Rectangle(0, 0, 10, 10).Render()
+		var buf bytes.Buffer
+		err := b.Build(&buf, WithEncryption(&PasswordEncryption{Algorithm: algorithm, UserPassword: "user", Permissions: PermissionPrint}))
+		if err != nil {
+			t.Fatalf("Build: unexpected error:%s", err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "/Filter /Standard") {
+			t.Error("Build: encryption dictionary is not written")
+		}
+		if strings.Contains(out, "Payroll") {
+			t.Error("Build: strings are not encrypted")
+		}
+		if strings.Contains(out, " re ") {
+			t.Error("Build: streams are not encrypted")
+		}
+		ref := fmt.Sprintf("/Encrypt %s", b.encryption.indirectReference())
+		if !strings.Contains(out, ref) || !strings.Contains(out, fmt.Sprintf("/ID [%s %s]", hexString(b.id), hexString(b.id))) {
+			t.Error("Build: trailer does not refer the encryption dictionary")
+		}
+		if isAES256 := algorithm == EncryptionAES256; isAES256 != strings.Contains(out, "/Extensions <</ADBE <</BaseVersion /1.7 /ExtensionLevel 8>>>>") {
+			t.Error("Build: unexpected extension")
+		}
+	}
+}
+
+func TestBuilderPublicKeyEncryption(t *testing.T) {
+	cert, _ := newTestCertificate(t, "recipient")
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetInfo(DocumentInfo{Title: "Payroll"})
+	var buf bytes.Buffer
+	if err := b.Build(&buf, WithEncryption(&PublicKeyEncryption{Recipients: []Recipient{{Certificate: cert, Permissions: PermissionPrint}}})); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "/Filter /Adobe.PubSec") || strings.Contains(out, "Payroll") {
+		t.Error("Build: document is not encrypted")
+	}
+}
+
+func TestBuilderDefaultPageBoxes(t *testing.T) {
+	b := NewBuilder(NewBox(0, 0, 620, 870), NewBox(0, 0, 620, 870))
+	b.SetDefaultBleedBox(NewBox(5, 5, 615, 865))
+	b.SetDefaultTrimBox(NewBox(10, 10, 610, 860))
+	b.SetDefaultArtBox(NewBox(20, 20, 600, 850))
+	b.SetDefaultRotate(270)
+	b.SetDefaultUserUnit(3)
+	b.AddPage()
+	b.AddPage().SetRotate(0)
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.String()
+	boxes := "/BleedBox [5 5 615 865] /TrimBox [10 10 610 860] /ArtBox [20 20 600 850] /UserUnit 3"
+	if strings.Count(out, boxes) != 2 {
+		t.Errorf("Build: boxes are not written on the pages:\n%s", out)
+	}
+	if strings.Count(out, "/Rotate 270") != 1 || strings.Count(out, "/Rotate 0") != 1 {
+		t.Errorf("Build: unexpected rotation:\n%s", out)
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage().SetRotate(100)
+	if err := b.Build(&buf); err == nil {
+		t.Error("Build: invalid rotation must be error")
+	}
+}
+
+func TestBuilderObjectStreams(t *testing.T) {
+	for _, encryption := range []Encryption{nil, &PasswordEncryption{Algorithm: EncryptionAES128}} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		b.version = "1.4"
+		b.SetObjectStreams(true)
+		b.SetInfo(DocumentInfo{Title: "Payroll"})
+		b.AddPage().Rectangle(0, 0, 10, 10).Render()
+		options := make([]BuildOption, 0)
+		if encryption != nil {
+			options = append(options, WithEncryption(encryption))
+		}
+		var buf bytes.Buffer
+		if err := b.Build(&buf, options...); err != nil {
+			t.Fatalf("Build: unexpected error:%s", err)
+		}
+		out := buf.Bytes()
+		if bytes.Contains(out, []byte("\nxref\n")) || bytes.Contains(out, []byte("trailer\n")) {
+			t.Error("Build: cross-reference table must be replaced with the stream")
+		}
+		if !bytes.Contains(out, []byte("/Type /ObjStm")) || bytes.Contains(out, []byte("(Payroll)")) {
+			t.Error("Build: objects are not packed")
+		}
+		if !bytes.HasPrefix(out, []byte("%PDF-1.5\n")) {
+			t.Errorf("Build: version is not raised:%q", out[:9])
+		}
+		dict := checkXRefStream(t, out)
+		if (encryption != nil) != strings.Contains(dict, "/Encrypt ") {
+			t.Errorf("Build: unexpected trailer:%s", dict)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// buttonFieldFlagNoToggleToOff : Exactly one radio button shall be selected at all times.
+	buttonFieldFlagNoToggleToOff fieldFlag = 1 << 14
+	// buttonFieldFlagRadio : The field is a set of radio buttons.
+	buttonFieldFlagRadio fieldFlag = 1 << 15
+)
+
+// offState is the name of the appearance state which check boxes and radio buttons have when they are off.
+const offState = "Off"
+
+// toggle is the pair of the on and off appearances of a check box or a radio button.
+type toggle struct {
+	onState   string
+	on        *stream
+	off       *stream
+	markColor Color
+}
+
+func newToggle(onState string, rect *Box) toggle {
+	width := rect.rightTopX - rect.leftBottomX
+	height := rect.rightTopY - rect.leftBottomY
+	return toggle{
+		onState:   onState,
+		on:        newFormXObject(width, height),
+		off:       newFormXObject(width, height),
+		markColor: NewColorGrayScale(0),
+	}
+}
+
+// state returns the name of the appearance state.
+func (tg *toggle) state(isOn bool) string {
+	if isOn {
+		return nameObject(tg.onState)
+	}
+	return nameObject(offState)
+}
+
+// buildCheck draws a square frame, and a check mark in the on appearance.
+func (tg *toggle) buildCheck(width, height int) {
+	bbox := NewBox(0, 0, width, height)
+	frame := newRectangle(nil, 0, 0, width, height).StrokeColor(NewColorGrayScale(0)).render(bbox)
+	lw := width / 10
+	if lw < 1 {
+		lw = 1
+	}
+	mark := newLine(nil, width/5, height/2, width*2/5, height*3/4, lw).MoveTo(width*4/5, height/4).Color(tg.markColor)
+	tg.on.reset()
+	tg.on.addStringDatum(frame)
+	tg.on.addStringDatum(mark.render(bbox))
+	tg.off.reset()
+	tg.off.addStringDatum(frame)
+}
+
+// buildRadio draws a circular frame, and a filled circle in the on appearance.
+func (tg *toggle) buildRadio(width, height int) {
+	cx := float64(width) / 2
+	cy := float64(height) / 2
+	frame := fmt.Sprintf("q %s 1 w %s S Q\n", NewColorGrayScale(0).strokeColor(), ellipse(cx, cy, cx-0.5, cy-0.5))
+	dot := fmt.Sprintf("q %s %s f Q\n", tg.markColor.nonStrokeColor(), ellipse(cx, cy, cx/2, cy/2))
+	tg.on.reset()
+	tg.on.addStringDatum(frame)
+	tg.on.addStringDatum(dot)
+	tg.off.reset()
+	tg.off.addStringDatum(frame)
+}
+
+// appearanceEntries returns the current appearance state and the appearance dictionary.
+func (tg *toggle) appearanceEntries(isOn bool) []string {
+	return []string{
+		fmt.Sprintf("/AS %s", tg.state(isOn)),
+		fmt.Sprintf("/AP <</N <<%s %s %s %s>>>>",
+			nameObject(tg.onState), tg.on.indirectReference(), nameObject(offState), tg.off.indirectReference()),
+	}
+}
+
+func (tg *toggle) walk(walker func(obj pdfObject)) {
+	tg.on.walk(walker)
+	tg.off.walk(walker)
+}
+
+// CheckBox is a check box of the interactive form.
+// Its appearance streams are generated when the document is built.
+type CheckBox interface {
+	Annotation
+	// ExportValue specifies the value of the field when the box is checked.
+	// The default value is "Yes".
+	ExportValue(value string) CheckBox
+	// Check makes the box checked.
+	Check() CheckBox
+	// Color specifies the color of the check mark.
+	Color(c Color) CheckBox
+	// ReadOnly prohibits the user from changing the value of the field.
+	ReadOnly() CheckBox
+	// Required makes the field have a value when the form is submitted.
+	Required() CheckBox
+}
+
+// checkBox is a implementation of a CheckBox interface.
+type checkBox struct {
+	field
+	toggle
+	checked bool
+}
+
+func newCheckBox(name string, page Page, rect *Box) *checkBox {
+	cb := &checkBox{
+		field:  newField("/Btn", name, page, rect),
+		toggle: newToggle("Yes", rect),
+	}
+	// A check box has the pair of appearances instead of a single appearance.
+	cb.appearance = nil
+	return cb
+}
+
+func (cb *checkBox) ExportValue(value string) CheckBox {
+	cb.onState = value
+	return cb
+}
+
+func (cb *checkBox) Check() CheckBox {
+	cb.checked = true
+	return cb
+}
+
+func (cb *checkBox) Color(c Color) CheckBox {
+	cb.markColor = c
+	return cb
+}
+
+func (cb *checkBox) ReadOnly() CheckBox {
+	cb.flags |= fieldFlagReadOnly
+	return cb
+}
+
+func (cb *checkBox) Required() CheckBox {
+	cb.flags |= fieldFlagRequired
+	return cb
+}
+
+func (cb *checkBox) buildField(dr *resource) error {
+	if cb.onState == "" || cb.onState == offState {
+		return fmt.Errorf("check box: invalid export value: %s", cb.name)
+	}
+	cb.buildCheck(cb.width(), cb.height())
+	return nil
+}
+
+func (cb *checkBox) compile() string {
+	entries := []string{fmt.Sprintf("/V %s", cb.state(cb.checked))}
+	return cb.compileHelper(append(entries, cb.appearanceEntries(cb.checked)...)...)
+}
+
+func (cb *checkBox) walk(walker func(obj pdfObject)) {
+	walker(cb)
+	cb.toggle.walk(walker)
+}
+
+// RadioButton is a radio button of the interactive form.
+// Radio buttons of the same name form a group, in which at most one button is selected.
+// A group can span several pages.
+// Its appearance streams are generated when the document is built.
+type RadioButton interface {
+	Annotation
+	// Select makes this button selected.
+	Select() RadioButton
+	// Color specifies the color of the mark of the selected button.
+	Color(c Color) RadioButton
+	// ReadOnly prohibits the user from changing the value of the group.
+	ReadOnly() RadioButton
+	// Required makes the group have a value when the form is submitted.
+	Required() RadioButton
+}
+
+// radioButton is a implementation of a RadioButton interface.
+// It is a widget annotation whose parent is the radio button group.
+type radioButton struct {
+	annotation
+	toggle
+	name     string
+	group    *radioGroup
+	selected bool
+	flags    fieldFlag
+}
+
+func newRadioButton(name, value string, page Page, rect *Box) *radioButton {
+	return &radioButton{
+		annotation: newAnnotation("/Widget", page, rect),
+		toggle:     newToggle(value, rect),
+		name:       name,
+	}
+}
+
+func (rb *radioButton) Select() RadioButton {
+	rb.selected = true
+	return rb
+}
+
+func (rb *radioButton) Color(c Color) RadioButton {
+	rb.markColor = c
+	return rb
+}
+
+func (rb *radioButton) ReadOnly() RadioButton {
+	rb.flags |= fieldFlagReadOnly
+	return rb
+}
+
+func (rb *radioButton) Required() RadioButton {
+	rb.flags |= fieldFlagRequired
+	return rb
+}
+
+func (rb *radioButton) compile() string {
+	entries := []string{fmt.Sprintf("/Parent %s", rb.group.indirectReference())}
+	return rb.compileHelper(append(entries, rb.appearanceEntries(rb.selected)...)...)
+}
+
+func (rb *radioButton) walk(walker func(obj pdfObject)) {
+	walker(rb)
+	rb.toggle.walk(walker)
+}
+
+// radioGroup is a radio button field.
+// Radio buttons are its kids.
+type radioGroup struct {
+	objectIdentifier
+	fieldName
+	flags fieldFlag
+	kids  []*radioButton
+}
+
+func newRadioGroup(name string) *radioGroup {
+	return &radioGroup{
+		objectIdentifier: objectIdentifier{},
+		fieldName:        newFieldName(name),
+		flags:            buttonFieldFlagRadio | buttonFieldFlagNoToggleToOff,
+		kids:             make([]*radioButton, 0),
+	}
+}
+
+// addButton adds the radio button to this group.
+func (rg *radioGroup) addButton(rb *radioButton) error {
+	if rb.selected && rg.selected() != nil {
+		return fmt.Errorf("radio button: more than one button is selected: %s", rg.name)
+	}
+	rb.group = rg
+	rg.flags |= rb.flags
+	rg.kids = append(rg.kids, rb)
+	return nil
+}
+
+// selected returns the selected radio button, or nil if no button is selected.
+func (rg *radioGroup) selected() *radioButton {
+	for _, rb := range rg.kids {
+		if rb.selected {
+			return rb
+		}
+	}
+	return nil
+}
+
+func (rg *radioGroup) buildField(dr *resource) error {
+	for _, rb := range rg.kids {
+		if rb.onState == "" || rb.onState == offState {
+			return fmt.Errorf("radio button: invalid value: %s", rg.name)
+		}
+		rb.buildRadio(rb.rect.rightTopX-rb.rect.leftBottomX, rb.rect.rightTopY-rb.rect.leftBottomY)
+	}
+	return nil
+}
+
+func (rg *radioGroup) compile() string {
+	dict := make([]string, 0, 6)
+	dict = append(dict, "/FT /Btn")
+	dict = append(dict, rg.fieldName.entries()...)
+	dict = append(dict, fmt.Sprintf("/Ff %d", rg.flags))
+	value := nameObject(offState)
+	if rb := rg.selected(); rb != nil {
+		value = nameObject(rb.onState)
+	}
+	dict = append(dict, fmt.Sprintf("/V %s", value))
+	kids := make([]pdfObject, 0, len(rg.kids))
+	for _, rb := range rg.kids {
+		kids = append(kids, rb)
+	}
+	dict = append(dict, fmt.Sprintf("/Kids %s", references(kids)))
+	return rg.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
+}
+
+func (rg *radioGroup) walk(walker func(obj pdfObject)) {
+	walker(rg)
+}
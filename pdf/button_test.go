@@ -0,0 +1,111 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckBox(t *testing.T) {
+	p := newTestPage()
+	c := p.CheckBox("agree", 0, 0, 10, 10)
+	if len(p.annotations()) != 1 || p.annotations()[0] != c {
+		t.Fatal("CheckBox: check box is not added to the page")
+	}
+	cb := c.ExportValue("On").Check().Required().(*checkBox)
+	if err := cb.buildField(nil); err != nil {
+		t.Fatalf("buildField: unexpected error:%s", err)
+	}
+	cb.objectNumber = 4
+	cb.on.objectNumber = 5
+	cb.off.objectNumber = 6
+	expected := "4 0 obj\n<</Type /Annot /Subtype /Widget /Rect [10 170 20 180] /P 3 0 R /F 4 /FT /Btn /T (agree) /Ff 2 /V /On /AS /On /AP <</N <</On 5 0 R /Off 6 0 R>>>>>>\nendobj\n"
+	testCompillation(t, expected, cb.compile())
+	frame := "q 0.000000 G 0 0 10 10 re S Q\n"
+	testRendering(t, frame, string(cb.off.data[0]))
+	testRendering(t, frame, string(cb.on.data[0]))
+	testRendering(t, "q 2 5 m 4 3 l 8 8 l 1 w 0.000000 G S Q\n", string(cb.on.data[1]))
+	if len(cb.off.data) != 1 {
+		t.Error("buildField: off appearance must not have check mark")
+	}
+}
+
+func TestCheckBoxUnchecked(t *testing.T) {
+	cb := newCheckBox("agree", newTestPage(), NewBox(0, 0, 10, 10))
+	if !strings.Contains(cb.compile(), "/V /Off /AS /Off") {
+		t.Errorf("compile: unchecked box must be off: %s", cb.compile())
+	}
+	cb.ExportValue("Off")
+	if err := cb.buildField(nil); err == nil {
+		t.Error("buildField: export value Off must be error")
+	}
+}
+
+func TestRadioGroup(t *testing.T) {
+	p := newTestPage()
+	rb1 := p.RadioButton("color", "Red", 0, 0, 10, 10).(*radioButton)
+	rb2 := p.RadioButton("color", "Blue", 0, 20, 10, 10).Select().ReadOnly().(*radioButton)
+	rg := newRadioGroup("color")
+	for _, rb := range []*radioButton{rb1, rb2} {
+		if err := rg.addButton(rb); err != nil {
+			t.Fatalf("addButton: unexpected error:%s", err)
+		}
+	}
+	if err := rg.buildField(nil); err != nil {
+		t.Fatalf("buildField: unexpected error:%s", err)
+	}
+	rg.objectNumber = 4
+	rb1.objectNumber = 5
+	rb2.objectNumber = 6
+	testCompillation(t, "4 0 obj\n<</FT /Btn /T (color) /Ff 49153 /V /Blue /Kids [5 0 R 6 0 R]>>\nendobj\n", rg.compile())
+	rb1.on.objectNumber = 7
+	rb1.off.objectNumber = 8
+	expected := "5 0 obj\n<</Type /Annot /Subtype /Widget /Rect [10 170 20 180] /P 3 0 R /F 4 /Parent 4 0 R /AS /Off /AP <</N <</Red 7 0 R /Off 8 0 R>>>>>>\nendobj\n"
+	testCompillation(t, expected, rb1.compile())
+	if len(rb1.on.data) != 2 || len(rb1.off.data) != 1 {
+		t.Error("buildField: appearances are not built")
+	}
+	if err := rg.addButton(newRadioButton("color", "Green", p, NewBox(0, 0, 10, 10)).Select().(*radioButton)); err == nil {
+		t.Error("addButton: more than one selected button must be error")
+	}
+	nums := 0
+	rg.walk(func(obj pdfObject) {
+		nums++
+	})
+	if nums != 1 {
+		t.Errorf("walk: radio buttons must be walked by pages: %d", nums)
+	}
+}
+
+func TestBuilderRadioGroup(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	p1 := b.AddPage()
+	p2 := b.AddPage()
+	p1.RadioButton("q.answer", "Yes", 0, 0, 10, 10)
+	p2.RadioButton("q.answer", "No", 0, 0, 10, 10).Select()
+	p2.CheckBox("q.sure", 0, 20, 10, 10)
+	if err := b.build(); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	q := b.dc.form.fields[0].(*fieldNode)
+	if len(q.kids) != 2 {
+		t.Fatalf("build: unexpected kids:%d", len(q.kids))
+	}
+	rg, ok := q.kids[0].(*radioGroup)
+	if !ok || len(rg.kids) != 2 {
+		t.Fatal("build: radio buttons on different pages are not grouped")
+	}
+	if rg.refNo() == 0 {
+		t.Error("build: radio group is not numbered")
+	}
+}
+
+func TestBuilderRadioGroupDuplicateName(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddFont(b.NewFontType1("/Helvetica"))
+	p := b.AddPage()
+	p.TextField("answer", 0, 0, 100, 20)
+	p.RadioButton("answer", "Yes", 0, 40, 10, 10)
+	if err := b.build(); err == nil {
+		t.Error("build: radio group and text field of the same name must be error")
+	}
+}
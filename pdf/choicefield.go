@@ -0,0 +1,236 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// choiceFieldFlagCombo : The field is a combo box, otherwise it is a list box.
+	choiceFieldFlagCombo fieldFlag = 1 << 17
+	// choiceFieldFlagEdit : The combo box includes an editable text box as well as a drop-down list.
+	choiceFieldFlagEdit fieldFlag = 1 << 18
+	// choiceFieldFlagMultiSelect : More than one of the field’s option items may be selected simultaneously.
+	choiceFieldFlagMultiSelect fieldFlag = 1 << 21
+)
+
+// ChoiceField is a list box or a combo box of the interactive form.
+// Its appearance stream is generated when the document is built.
+type ChoiceField interface {
+	Annotation
+	// Option adds an option item.
+	// exportValue is the value of the field when the item is selected, and text is displayed to the user.
+	Option(exportValue, text string) ChoiceField
+	// Select makes the items of the export values selected.
+	Select(exportValues ...string) ChoiceField
+	// Editable allows the user to enter a value which is not in the options.
+	// It is available only for combo boxes.
+	Editable() ChoiceField
+	// MultiSelect allows the user to select more than one item.
+	// It is available only for list boxes.
+	MultiSelect() ChoiceField
+	// ReadOnly prohibits the user from changing the value of the field.
+	ReadOnly() ChoiceField
+	// Required makes the field have a value when the form is submitted.
+	Required() ChoiceField
+	// Font specifies the font and its size of the text.
+	// The font must be registered by Builder.AddFont, otherwise Build returns an error.
+	// If the font is not specified, a font registered by Builder.AddFont is used.
+	Font(font Font, fontSize int) ChoiceField
+}
+
+// choiceOption is an option item of a choice field.
+type choiceOption struct {
+	exportValue string
+	text        string
+}
+
+func (co choiceOption) compile() string {
+	if co.exportValue == co.text {
+		return textString(co.text)
+	}
+	return fmt.Sprintf("[%s %s]", textString(co.exportValue), textString(co.text))
+}
+
+// choiceField is a implementation of a ChoiceField interface.
+type choiceField struct {
+	field
+	variableText
+	options  []choiceOption
+	selected []string
+}
+
+func newChoiceField(name string, page Page, rect *Box, combo bool) *choiceField {
+	cf := &choiceField{
+		field:        newField("/Ch", name, page, rect),
+		variableText: variableText{fontSize: 12},
+		options:      make([]choiceOption, 0),
+		selected:     make([]string, 0),
+	}
+	if combo {
+		cf.flags |= choiceFieldFlagCombo
+	}
+	return cf
+}
+
+func (cf *choiceField) Option(exportValue, text string) ChoiceField {
+	cf.options = append(cf.options, choiceOption{exportValue, text})
+	return cf
+}
+
+func (cf *choiceField) Select(exportValues ...string) ChoiceField {
+	cf.selected = append(cf.selected, exportValues...)
+	return cf
+}
+
+func (cf *choiceField) Editable() ChoiceField {
+	cf.flags |= choiceFieldFlagEdit
+	return cf
+}
+
+func (cf *choiceField) MultiSelect() ChoiceField {
+	cf.flags |= choiceFieldFlagMultiSelect
+	return cf
+}
+
+func (cf *choiceField) ReadOnly() ChoiceField {
+	cf.flags |= fieldFlagReadOnly
+	return cf
+}
+
+func (cf *choiceField) Required() ChoiceField {
+	cf.flags |= fieldFlagRequired
+	return cf
+}
+
+func (cf *choiceField) Font(font Font, fontSize int) ChoiceField {
+	cf.font = font
+	cf.fontSize = fontSize
+	return cf
+}
+
+func (cf *choiceField) isCombo() bool {
+	return cf.flags&choiceFieldFlagCombo != 0
+}
+
+// index returns the index of the option item of the export value, or -1 if it is not in the options.
+func (cf *choiceField) index(exportValue string) int {
+	for i, o := range cf.options {
+		if o.exportValue == exportValue {
+			return i
+		}
+	}
+	return -1
+}
+
+// isSelected returns whether the item of the export value is selected.
+func (cf *choiceField) isSelected(exportValue string) bool {
+	for _, v := range cf.selected {
+		if v == exportValue {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the consistency of the flags and the selected items.
+func (cf *choiceField) validate() error {
+	if cf.isCombo() && cf.flags&choiceFieldFlagMultiSelect != 0 {
+		return fmt.Errorf("choice field: combo box cannot be multi-select: %s", cf.name)
+	}
+	if !cf.isCombo() && cf.flags&choiceFieldFlagEdit != 0 {
+		return fmt.Errorf("choice field: list box cannot be editable: %s", cf.name)
+	}
+	if len(cf.selected) > 1 && cf.flags&choiceFieldFlagMultiSelect == 0 {
+		return fmt.Errorf("choice field: more than one item is selected: %s", cf.name)
+	}
+	for _, v := range cf.selected {
+		if cf.index(v) < 0 && cf.flags&choiceFieldFlagEdit == 0 {
+			return fmt.Errorf("choice field: selected value is not in the options: %s: %s", cf.name, v)
+		}
+	}
+	return nil
+}
+
+func (cf *choiceField) buildField(dr *resource) error {
+	if err := cf.validate(); err != nil {
+		return err
+	}
+	if err := cf.resolveFont("choice field", dr); err != nil {
+		return err
+	}
+	cf.appearance.reset()
+	cf.appearance.resetResources()
+	cf.appearance.resources.addFont(cf.font)
+	cf.appearance.addStringDatum("/Tx BMC\n")
+	if cf.isCombo() {
+		cf.buildCombo()
+	} else {
+		cf.buildList()
+	}
+	cf.appearance.addStringDatum("EMC\n")
+	return nil
+}
+
+// buildCombo draws the text of the selected item.
+func (cf *choiceField) buildCombo() {
+	if len(cf.selected) == 0 {
+		return
+	}
+	text := cf.selected[0]
+	if i := cf.index(text); i >= 0 {
+		text = cf.options[i].text
+	}
+	cf.appearance.addStringDatum(cf.font.createText(textFieldPadding, (cf.height()-cf.fontSize)/2, cf.fontSize, text))
+}
+
+// buildList draws the option items from the top, and highlights the selected items.
+func (cf *choiceField) buildList() {
+	lineHeight := cf.fontSize + textFieldPadding
+	for i, o := range cf.options {
+		if cf.isSelected(o.exportValue) {
+			highlight := newRectangle(nil, 0, i*lineHeight, cf.width(), lineHeight).FillColor(NewColorRGB(0.6, 0.75, 0.85))
+			cf.appearance.addStringDatum(highlight.render(cf.bbox()))
+		}
+		y := cf.height() - (i+1)*lineHeight + textFieldPadding
+		cf.appearance.addStringDatum(cf.font.createText(textFieldPadding, y, cf.fontSize, o.text))
+	}
+}
+
+func (cf *choiceField) compile() string {
+	entries := make([]string, 0, 4)
+	if cf.font != nil {
+		entries = append(entries, cf.da())
+	}
+	opts := make([]string, 0, len(cf.options))
+	for _, o := range cf.options {
+		opts = append(opts, o.compile())
+	}
+	entries = append(entries, fmt.Sprintf("/Opt [%s]", strings.Join(opts, " ")))
+	values := make([]string, 0, len(cf.selected))
+	for _, v := range cf.selected {
+		values = append(values, textString(v))
+	}
+	indices := make([]string, 0, len(cf.selected))
+	for i, o := range cf.options {
+		if cf.isSelected(o.exportValue) {
+			indices = append(indices, fmt.Sprintf("%d", i))
+		}
+	}
+	switch {
+	case len(values) == 1:
+		entries = append(entries, fmt.Sprintf("/V %s", values[0]))
+	case len(values) > 1:
+		entries = append(entries, fmt.Sprintf("/V [%s]", strings.Join(values, " ")))
+	}
+	if len(indices) > 1 {
+		// The indices in ascending order are required when more than one item is selected.
+		entries = append(entries, fmt.Sprintf("/I [%s]", strings.Join(indices, " ")))
+	}
+	return cf.compileHelper(entries...)
+}
+
+func (cf *choiceField) walk(walker func(obj pdfObject)) {
+	walker(cf)
+	cf.appearance.walk(walker)
+}
@@ -0,0 +1,68 @@
+package pdf
+
+import "testing"
+
+func TestComboBox(t *testing.T) {
+	p := newTestPage()
+	f := newFontType1("/F0", "/Helvetica")
+	c := p.ComboBox("country", 0, 0, 100, 20)
+	if len(p.annotations()) != 1 || p.annotations()[0] != c {
+		t.Fatal("ComboBox: combo box is not added to the page")
+	}
+	cf := c.Option("JP", "Japan").Option("US", "United States").Select("JP").Font(f, 10).(*choiceField)
+	if err := cf.buildField(newTestDefaultResource(f)); err != nil {
+		t.Fatalf("buildField: unexpected error:%s", err)
+	}
+	cf.objectNumber = 4
+	cf.appearance.objectNumber = 5
+	expected := "4 0 obj\n<</Type /Annot /Subtype /Widget /Rect [10 160 110 180] /P 3 0 R /F 4 /FT /Ch /T (country) /Ff 131072 /AP <</N 5 0 R>> /DA (/F0 10 Tf 0 g) /Opt [[(JP) (Japan)] [(US) (United States)]] /V (JP)>>\nendobj\n"
+	testCompillation(t, expected, cf.compile())
+	testRendering(t, f.createText(2, 5, 10, "Japan"), string(cf.appearance.data[1]))
+}
+
+func TestListBox(t *testing.T) {
+	f := newFontType1("/F0", "/Helvetica")
+	cf := newChoiceField("fruits", newTestPage(), NewBox(0, 0, 100, 40), false)
+	cf.Option("a", "a").Option("b", "b").Option("c", "c").MultiSelect().Select("c", "a").Font(f, 10)
+	if err := cf.buildField(newTestDefaultResource(f)); err != nil {
+		t.Fatalf("buildField: unexpected error:%s", err)
+	}
+	cf.objectNumber = 4
+	cf.appearance.objectNumber = 5
+	expected := "4 0 obj\n<</Type /Annot /Subtype /Widget /Rect [0 0 100 40] /P 3 0 R /F 4 /FT /Ch /T (fruits) /Ff 2097152 /AP <</N 5 0 R>> /DA (/F0 10 Tf 0 g) /Opt [(a) (b) (c)] /V [(c) (a)] /I [0 2]>>\nendobj\n"
+	testCompillation(t, expected, cf.compile())
+	data := cf.appearance.data
+	if len(data) != 7 {
+		t.Fatalf("buildField: unexpected appearance:%d", len(data))
+	}
+	testRendering(t, "q 0.600000 0.750000 0.850000 rg 0 28 100 12 re f Q\n", string(data[1]))
+	testRendering(t, f.createText(2, 30, 10, "a"), string(data[2]))
+	testRendering(t, f.createText(2, 18, 10, "b"), string(data[3]))
+	testRendering(t, "q 0.600000 0.750000 0.850000 rg 0 4 100 12 re f Q\n", string(data[4]))
+	testRendering(t, f.createText(2, 6, 10, "c"), string(data[5]))
+}
+
+func TestChoiceFieldValidation(t *testing.T) {
+	f := newFontType1("/F0", "/Helvetica")
+	tests := []struct {
+		combo   bool
+		setup   func(cf ChoiceField)
+		isValid bool
+	}{
+		{true, func(cf ChoiceField) { cf.Option("a", "a").Select("a") }, true},
+		{true, func(cf ChoiceField) { cf.Option("a", "a").Select("b") }, false},
+		{true, func(cf ChoiceField) { cf.Option("a", "a").Editable().Select("b") }, true},
+		{true, func(cf ChoiceField) { cf.MultiSelect() }, false},
+		{false, func(cf ChoiceField) { cf.Editable() }, false},
+		{false, func(cf ChoiceField) { cf.Option("a", "a").Option("b", "b").Select("a", "b") }, false},
+		{false, func(cf ChoiceField) { cf.Option("a", "a").Option("b", "b").MultiSelect().Select("a", "b") }, true},
+	}
+	for i, test := range tests {
+		cf := newChoiceField("choice", newTestPage(), NewBox(0, 0, 10, 10), test.combo)
+		cf.Font(f, 10)
+		test.setup(cf)
+		if err := cf.buildField(newTestDefaultResource(f)); (err == nil) != test.isValid {
+			t.Errorf("validate: case %d: unexpected result:%v", i, err)
+		}
+	}
+}
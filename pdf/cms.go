@@ -0,0 +1,263 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// Object identifiers of Cryptographic Message Syntax (RFC 5652).
+var (
+	oidData            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEnvelopedData   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidAES256CBC       = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSignedData      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidSHA256          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidContentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+)
+
+// contentInfo is a CMS ContentInfo.
+// Content is the DER encoded content explicitly tagged by [0].
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+// newContentInfo returns the DER encoded ContentInfo which wraps the content.
+func newContentInfo(contentType asn1.ObjectIdentifier, content interface{}) ([]byte, error) {
+	inner, err := asn1.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(contentInfo{
+		ContentType: contentType,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: inner},
+	})
+}
+
+// issuerAndSerialNumber identifies a certificate by its issuer and serial number.
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+func newIssuerAndSerialNumber(cert *x509.Certificate) issuerAndSerialNumber {
+	return issuerAndSerialNumber{
+		Issuer:       asn1.RawValue{FullBytes: cert.RawIssuer},
+		SerialNumber: cert.SerialNumber,
+	}
+}
+
+// envelopedData is a CMS EnvelopedData.
+type envelopedData struct {
+	Version              int
+	RecipientInfos       []keyTransRecipientInfo `asn1:"set"`
+	EncryptedContentInfo encryptedContentInfo
+}
+
+// keyTransRecipientInfo is the content-encryption key encrypted by the public key of a recipient.
+type keyTransRecipientInfo struct {
+	Version                int
+	RecipientIdentifier    issuerAndSerialNumber
+	KeyEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedKey           []byte
+}
+
+// encryptedContentInfo is the encrypted content.
+// EncryptedContent is the octet string implicitly tagged by [0].
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue
+}
+
+// envelope encrypts the content for the recipients, and returns the DER encoded ContentInfo of EnvelopedData.
+// The content is encrypted by AES-256 in CBC mode, and the key is encrypted by RSA public keys of the recipients.
+func envelope(content []byte, recipients []*x509.Certificate, random io.Reader) ([]byte, error) {
+	// The content-encryption key followed by the initialization vector.
+	r := make([]byte, 32+aes.BlockSize)
+	if _, err := io.ReadFull(random, r); err != nil {
+		return nil, err
+	}
+	key, iv := r[:32], r[32:]
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(content)%aes.BlockSize
+	plain := append(append([]byte{}, content...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+	infos := make([]keyTransRecipientInfo, 0, len(recipients))
+	for _, cert := range recipients {
+		pub, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("cms: only RSA keys are supported for recipients")
+		}
+		encryptedKey, err := rsa.EncryptPKCS1v15(random, pub, key)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, keyTransRecipientInfo{
+			Version:                0,
+			RecipientIdentifier:    newIssuerAndSerialNumber(cert),
+			KeyEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue},
+			EncryptedKey:           encryptedKey,
+		})
+	}
+	return newContentInfo(oidEnvelopedData, envelopedData{
+		Version:        0,
+		RecipientInfos: infos,
+		EncryptedContentInfo: encryptedContentInfo{
+			ContentType: oidData,
+			ContentEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  oidAES256CBC,
+				Parameters: asn1.RawValue{Tag: asn1.TagOctetString, Bytes: iv},
+			},
+			EncryptedContent: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: encrypted},
+		},
+	})
+}
+
+// signedData is a CMS SignedData.
+// Certificates are the DER encoded certificates implicitly tagged by [0].
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+// encapsulatedContentInfo is the signed content.
+// The content of a detached signature is absent.
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional"`
+}
+
+// signerInfo is the signature of a signer.
+// SignedAttributes and UnsignedAttributes are the sets of attributes implicitly tagged by [0] and [1].
+type signerInfo struct {
+	Version            int
+	SignerIdentifier   issuerAndSerialNumber
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttributes   asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttributes asn1.RawValue `asn1:"optional"`
+}
+
+// attribute is a CMS attribute which has a single value.
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// newAttribute returns the DER encoded attribute.
+func newAttribute(attrType asn1.ObjectIdentifier, value interface{}) ([]byte, error) {
+	v, err := asn1.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(attribute{
+		Type:   attrType,
+		Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: v},
+	})
+}
+
+// attributeSet returns the set of the DER encoded attributes with the tag.
+// Elements of a set are sorted by their encodings in DER.
+func attributeSet(class, tag int, attrs [][]byte) asn1.RawValue {
+	sorted := append([][]byte{}, attrs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	return asn1.RawValue{Class: class, Tag: tag, IsCompound: true, Bytes: bytes.Join(sorted, nil)}
+}
+
+// signatureAlgorithm returns the algorithm which the signer uses with SHA-256.
+func signatureAlgorithm(signer crypto.Signer) (pkix.AlgorithmIdentifier, error) {
+	switch signer.Public().(type) {
+	case *rsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSHA256WithRSA, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+	default:
+		return pkix.AlgorithmIdentifier{}, errors.New("cms: only RSA and ECDSA keys are supported for signers")
+	}
+}
+
+// signDetached signs the data, and returns the DER encoded ContentInfo of SignedData without the content.
+// The first certificate of the chain must be the certificate of the signer.
+func signDetached(data []byte, signer crypto.Signer, chain []*x509.Certificate, signingTime time.Time, random io.Reader) ([]byte, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("cms: certificate of the signer is not specified")
+	}
+	sigAlg, err := signatureAlgorithm(signer)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	attrs := make([][]byte, 0, 3)
+	for _, a := range []struct {
+		attrType asn1.ObjectIdentifier
+		value    interface{}
+	}{
+		{oidContentType, oidData},
+		{oidSigningTime, signingTime.UTC()},
+		{oidMessageDigest, digest[:]},
+	} {
+		attr, err := newAttribute(a.attrType, a.value)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, attr)
+	}
+	// The signature is computed over the signed attributes encoded as a SET.
+	signed, err := asn1.Marshal(attributeSet(asn1.ClassUniversal, asn1.TagSet, attrs))
+	if err != nil {
+		return nil, err
+	}
+	signedDigest := sha256.Sum256(signed)
+	signature, err := signer.Sign(random, signedDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	certs := make([][]byte, 0, len(chain))
+	for _, c := range chain {
+		certs = append(certs, c.Raw)
+	}
+	sha256Alg := pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}
+	return newContentInfo(oidSignedData, signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Alg},
+		EncapContentInfo: encapsulatedContentInfo{ContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: bytes.Join(certs, nil)},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SignerIdentifier:   newIssuerAndSerialNumber(chain[0]),
+			DigestAlgorithm:    sha256Alg,
+			SignedAttributes:   attributeSet(asn1.ClassContextSpecific, 0, attrs),
+			SignatureAlgorithm: sigAlg,
+			Signature:          signature,
+		}},
+	})
+}
@@ -0,0 +1,180 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestCertificate returns a self-signed certificate and its private key.
+func newTestCertificate(t *testing.T, name string) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("newTestCertificate: unexpected error:%s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("newTestCertificate: unexpected error:%s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("newTestCertificate: unexpected error:%s", err)
+	}
+	return cert, key
+}
+
+// openEnvelope decrypts the content of the EnvelopedData with the private key of the recipient.
+func openEnvelope(t *testing.T, der []byte, cert *x509.Certificate, key *rsa.PrivateKey) []byte {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		t.Fatalf("openEnvelope: unexpected error:%s", err)
+	}
+	if !ci.ContentType.Equal(oidEnvelopedData) {
+		t.Fatalf("openEnvelope: unexpected content type:%s", ci.ContentType)
+	}
+	var ed envelopedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+		t.Fatalf("openEnvelope: unexpected error:%s", err)
+	}
+	for _, ri := range ed.RecipientInfos {
+		if !bytes.Equal(ri.RecipientIdentifier.Issuer.FullBytes, cert.RawIssuer) || ri.RecipientIdentifier.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+			continue
+		}
+		cek, err := rsa.DecryptPKCS1v15(nil, key, ri.EncryptedKey)
+		if err != nil {
+			t.Fatalf("openEnvelope: unexpected error:%s", err)
+		}
+		eci := ed.EncryptedContentInfo
+		if !eci.ContentEncryptionAlgorithm.Algorithm.Equal(oidAES256CBC) {
+			t.Fatalf("openEnvelope: unexpected algorithm:%s", eci.ContentEncryptionAlgorithm.Algorithm)
+		}
+		block, _ := aes.NewCipher(cek)
+		plain := make([]byte, len(eci.EncryptedContent.Bytes))
+		cipher.NewCBCDecrypter(block, eci.ContentEncryptionAlgorithm.Parameters.Bytes).CryptBlocks(plain, eci.EncryptedContent.Bytes)
+		return plain[:len(plain)-int(plain[len(plain)-1])]
+	}
+	t.Fatal("openEnvelope: recipient is not found")
+	return nil
+}
+
+func TestEnvelope(t *testing.T) {
+	cert1, key1 := newTestCertificate(t, "recipient1")
+	cert2, key2 := newTestCertificate(t, "recipient2")
+	content := []byte("0123456789abcdefghijklmn")
+	der, err := envelope(content, []*x509.Certificate{cert1, cert2}, rand.Reader)
+	if err != nil {
+		t.Fatalf("envelope: unexpected error:%s", err)
+	}
+	if actual := openEnvelope(t, der, cert1, key1); !bytes.Equal(content, actual) {
+		t.Errorf("envelope: expected:%s actual:%s", content, actual)
+	}
+	if actual := openEnvelope(t, der, cert2, key2); !bytes.Equal(content, actual) {
+		t.Errorf("envelope: expected:%s actual:%s", content, actual)
+	}
+	if _, err := envelope(content, []*x509.Certificate{{PublicKey: "not a key"}}, rand.Reader); err == nil {
+		t.Error("envelope: non RSA key must be error")
+	}
+}
+
+// verifyDetached verifies the detached signature of the data, and returns the signed attributes.
+// The signature may be followed by the padding of the contents.
+func verifyDetached(t *testing.T, der []byte, data []byte) map[string][]byte {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		t.Fatalf("verifyDetached: unexpected error:%s", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		t.Fatalf("verifyDetached: unexpected content type:%s", ci.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		t.Fatalf("verifyDetached: unexpected error:%s", err)
+	}
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil || len(certs) == 0 {
+		t.Fatalf("verifyDetached: invalid certificates:%v", err)
+	}
+	if len(sd.SignerInfos) != 1 {
+		t.Fatalf("verifyDetached: unexpected signer infos:%d", len(sd.SignerInfos))
+	}
+	si := sd.SignerInfos[0]
+	if si.SignerIdentifier.SerialNumber.Cmp(certs[0].SerialNumber) != 0 {
+		t.Error("verifyDetached: signer is not the first certificate")
+	}
+	attrs := make(map[string][]byte)
+	for rest := si.SignedAttributes.Bytes; len(rest) > 0; {
+		var a attribute
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &a); err != nil {
+			t.Fatalf("verifyDetached: unexpected error:%s", err)
+		}
+		attrs[a.Type.String()] = a.Values.Bytes
+	}
+	var digest []byte
+	asn1.Unmarshal(attrs[oidMessageDigest.String()], &digest)
+	expected := sha256.Sum256(data)
+	if !bytes.Equal(expected[:], digest) {
+		t.Error("verifyDetached: message digest does not match")
+	}
+	// The signature is computed over the signed attributes with the SET tag.
+	signed := append([]byte{0x31}, si.SignedAttributes.FullBytes[1:]...)
+	algorithm := x509.SHA256WithRSA
+	if si.SignatureAlgorithm.Algorithm.Equal(oidECDSAWithSHA256) {
+		algorithm = x509.ECDSAWithSHA256
+	}
+	if err := certs[0].CheckSignature(algorithm, signed, si.Signature); err != nil {
+		t.Errorf("verifyDetached: invalid signature:%s", err)
+	}
+	return attrs
+}
+
+func TestSignDetached(t *testing.T) {
+	cert, key := newTestCertificate(t, "signer")
+	data := []byte("signed data")
+	date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	der, err := signDetached(data, key, []*x509.Certificate{cert}, date, rand.Reader)
+	if err != nil {
+		t.Fatalf("signDetached: unexpected error:%s", err)
+	}
+	attrs := verifyDetached(t, der, data)
+	var signingTime time.Time
+	asn1.Unmarshal(attrs[oidSigningTime.String()], &signingTime)
+	if !signingTime.Equal(date) {
+		t.Errorf("signDetached: unexpected signing time:%s", signingTime)
+	}
+	var contentType asn1.ObjectIdentifier
+	asn1.Unmarshal(attrs[oidContentType.String()], &contentType)
+	if !contentType.Equal(oidData) {
+		t.Errorf("signDetached: unexpected content type:%s", contentType)
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "ec"}, NotBefore: date, NotAfter: date.AddDate(1, 0, 0)}
+	ecDER, _ := x509.CreateCertificate(rand.Reader, template, template, &ecKey.PublicKey, ecKey)
+	ecCert, _ := x509.ParseCertificate(ecDER)
+	der, err = signDetached(data, ecKey, []*x509.Certificate{ecCert}, date, rand.Reader)
+	if err != nil {
+		t.Fatalf("signDetached: unexpected error:%s", err)
+	}
+	verifyDetached(t, der, data)
+	if _, err := signDetached(data, key, nil, date, rand.Reader); err == nil {
+		t.Error("signDetached: signer without certificate must be error")
+	}
+}
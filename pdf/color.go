@@ -7,6 +7,8 @@ type Color interface {
 	colorSpace() colorSpace
 	strokeColor() string
 	nonStrokeColor() string
+	// components returns the color components as an array, such as used in annotations.
+	components() string
 }
 
 type grayScaleColor struct {
@@ -30,6 +32,10 @@ func (c *grayScaleColor) nonStrokeColor() string {
 	return fmt.Sprintf("%f g", c.scale)
 }
 
+func (c *grayScaleColor) components() string {
+	return fmt.Sprintf("[%f]", c.scale)
+}
+
 type rgbColor struct {
 	red   float32
 	green float32
@@ -53,6 +59,10 @@ func (c *rgbColor) nonStrokeColor() string {
 	return fmt.Sprintf("%f %f %f rg", c.red, c.green, c.blue)
 }
 
+func (c *rgbColor) components() string {
+	return fmt.Sprintf("[%f %f %f]", c.red, c.green, c.blue)
+}
+
 type cmykColor struct {
 	cyan    float32
 	magenta float32
@@ -77,6 +87,10 @@ func (c *cmykColor) nonStrokeColor() string {
 	return fmt.Sprintf("%f %f %f %f k", c.cyan, c.magenta, c.yellow, c.key)
 }
 
+func (c *cmykColor) components() string {
+	return fmt.Sprintf("[%f %f %f %f]", c.cyan, c.magenta, c.yellow, c.key)
+}
+
 type undefColor struct{}
 
 // newColorUndef returns a dummy Color for no filling or no stroking.
@@ -96,6 +110,10 @@ func (c *undefColor) nonStrokeColor() string {
 	return ""
 }
 
+func (c *undefColor) components() string {
+	return "[]"
+}
+
 // colorSpace is color space
 type colorSpace int
 
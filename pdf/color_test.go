@@ -53,3 +53,10 @@ func TestColorSpace(t *testing.T) {
 		t.Errorf("expected:%s actual:%s", ex5, ac5)
 	}
 }
+
+func TestColorComponents(t *testing.T) {
+	testCompillation(t, "[0.500000]", NewColorGrayScale(0.5).components())
+	testCompillation(t, "[0.200000 0.400000 0.800000]", NewColorRGB(0.2, 0.4, 0.8).components())
+	testCompillation(t, "[0.200000 0.400000 0.600000 0.800000]", NewColorCMYK(0.2, 0.4, 0.6, 0.8).components())
+	testCompillation(t, "[]", newColorUndef().components())
+}
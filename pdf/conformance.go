@@ -0,0 +1,400 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"strings"
+)
+
+const (
+	xmpNamespacePDFAID  = "http://www.aiim.org/pdfa/ns/id/"
+	xmpNamespacePDFXID  = "http://www.npes.org/pdfx/ns/id/"
+	xmpNamespacePDFUAID = "http://www.aiim.org/pdfua/ns/id/"
+	xmpNamespaceXMPMM   = "http://ns.adobe.com/xap/1.0/mm/"
+)
+
+// Conformance is a standard to which the document conforms.
+// Builder.Build emits the objects required by the standard,
+// and returns an error if the document cannot conform to it.
+type Conformance int
+
+const (
+	conformanceUndefined Conformance = iota
+	// ConformancePDFA2B : PDF/A-2b (ISO 19005-2, level B), the visual appearance is preserved for long-term archiving.
+	ConformancePDFA2B
+	// ConformancePDFA3B : PDF/A-3b (ISO 19005-3, level B), PDF/A-2b which also allows embedded files of any format.
+	ConformancePDFA3B
+	// ConformancePDFX4 : PDF/X-4 (ISO 15930-7), the exchange of print-ready documents.
+	// Every page must have the trim box and the bleed box.
+	// The printing condition must be specified by Builder.SetOutputCondition.
+	ConformancePDFX4
+	// ConformancePDFUA1 : PDF/UA-1 (ISO 14289-1), the universal accessibility.
+	// The document must be tagged by Builder.StructureTree, and must have the title and the language.
+	// Contents of pages must be in structure elements or artifacts, and annotations are not supported.
+	// The viewer displays the title instead of the file name.
+	ConformancePDFUA1
+)
+
+func (c Conformance) String() string {
+	switch c {
+	case ConformancePDFA2B:
+		return "PDF/A-2b"
+	case ConformancePDFA3B:
+		return "PDF/A-3b"
+	case ConformancePDFX4:
+		return "PDF/X-4"
+	case ConformancePDFUA1:
+		return "PDF/UA-1"
+	default:
+		return ""
+	}
+}
+
+// pdfaPart returns the part of PDF/A, or 0 if the conformance is not PDF/A.
+func (c Conformance) pdfaPart() int {
+	switch c {
+	case ConformancePDFA2B:
+		return 2
+	case ConformancePDFA3B:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// isPDFX returns whether the conformance is PDF/X.
+func (c Conformance) isPDFX() bool {
+	return c == ConformancePDFX4
+}
+
+// OutputCondition is the printing condition for which a PDF/X document is prepared.
+//
+// ex. the characterized printing condition FOGRA39 of the ICC registry:
+//
+//	&OutputCondition{
+//		Identifier:   "FOGRA39",
+//		RegistryName: "http://www.color.org",
+//		Info:         "Coated FOGRA39 (ISO 12647-2:2004)",
+//		Profile:      profile, // ISOcoated_v2_eci.icc etc.
+//	}
+type OutputCondition struct {
+	// Identifier is the name of the printing condition, such as the reference name in the registry.
+	Identifier string
+	// RegistryName is the URL of the registry in which the condition is registered.
+	// If it is empty, the condition is custom.
+	RegistryName string
+	// Info is the human-readable description of the condition, which is required for a custom condition.
+	Info string
+	// Profile is the ICC output profile of the condition, whose color space must be CMYK.
+	Profile []byte
+}
+
+// validate returns the reasons why the condition cannot be the output intent of PDF/X.
+func (oc *OutputCondition) validate() []string {
+	errs := make([]string, 0)
+	if oc.Identifier == "" {
+		errs = append(errs, "identifier of the output condition is not specified")
+	}
+	if oc.RegistryName == "" && oc.Info == "" {
+		errs = append(errs, "info of the output condition is not specified, which is required for a custom condition")
+	}
+	if class, cs, ok := iccProfileClass(oc.Profile); !ok || class != "prtr" || cs != "CMYK" {
+		errs = append(errs, "profile of the output condition must be a CMYK output profile")
+	}
+	return errs
+}
+
+// outputIntent describes the color characteristics of the output device.
+// Device-dependent colors of the document are interpreted with its ICC profile.
+type outputIntent struct {
+	subtype    string
+	identifier string
+	// registryName is the registry of the identifier, or empty if the condition is custom.
+	registryName string
+	info         string
+	// colorSpace is the color space of the profile.
+	colorSpace colorSpace
+	profile    *stream
+}
+
+// newOutputIntentSRGB returns the output intent of PDF/A whose profile is sRGB.
+func newOutputIntentSRGB() *outputIntent {
+	profile := newDeflatedStream()
+	profile.dict["/N"] = "3"
+	profile.addBinaryDatum(srgbProfile())
+	return &outputIntent{
+		subtype:    "/GTS_PDFA1",
+		identifier: "sRGB IEC61966-2.1",
+		info:       "sRGB IEC61966-2.1",
+		colorSpace: colorSpaceDeviceRGB,
+		profile:    profile,
+	}
+}
+
+// newOutputIntentPDFX returns the output intent of PDF/X for the printing condition.
+func newOutputIntentPDFX(oc *OutputCondition) *outputIntent {
+	profile := newDeflatedStream()
+	profile.dict["/N"] = "4"
+	profile.addBinaryDatum(oc.Profile)
+	return &outputIntent{
+		subtype:      "/GTS_PDFX",
+		identifier:   oc.Identifier,
+		registryName: oc.RegistryName,
+		info:         oc.Info,
+		colorSpace:   colorSpaceDeviceCMYK,
+		profile:      profile,
+	}
+}
+
+func (oi *outputIntent) compile() string {
+	dict := []string{"/Type /OutputIntent", fmt.Sprintf("/S %s", oi.subtype), fmt.Sprintf("/OutputConditionIdentifier %s", textString(oi.identifier))}
+	if oi.registryName != "" {
+		dict = append(dict, fmt.Sprintf("/RegistryName %s", textString(oi.registryName)))
+	}
+	if oi.info != "" {
+		dict = append(dict, fmt.Sprintf("/Info %s", textString(oi.info)))
+	}
+	dict = append(dict, fmt.Sprintf("/DestOutputProfile %s", oi.profile.indirectReference()))
+	return fmt.Sprintf("<<%s>>", strings.Join(dict, " "))
+}
+
+// buildConformance adds the objects required by the conformance to the document.
+func (b *Builder) buildConformance() {
+	if b.conformance == conformanceUndefined {
+		return
+	}
+	// The document information and the XMP metadata identify the conformance.
+	if b.info == nil {
+		b.info = newDocumentInfo(DocumentInfo{})
+	}
+	// The output intent of the written document is kept, since its profile is not changed.
+	rebuilt := len(b.dc.outputIntents) > 0
+	if b.conformance.isPDFX() {
+		b.info.trapped = "/False"
+		b.info.pdfxVersion = b.conformance.String()
+		if !rebuilt && b.outputCondition != nil {
+			b.dc.outputIntents = []*outputIntent{newOutputIntentPDFX(b.outputCondition)}
+		}
+		return
+	}
+	if b.conformance == ConformancePDFUA1 {
+		if b.dc.viewerPreferences == nil {
+			b.dc.viewerPreferences = &ViewerPreferences{}
+		}
+		b.dc.viewerPreferences.DisplayDocTitle = true
+		return
+	}
+	if !rebuilt {
+		b.dc.outputIntents = []*outputIntent{newOutputIntentSRGB()}
+	}
+	if b.conformance == ConformancePDFA3B {
+		// Embedded files are associated with the document.
+		b.dc.associatedFiles = make([]pdfObject, 0, len(b.files))
+		for _, f := range b.files {
+			if f.relationship == afRelationshipUndefined {
+				f.relationship = AFRelationshipUnspecified
+			}
+			b.dc.associatedFiles = append(b.dc.associatedFiles, f)
+		}
+	}
+}
+
+// buildConformanceMetadata adds the identification of the conformance to the XMP metadata.
+func (b *Builder) buildConformanceMetadata(m *xmpMetadata) {
+	if part := b.conformance.pdfaPart(); part > 0 {
+		id := m.schema("pdfaid", xmpNamespacePDFAID)
+		id.set("part", fmt.Sprintf("%d", part))
+		id.set("conformance", "B")
+	}
+	if b.conformance == ConformancePDFUA1 {
+		m.schema("pdfuaid", xmpNamespacePDFUAID).set("part", "1")
+	}
+	if b.conformance.isPDFX() {
+		m.schema("pdf", xmpNamespacePDF).set("Trapped", "False")
+		m.schema("pdfxid", xmpNamespacePDFXID).set("GTS_PDFXVersion", b.conformance.String())
+		// The identifier of the document is derived from the other metadata, so that it is reproducible.
+		sum := md5.Sum(m.bytes())
+		id := b.fileID(sum[:])
+		mm := m.schema("xmpMM", xmpNamespaceXMPMM)
+		mm.set("DocumentID", fmt.Sprintf("uuid:%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16]))
+		mm.set("VersionID", "1")
+		mm.set("RenditionClass", "default")
+	}
+	b.buildFacturXMetadata(m)
+}
+
+// validateConformance checks the built document, and returns the reasons why it does not conform.
+func (b *Builder) validateConformance() []string {
+	if b.facturX != facturXProfileUndefined && b.conformance != ConformancePDFA3B {
+		return []string{fmt.Sprintf("Factur-X: %s is required", ConformancePDFA3B)}
+	}
+	if b.conformance == conformanceUndefined {
+		return nil
+	}
+	errs := make([]string, 0)
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf("%s: %s", b.conformance, fmt.Sprintf(format, args...)))
+	}
+	for _, f := range b.files {
+		switch {
+		case b.conformance == ConformancePDFA2B:
+			fail("embedded files are not allowed, use %s: %s", ConformancePDFA3B, f.name)
+		case b.conformance.isPDFX():
+			fail("embedded files are not allowed: %s", f.name)
+		case b.conformance == ConformancePDFUA1:
+			// Embedded files of any format are allowed.
+		default:
+			if _, ok := f.stream.dict["/Subtype"]; !ok {
+				fail("MIME type of the embedded file is not specified: %s", f.name)
+			}
+		}
+	}
+	if b.conformance.isPDFX() && b.info.info.Title == "" {
+		fail("title is not specified")
+	}
+	if b.conformance == ConformancePDFUA1 {
+		if b.dc.structTree == nil {
+			fail("document is not tagged, use Builder.StructureTree")
+		}
+		if b.info.info.Title == "" {
+			fail("title is not specified")
+		}
+		if b.dc.lang == "" {
+			fail("language is not specified, use Builder.SetLanguage")
+		}
+	}
+	if b.conformance.isPDFX() {
+		if b.outputCondition == nil {
+			fail("output condition is not specified, use Builder.SetOutputCondition")
+		} else {
+			for _, err := range b.outputCondition.validate() {
+				fail("%s", err)
+			}
+		}
+	}
+	contents := make(map[*stream]bool)
+	for i, p := range b.dc.pages.allPages() {
+		pg, ok := p.(*page)
+		if !ok {
+			continue
+		}
+		contents[pg.contents] = true
+		if b.conformance == ConformancePDFUA1 {
+			if pg.untaggedContents > 0 {
+				fail("contents are neither in structure elements nor in artifacts on the page %d", i+1)
+			}
+			if len(pg.annots) > 0 {
+				fail("annotations are not supported on the page %d", i+1)
+			}
+		}
+		if !b.conformance.isPDFX() {
+			continue
+		}
+		if pg.trim() == nil || pg.bleed() == nil {
+			fail("trim box and bleed box are not specified on the page %d", i+1)
+		} else if !pg.bb().contains(pg.tb()) || !pg.mb().contains(pg.bb()) {
+			fail("trim box must be inside the bleed box, which must be inside the media box on the page %d", i+1)
+		}
+	}
+	used := make(map[colorSpace]bool)
+	fonts := make(map[string]bool)
+	b.dc.walk(func(obj pdfObject) {
+		switch o := obj.(type) {
+		case Font:
+			if !o.embedded() && !fonts[o.baseFont()] {
+				fonts[o.baseFont()] = true
+				fail("font is not embedded: %s", o.baseFont())
+			}
+		case *stream:
+			if contents[o] || o.dict["/Subtype"] == "/Form" {
+				for cs := range deviceColorSpaces(o.data) {
+					used[cs] = true
+				}
+			} else if cs, ok := o.dict["/ColorSpace"]; ok {
+				used[parseColorSpace(cs)] = true
+			}
+		}
+	})
+	for _, oi := range b.dc.outputIntents {
+		if used[colorSpaceDeviceCMYK] && oi.colorSpace != colorSpaceDeviceCMYK {
+			fail("%s is not allowed with the output intent %s", colorSpaceDeviceCMYK, oi.identifier)
+		}
+		if used[colorSpaceDeviceRGB] && oi.colorSpace != colorSpaceDeviceRGB {
+			fail("%s is not allowed with the output intent %s", colorSpaceDeviceRGB, oi.identifier)
+		}
+	}
+	return errs
+}
+
+// parseColorSpace returns the device color space of the name, or colorSpaceUndefined.
+func parseColorSpace(name string) colorSpace {
+	for _, cs := range []colorSpace{colorSpaceDeviceGray, colorSpaceDeviceRGB, colorSpaceDeviceCMYK} {
+		if cs.String() == name {
+			return cs
+		}
+	}
+	return colorSpaceUndefined
+}
+
+// deviceColorSpaces returns the device color spaces used by the color operators of the content stream.
+// Strings and comments in the content stream are skipped.
+func deviceColorSpaces(data [][]byte) map[colorSpace]bool {
+	used := make(map[colorSpace]bool)
+	content := bytes.Join(data, nil)
+	prev := ""
+	token := make([]byte, 0, 16)
+	operator := func() {
+		if len(token) == 0 {
+			return
+		}
+		t := string(token)
+		switch t {
+		case "g", "G":
+			used[colorSpaceDeviceGray] = true
+		case "rg", "RG":
+			used[colorSpaceDeviceRGB] = true
+		case "k", "K":
+			used[colorSpaceDeviceCMYK] = true
+		case "cs", "CS":
+			if cs := parseColorSpace(prev); cs != colorSpaceUndefined {
+				used[cs] = true
+			}
+		}
+		prev = t
+		token = token[:0]
+	}
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '(':
+			operator()
+			_, n, err := parseLiteralString(string(content[i:]))
+			if err != nil {
+				return used
+			}
+			i += n - 1
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			operator()
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return used
+			}
+			i += end
+		case c == '%':
+			operator()
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '/':
+			operator()
+			token = append(token, c)
+		case strings.IndexByte(" \t\r\n\f\x00[]{}<>", c) >= 0:
+			operator()
+		default:
+			token = append(token, c)
+		}
+	}
+	operator()
+	return used
+}
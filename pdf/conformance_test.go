@@ -0,0 +1,288 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeviceColorSpaces(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected []colorSpace
+	}{
+		{"q 0.5 g 0 0 1 RG 0 0 10 10 re B Q", []colorSpace{colorSpaceDeviceGray, colorSpaceDeviceRGB}},
+		{"0 0 0 1 k", []colorSpace{colorSpaceDeviceCMYK}},
+		{"/DeviceCMYK cs 1 0 0 0 sc", []colorSpace{colorSpaceDeviceCMYK}},
+		{"/CS0 cs 1 sc", nil},
+		{"BT /F1 12 Tf (0 0 0 1 k) Tj <6B> Tj ET", nil},
+		{"% 0 0 0 1 k\n1 g", []colorSpace{colorSpaceDeviceGray}},
+		{"(a \\) 1 k) Tj", nil},
+	}
+	for _, c := range cases {
+		used := deviceColorSpaces([][]byte{[]byte(c.content)})
+		if len(used) != len(c.expected) {
+			t.Errorf("deviceColorSpaces: %q: unexpected color spaces:%v", c.content, used)
+			continue
+		}
+		for _, cs := range c.expected {
+			if !used[cs] {
+				t.Errorf("deviceColorSpaces: %q: %s is not found", c.content, cs)
+			}
+		}
+	}
+}
+
+func buildConformance(c Conformance, prepare func(b *Builder)) (string, error) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetConformance(c)
+	b.SetReproducible(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	prepare(b)
+	var buf bytes.Buffer
+	err := b.Build(&buf)
+	return buf.String(), err
+}
+
+func TestBuilderConformancePDFA2B(t *testing.T) {
+	out, err := buildConformance(ConformancePDFA2B, func(b *Builder) {
+		b.AddPage().Rectangle(0, 0, 10, 10).FillColor(NewColorRGB(1, 0, 0)).Render()
+	})
+	if err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	for _, entry := range []string{
+		"/OutputIntents [<</Type /OutputIntent /S /GTS_PDFA1 /OutputConditionIdentifier (sRGB IEC61966-2.1)",
+		"/N 3",
+		"<pdfaid:part>2</pdfaid:part>",
+		"<pdfaid:conformance>B</pdfaid:conformance>",
+		"/Info ",
+	} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+	if strings.Contains(out, "/AF ") {
+		t.Error("Build: associated files must be omitted")
+	}
+}
+
+func TestBuilderConformancePDFUA1(t *testing.T) {
+	out, err := buildConformance(ConformancePDFUA1, func(b *Builder) {
+		b.SetInfo(DocumentInfo{Title: "Annual Report"})
+		b.SetLanguage("en-US")
+		b.SetViewerPreferences(ViewerPreferences{HideToolbar: true})
+		p := b.AddPage()
+		p.BeginStructure(b.StructureTree().AddElement(StructureRoleP))
+		p.Rectangle(0, 0, 10, 10).Render()
+		p.EndMarkedContent()
+	})
+	if err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	for _, entry := range []string{
+		"<pdfuaid:part>1</pdfuaid:part>",
+		"/HideToolbar true",
+		"/DisplayDocTitle true",
+		"/StructTreeRoot ",
+	} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+	if strings.Contains(out, "/OutputIntents ") {
+		t.Error("Build: output intents must be omitted")
+	}
+}
+
+func TestBuilderConformancePDFA3B(t *testing.T) {
+	out, err := buildConformance(ConformancePDFA3B, func(b *Builder) {
+		b.AddPage()
+		b.AttachFile("a.csv", "text/csv", strings.NewReader("1,2"))
+		f, _ := b.AttachFile("b.xml", "text/xml", strings.NewReader("<a/>"))
+		f.Relationship(AFRelationshipSource)
+	})
+	if err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	for _, entry := range []string{"<pdfaid:part>3</pdfaid:part>", "/AF [", "/AFRelationship /Unspecified", "/AFRelationship /Source"} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+}
+
+func TestBuilderConformanceError(t *testing.T) {
+	cases := []struct {
+		conformance Conformance
+		prepare     func(b *Builder)
+		message     string
+	}{
+		{ConformancePDFA2B, func(b *Builder) {
+			f := b.NewFontType1("/Helvetica")
+			b.AddFont(f)
+			b.AddPage().WriteText(10, 10, f, 12, "text")
+		}, "PDF/A-2b: font is not embedded: /Helvetica"},
+		{ConformancePDFA2B, func(b *Builder) {
+			b.AddPage().Line(0, 0, 10, 10, 1).Color(NewColorCMYK(0, 0, 0, 1)).Render()
+		}, "PDF/A-2b: /DeviceCMYK is not allowed with the output intent sRGB IEC61966-2.1"},
+		{ConformancePDFA2B, func(b *Builder) {
+			b.AttachFile("a.csv", "text/csv", strings.NewReader("1,2"))
+		}, "PDF/A-2b: embedded files are not allowed, use PDF/A-3b: a.csv"},
+		{ConformancePDFA3B, func(b *Builder) {
+			b.AttachFile("a.bin", "", strings.NewReader("1,2"))
+		}, "PDF/A-3b: MIME type of the embedded file is not specified: a.bin"},
+		{ConformancePDFUA1, func(b *Builder) {
+			b.AddPage()
+		}, "PDF/UA-1: document is not tagged, use Builder.StructureTree, PDF/UA-1: title is not specified, PDF/UA-1: language is not specified, use Builder.SetLanguage"},
+		{ConformancePDFUA1, func(b *Builder) {
+			b.SetInfo(DocumentInfo{Title: "Annual Report"})
+			b.SetLanguage("en-US")
+			p := b.AddPage()
+			p.BeginStructure(b.StructureTree().AddElement(StructureRoleP))
+			p.EndMarkedContent()
+			p.BeginArtifact()
+			p.Line(0, 0, 10, 0, 1).Render()
+			p.EndMarkedContent()
+			p.Rectangle(0, 0, 10, 10).Render()
+			p.Link(0, 0, 10, 10, LinkURI("https://example.com"))
+		}, "PDF/UA-1: contents are neither in structure elements nor in artifacts on the page 1, PDF/UA-1: annotations are not supported on the page 1"},
+	}
+	for _, c := range cases {
+		_, err := buildConformance(c.conformance, c.prepare)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("Build: expected:%s, actual:%v", c.message, err)
+		}
+	}
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetConformance(ConformancePDFA3B)
+	var buf bytes.Buffer
+	err := b.Build(&buf, WithEncryption(&PasswordEncryption{Algorithm: EncryptionAES256, UserPassword: "user"}))
+	if err == nil || err.Error() != "PDF/A-3b: encryption is not allowed" {
+		t.Errorf("Build: unexpected error:%v", err)
+	}
+}
+
+// newTestOutputCondition returns the registered printing condition with the test profile.
+func newTestOutputCondition() *OutputCondition {
+	return &OutputCondition{
+		Identifier:   "FOGRA39",
+		RegistryName: "http://www.color.org",
+		Info:         "Coated FOGRA39 (ISO 12647-2:2004)",
+		Profile:      newTestCMYKProfile(),
+	}
+}
+
+func TestBuilderConformancePDFX4(t *testing.T) {
+	out, err := buildConformance(ConformancePDFX4, func(b *Builder) {
+		b.SetInfo(DocumentInfo{Title: "Flyer"})
+		b.SetOutputCondition(newTestOutputCondition())
+		p := b.AddPageWithBox(NewBox(0, 0, 615, 862), nil)
+		p.SetTrimBox(NewBox(10, 10, 605, 852))
+		p.SetBleedBox(NewBox(1, 1, 614, 861))
+		p.Rectangle(0, 0, 10, 10).FillColor(NewColorCMYK(0, 1, 1, 0)).Render()
+	})
+	if err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	if !strings.HasPrefix(out, "%PDF-1.6\n") {
+		t.Errorf("Build: unexpected header:%q", out[:9])
+	}
+	for _, entry := range []string{
+		"/OutputIntents [<</Type /OutputIntent /S /GTS_PDFX /OutputConditionIdentifier (FOGRA39)",
+		"/N 4",
+		"/Trapped /False /GTS_PDFXVersion (PDF/X-4)",
+		"<pdf:Trapped>False</pdf:Trapped>",
+		"<pdfxid:GTS_PDFXVersion>PDF/X-4</pdfxid:GTS_PDFXVersion>",
+		"<xmpMM:DocumentID>uuid:",
+		"<xmpMM:VersionID>1</xmpMM:VersionID>",
+		"/TrimBox [10 10 605 852]",
+	} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+	if strings.Contains(out, "pdfaid") {
+		t.Error("Build: PDF/A identification must be omitted")
+	}
+}
+
+func TestBuilderConformancePDFX4OutputCondition(t *testing.T) {
+	profile := newTestCMYKProfile()
+	out, err := buildConformance(ConformancePDFX4, func(b *Builder) {
+		b.SetInfo(DocumentInfo{Title: "Flyer"})
+		p := b.AddPage()
+		p.SetTrimBox(NewBox(10, 10, 100, 100))
+		p.SetBleedBox(NewBox(0, 0, 110, 110))
+		b.SetOutputCondition(&OutputCondition{
+			Identifier:   "FOGRA39",
+			RegistryName: "http://www.color.org",
+			Info:         "Coated FOGRA39 (ISO 12647-2:2004)",
+			Profile:      profile,
+		})
+	})
+	if err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	expected := "/OutputIntents [<</Type /OutputIntent /S /GTS_PDFX /OutputConditionIdentifier (FOGRA39) /RegistryName (http://www.color.org) /Info (Coated FOGRA39 \\(ISO 12647-2:2004\\))"
+	if !strings.Contains(out, expected) {
+		t.Errorf("Build: document does not contain:%s", expected)
+	}
+	cases := []struct {
+		oc      *OutputCondition
+		message string
+	}{
+		{&OutputCondition{Identifier: "Press", Profile: profile}, "PDF/X-4: info of the output condition is not specified, which is required for a custom condition"},
+		{&OutputCondition{RegistryName: "http://www.color.org", Profile: srgbProfile()}, "PDF/X-4: identifier of the output condition is not specified, PDF/X-4: profile of the output condition must be a CMYK output profile"},
+		{&OutputCondition{Identifier: "FOGRA39", RegistryName: "http://www.color.org", Profile: []byte("not a profile")}, "PDF/X-4: profile of the output condition must be a CMYK output profile"},
+	}
+	for _, c := range cases {
+		_, err := buildConformance(ConformancePDFX4, func(b *Builder) {
+			b.SetInfo(DocumentInfo{Title: "Flyer"})
+			b.SetOutputCondition(c.oc)
+		})
+		if err == nil || err.Error() != c.message {
+			t.Errorf("Build: expected:%s, actual:%v", c.message, err)
+		}
+	}
+}
+
+func TestBuilderConformancePDFX4Error(t *testing.T) {
+	cases := []struct {
+		prepare func(b *Builder)
+		message string
+	}{
+		{func(b *Builder) {
+			b.AddPage()
+		}, "PDF/X-4: title is not specified, PDF/X-4: output condition is not specified, use Builder.SetOutputCondition, PDF/X-4: trim box and bleed box are not specified on the page 1"},
+		{func(b *Builder) {
+			b.SetInfo(DocumentInfo{Title: "Flyer"})
+			b.SetOutputCondition(newTestOutputCondition())
+			p := b.AddPage()
+			p.SetTrimBox(NewBox(10, 10, 100, 100))
+			p.SetBleedBox(NewBox(-1, 0, 110, 110))
+		}, "PDF/X-4: trim box must be inside the bleed box, which must be inside the media box on the page 1"},
+		{func(b *Builder) {
+			b.SetInfo(DocumentInfo{Title: "Flyer"})
+			b.SetOutputCondition(newTestOutputCondition())
+			p := b.AddPage()
+			p.SetTrimBox(NewBox(10, 10, 100, 100))
+			p.SetBleedBox(NewBox(20, 0, 110, 110))
+		}, "PDF/X-4: trim box must be inside the bleed box, which must be inside the media box on the page 1"},
+		{func(b *Builder) {
+			b.SetInfo(DocumentInfo{Title: "Flyer"})
+			b.SetOutputCondition(newTestOutputCondition())
+			p := b.AddPage()
+			p.SetTrimBox(NewBox(10, 10, 100, 100))
+			p.SetBleedBox(NewBox(0, 0, 110, 110))
+			p.Line(0, 0, 10, 10, 1).Color(NewColorRGB(1, 0, 0)).Render()
+			b.AttachFile("a.csv", "text/csv", strings.NewReader("1,2"))
+		}, "PDF/X-4: embedded files are not allowed: a.csv, PDF/X-4: /DeviceRGB is not allowed with the output intent FOGRA39"},
+	}
+	for _, c := range cases {
+		_, err := buildConformance(ConformancePDFX4, c.prepare)
+		if err == nil || err.Error() != c.message {
+			t.Errorf("Build: expected:%s, actual:%v", c.message, err)
+		}
+	}
+}
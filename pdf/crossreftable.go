@@ -1,8 +1,10 @@
 package pdf
 
 import (
+	"encoding/binary"
 	"fmt"
 	"sort"
+	"strings"
 )
 
 // crossRefTable is The cross-reference table.
@@ -28,7 +30,28 @@ func (crt *crossRefTable) hasEntry(obj pdfObject) bool {
 	return ok
 }
 
-func (crt *crossRefTable) compile() string {
+// size returns one greater than the highest object number in the table.
+func (crt *crossRefTable) size() int {
+	max := 0
+	for num := range crt.entries {
+		if num > max {
+			max = num
+		}
+	}
+	return max + 1
+}
+
+// addCompressedEntry adds the entry of the object which is packed in an object stream.
+// The object stream is determined when the stream is written.
+func (crt *crossRefTable) addCompressedEntry(obj pdfObject) *crossRefEntry {
+	e := newCrossRefEntry(0, 0, true)
+	e.compressed = true
+	crt.entries[obj.refNo()] = e
+	return e
+}
+
+// subSections returns the object numbers in the table, which are grouped into consecutive ranges.
+func (crt *crossRefTable) subSections() [][]int {
 	nums := make([]int, 0, len(crt.entries))
 	for i := range crt.entries {
 		nums = append(nums, i)
@@ -39,7 +62,7 @@ func (crt *crossRefTable) compile() string {
 	for i, num := range nums {
 		if i == 0 {
 			subSections = append(subSections, make([]int, 1))
-			subSections[0][0] = 0
+			subSections[0][0] = num
 		} else {
 			if num > nums[i-1]+1 {
 				subSections = append(subSections, make([]int, 1))
@@ -50,8 +73,12 @@ func (crt *crossRefTable) compile() string {
 			}
 		}
 	}
+	return subSections
+}
+
+func (crt *crossRefTable) compile() string {
 	result := "xref\n"
-	for _, section := range subSections {
+	for _, section := range crt.subSections() {
 		t := make([]byte, 0, 20*len(section))
 		for _, num := range section {
 			t = append(t, crt.entries[num].compile()...)
@@ -61,15 +88,33 @@ func (crt *crossRefTable) compile() string {
 	return result
 }
 
+// compileStream returns the index and the data of the cross-reference stream whose field widths are [1 4 2].
+func (crt *crossRefTable) compileStream() (string, []byte) {
+	index := make([]string, 0)
+	data := make([]byte, 0, 7*len(crt.entries))
+	for _, section := range crt.subSections() {
+		index = append(index, fmt.Sprintf("%d %d", section[0], len(section)))
+		for _, num := range section {
+			data = append(data, crt.entries[num].compileBinary()...)
+		}
+	}
+	return fmt.Sprintf("[%s]", strings.Join(index, " ")), data
+}
+
 // crossRefEntry is a entry of a crossRefTable.
 type crossRefEntry struct {
 	offset int
 	age    int
 	inuse  bool
+	// compressed is true if the object is packed in the object stream, whose number is stream.
+	compressed bool
+	stream     int
+	// index is the index of the object in the object stream.
+	index int
 }
 
 func newCrossRefEntry(offset int, age int, inuse bool) *crossRefEntry {
-	return &crossRefEntry{offset, age, inuse}
+	return &crossRefEntry{offset: offset, age: age, inuse: inuse}
 }
 
 func (cre *crossRefEntry) compile() string {
@@ -79,3 +124,22 @@ func (cre *crossRefEntry) compile() string {
 	}
 	return fmt.Sprintf("%010d %05d %s \n", cre.offset, cre.age, u)
 }
+
+// compileBinary returns the entry of a cross-reference stream whose field widths are [1 4 2].
+func (cre *crossRefEntry) compileBinary() []byte {
+	b := make([]byte, 7)
+	switch {
+	case !cre.inuse:
+		b[0] = 0
+		binary.BigEndian.PutUint16(b[5:], uint16(cre.age))
+	case cre.compressed:
+		b[0] = 2
+		binary.BigEndian.PutUint32(b[1:], uint32(cre.stream))
+		binary.BigEndian.PutUint16(b[5:], uint16(cre.index))
+	default:
+		b[0] = 1
+		binary.BigEndian.PutUint32(b[1:], uint32(cre.offset))
+		binary.BigEndian.PutUint16(b[5:], uint16(cre.age))
+	}
+	return b
+}
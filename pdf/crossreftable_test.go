@@ -1,6 +1,9 @@
 package pdf
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
 func isEqualCrossRefEntry(t *testing.T, expected, actual *crossRefEntry) {
 	t.Helper()
@@ -94,3 +97,35 @@ func TestCrossRefEntryCompile(t *testing.T) {
 		t.Errorf("expected:'%s' actual:'%s'", expected2, actual2)
 	}
 }
+
+func TestCrossRefTableSize(t *testing.T) {
+	crt := newCrossRefTable()
+	if crt.size() != 1 {
+		t.Errorf("size: expected:1 actual:%d", crt.size())
+	}
+	crt.addNewEntry(&objectIdentifier{5, 0}, 10)
+	crt.addNewEntry(&objectIdentifier{1, 0}, 20)
+	if crt.size() != 6 {
+		t.Errorf("size: expected:6 actual:%d", crt.size())
+	}
+}
+
+func TestCrossRefTableCompileStream(t *testing.T) {
+	crt := newCrossRefTable()
+	crt.addNewEntry(&objectIdentifier{1, 0}, 0x0102)
+	e := crt.addCompressedEntry(&objectIdentifier{2, 0})
+	e.stream = 5
+	e.index = 3
+	crt.addNewEntry(&objectIdentifier{5, 0}, 0x0A0B0C0D)
+	index, data := crt.compileStream()
+	testCompillation(t, "[0 3 5 1]", index)
+	expected := []byte{
+		0, 0, 0, 0, 0, 0xFF, 0xFF,
+		1, 0, 0, 0x01, 0x02, 0, 0,
+		2, 0, 0, 0, 5, 0, 3,
+		1, 0x0A, 0x0B, 0x0C, 0x0D, 0, 0,
+	}
+	if !bytes.Equal(expected, data) {
+		t.Errorf("compileStream: expected:%v, actual:%v", expected, data)
+	}
+}
@@ -0,0 +1,31 @@
+package pdf
+
+import "fmt"
+
+// explicitDestination returns the destination array that locates the page.
+func explicitDestination(page Page, destType OutlineDestination) string {
+	return fmt.Sprintf("[%s %s]", page.indirectReference(), destType.compile())
+}
+
+// namedDestination is a destination registered on a page with its name.
+// It locates the coordinate of the page at the upper-left corner of the window.
+type namedDestination struct {
+	name string
+	page Page
+	left int
+	top  int
+}
+
+func newNamedDestination(name string, page Page, left, top int) *namedDestination {
+	return &namedDestination{
+		name: name,
+		page: page,
+		left: left,
+		top:  top,
+	}
+}
+
+// compile returns the destination array on the page.
+func (d *namedDestination) compile() string {
+	return fmt.Sprintf("[%s /XYZ %d %d null]", d.page.indirectReference(), d.left, d.top)
+}
@@ -0,0 +1,65 @@
+package pdf
+
+import "testing"
+
+func TestExplicitDestination(t *testing.T) {
+	p := &mockPage{}
+	p.objectNumber = 3
+	testCompillation(t, "[3 0 R /FitH 10]", explicitDestination(p, OutlineDestinationVertical(10)))
+}
+
+func TestNamedDestination(t *testing.T) {
+	pl := newRootPage(NewBox(0, 0, 100, 200), NewBox(10, 20, 90, 180))
+	p := pl.newPage(nil, nil, nil)
+	p.(*page).objectNumber = 3
+	p.AddDestination("chapter-1", 5, 30)
+	ds := p.destinations()
+	if len(ds) != 1 {
+		t.Fatalf("AddDestination: destination size: expected:1 actual:%d", len(ds))
+	}
+	if ds[0].name != "chapter-1" {
+		t.Errorf("AddDestination: name: expected:chapter-1 actual:%s", ds[0].name)
+	}
+	testCompillation(t, "[3 0 R /XYZ 15 150 null]", ds[0].compile())
+}
+
+func TestDocumentCatalogDestinations(t *testing.T) {
+	dc := newDocumentCatalog(NewBoxA4(), NewBoxA4())
+	p1 := dc.pages.newPage(nil, nil, nil)
+	p2 := dc.pages.newPage(nil, nil, nil)
+	p1.AddDestination("b", 0, 0)
+	p2.AddDestination("a", 0, 0)
+	if err := dc.build(); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	if len(dc.names.dests.names) != 2 {
+		t.Errorf("build: destinations are not collected: %v", dc.names.dests.names)
+	}
+	dc.names.dests.objectNumber = 4
+	testCompillation(t, "1 0 obj\n<</Type /Catalog /Pages 2 0 R /Names <</Dests 4 0 R>>>>\nendobj\n", dc.compile())
+	p2.AddDestination("b", 0, 0)
+	if err := dc.build(); err == nil {
+		t.Error("build: duplicate destination must be error")
+	}
+}
+
+func TestDocumentCatalogNamedTargets(t *testing.T) {
+	dc := newDocumentCatalog(NewBoxA4(), NewBoxA4())
+	p1 := dc.pages.newPage(nil, nil, nil)
+	p2 := dc.pages.newPage(nil, nil, nil)
+	p1.AddDestination("chapter-1", 0, 0)
+	p2.Link(0, 0, 10, 10, LinkNamed("chapter-1"))
+	dc.Outline().AddNamedItem("first", "chapter-1")
+	if err := dc.build(); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	p2.Link(0, 0, 10, 10, LinkNamed("chapter-2"))
+	if err := dc.build(); err == nil || err.Error() != "link: named destination is not registered on the page 2: chapter-2" {
+		t.Errorf("build: unexpected error:%v", err)
+	}
+	p2.AddDestination("chapter-2", 0, 0)
+	dc.Outline().AddNamedItem("first", "chapter-1").AddNamedItem("third", "chapter-3")
+	if err := dc.build(); err == nil || err.Error() != "outline: named destination is not registered: chapter-3" {
+		t.Errorf("build: unexpected error:%v", err)
+	}
+}
@@ -2,14 +2,41 @@ package pdf
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 // documentCatalog is a root object of a pdf document graph.
 type documentCatalog struct {
 	objectIdentifier
-	pages   *pageList
-	outline Outline
+	pages    *pageList
+	outline  Outline
+	metadata *stream
+	labels   *pageLabels
+	names    *names
+	form     *acroForm
+	// pageMode specifies how the document shall be displayed when opened.
+	pageMode PageMode
+	// pageLayout specifies the page layout when the document is opened.
+	pageLayout PageLayout
+	// viewerPreferences controls the way the document is presented.
+	viewerPreferences *ViewerPreferences
+	// openAction is the destination displayed when the document is opened.
+	openAction *openAction
+	// extensionLevel is the Adobe extension level to PDF 1.7, or 0 if no extension is used.
+	extensionLevel int
+	// dss is the document security store, or nil.
+	dss *dss
+	// outputIntents describe the color characteristics of the output devices.
+	outputIntents []*outputIntent
+	// associatedFiles are the files associated with the document.
+	associatedFiles []pdfObject
+	// structTree is the root of the logical structure, or nil if the document is not tagged.
+	structTree *structTreeRoot
+	// lang is the natural language of the texts in the document, or empty.
+	lang string
+	// layers are the optional content groups in the order in which they were declared.
+	layers []*optionalContentGroup
 }
 
 // newDocumentCatalog returns a document catalog with a root page.
@@ -20,8 +47,12 @@ func newDocumentCatalog(mb, cb *Box) *documentCatalog {
 			objectNumber:     1,
 			generationNumber: 0,
 		},
-		pages:   newRootPage(mb, cb),
-		outline: nil,
+		pages:    newRootPage(mb, cb),
+		outline:  nil,
+		metadata: nil,
+		labels:   nil,
+		names:    newNames(),
+		pageMode: pageModeUndefined,
 	}
 }
 
@@ -36,12 +67,229 @@ func (dc *documentCatalog) Outline() Outline {
 	return o
 }
 
+// pageLabels returns the page labels.
+// If page labels have not been created, creates new page labels and returns it.
+func (dc *documentCatalog) pageLabels() *pageLabels {
+	if dc.labels == nil {
+		dc.labels = newPageLabels()
+	}
+	return dc.labels
+}
+
+// build resolves document-level features which depend on the page order.
+func (dc *documentCatalog) build() error {
+	pages := dc.pages.allPages()
+	// The pages written by the streaming builder precede the pages.
+	offset := dc.pages.countWritten()
+	if err := validatePages(pages, offset); err != nil {
+		return err
+	}
+	if dc.labels != nil {
+		if err := dc.labels.resolve(pages, offset); err != nil {
+			return err
+		}
+	}
+	if err := dc.buildDestinations(pages); err != nil {
+		return err
+	}
+	if err := dc.validateNamedTargets(pages, offset); err != nil {
+		return err
+	}
+	if dc.structTree != nil {
+		if err := dc.structTree.build(pages); err != nil {
+			return err
+		}
+	}
+	return dc.buildAcroForm(pages)
+}
+
+// validatePages checks the pages, which follow offset pages.
+func validatePages(pages []Page, offset int) error {
+	for i, p := range pages {
+		pg, ok := p.(*page)
+		if !ok {
+			continue
+		}
+		if err := validatePage(pg, offset+i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePage checks the rotation, the user unit and the marked contents of the page of the number.
+func validatePage(pg *page, number int) error {
+	if len(pg.errs) > 0 {
+		return fmt.Errorf("%s on the page %d", pg.errs[0], number)
+	}
+	if len(pg.markedContents) > 0 {
+		return fmt.Errorf("page: marked content is not ended on the page %d", number)
+	}
+	if r := pg.rot(); r%90 != 0 {
+		return fmt.Errorf("page: rotation must be a multiple of 90 on the page %d: %d", number, r)
+	}
+	if u := pg.uu(); u < 0 {
+		return fmt.Errorf("page: user unit must be positive on the page %d: %s", number, strconv.FormatFloat(u, 'f', -1, 64))
+	}
+	return nil
+}
+
+// buildAcroForm collects the form fields on the pages.
+// The interactive form uses the default resource as its default resource.
+// If the form has been built, the rebuilt fields keep the object numbers of the previous ones.
+func (dc *documentCatalog) buildAcroForm(pages []Page) error {
+	previous := dc.form
+	dc.form = nil
+	for _, p := range pages {
+		for _, a := range p.annotations() {
+			var err error
+			switch f := a.(type) {
+			case *radioButton:
+				err = dc.acroForm().addRadioButton(f)
+			case formField:
+				err = dc.acroForm().addField(f)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if dc.form == nil {
+		return nil
+	}
+	if previous != nil {
+		dc.form.keepNumbers(previous)
+	}
+	return dc.form.build()
+}
+
+// acroForm returns the interactive form.
+// If the interactive form has not been created, creates it.
+func (dc *documentCatalog) acroForm() *acroForm {
+	if dc.form == nil {
+		dc.form = newAcroForm(dc.pages.resource)
+	}
+	return dc.form
+}
+
+// buildDestinations collects the named destinations registered on the pages.
+// If the tree has been built, the rebuilt tree keeps the object number of the previous one.
+func (dc *documentCatalog) buildDestinations(pages []Page) error {
+	previous := dc.names.dests
+	dc.names.dests = nil
+	for _, p := range pages {
+		for _, d := range p.destinations() {
+			if dc.names.dests == nil {
+				dc.names.dests = newNameTree()
+				if previous != nil {
+					dc.names.dests.objectIdentifier = previous.objectIdentifier
+				}
+			}
+			if err := dc.names.dests.add(d.name, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateNamedTargets checks that the links on the pages and the outline items refer the registered named destinations.
+func (dc *documentCatalog) validateNamedTargets(pages []Page, offset int) error {
+	for i, p := range pages {
+		for _, name := range namedTargets(p) {
+			if !dc.hasDestination(name) {
+				return fmt.Errorf("link: named destination is not registered on the page %d: %s", offset+i+1, name)
+			}
+		}
+	}
+	if dc.outline == nil {
+		return nil
+	}
+	var missing string
+	dc.outline.walk(func(obj pdfObject) {
+		if oi, ok := obj.(*outlineItem); ok && oi.destName != "" && !dc.hasDestination(oi.destName) && missing == "" {
+			missing = oi.destName
+		}
+	})
+	if missing != "" {
+		return fmt.Errorf("outline: named destination is not registered: %s", missing)
+	}
+	return nil
+}
+
+// hasDestination returns whether the named destination is registered by Page.AddDestination.
+func (dc *documentCatalog) hasDestination(name string) bool {
+	return dc.names.dests != nil && dc.names.dests.names[name] != nil
+}
+
+// namedTargets returns the names of the destinations to which the links on the page jump.
+func namedTargets(p Page) []string {
+	names := make([]string, 0)
+	for _, a := range p.annotations() {
+		if l, ok := a.(*link); ok {
+			if t, ok := l.target.(*namedLinkTarget); ok {
+				names = append(names, t.name)
+			}
+		}
+	}
+	return names
+}
+
 func (dc *documentCatalog) compile() string {
-	options := make([]string, 1, 2)
+	options := make([]string, 1, 8)
 	options[0] = fmt.Sprintf("/Pages %s", dc.pages.indirectReference())
 	if dc.outline != nil {
 		options = append(options, fmt.Sprintf("/Outlines %s", dc.outline.indirectReference()))
 	}
+	if dc.metadata != nil {
+		options = append(options, fmt.Sprintf("/Metadata %s", dc.metadata.indirectReference()))
+	}
+	if dc.labels != nil {
+		options = append(options, fmt.Sprintf("/PageLabels %s", dc.labels.compile()))
+	}
+	if !dc.names.isEmpty() {
+		options = append(options, fmt.Sprintf("/Names %s", dc.names.compile()))
+	}
+	if dc.form != nil {
+		options = append(options, fmt.Sprintf("/AcroForm %s", dc.form.compile()))
+	}
+	if pm := dc.pageMode.compile(); pm != "" {
+		options = append(options, fmt.Sprintf("/PageMode %s", pm))
+	}
+	if pl := dc.pageLayout.compile(); pl != "" {
+		options = append(options, fmt.Sprintf("/PageLayout %s", pl))
+	}
+	if dc.viewerPreferences != nil {
+		options = append(options, fmt.Sprintf("/ViewerPreferences %s", dc.viewerPreferences.compile()))
+	}
+	if dc.openAction != nil {
+		options = append(options, fmt.Sprintf("/OpenAction %s", dc.openAction.compile()))
+	}
+	if dc.dss != nil {
+		options = append(options, fmt.Sprintf("/DSS %s", dc.dss.indirectReference()))
+	}
+	if len(dc.outputIntents) > 0 {
+		intents := make([]string, 0, len(dc.outputIntents))
+		for _, oi := range dc.outputIntents {
+			intents = append(intents, oi.compile())
+		}
+		options = append(options, fmt.Sprintf("/OutputIntents [%s]", strings.Join(intents, " ")))
+	}
+	if len(dc.associatedFiles) > 0 {
+		options = append(options, fmt.Sprintf("/AF %s", references(dc.associatedFiles)))
+	}
+	if dc.structTree != nil {
+		options = append(options, fmt.Sprintf("/MarkInfo <</Marked true>> /StructTreeRoot %s", dc.structTree.indirectReference()))
+	}
+	if len(dc.layers) > 0 {
+		options = append(options, fmt.Sprintf("/OCProperties %s", compileOCProperties(dc.layers)))
+	}
+	if dc.lang != "" {
+		options = append(options, fmt.Sprintf("/Lang %s", textString(dc.lang)))
+	}
+	if dc.extensionLevel > 0 {
+		options = append(options, fmt.Sprintf("/Extensions <</ADBE <</BaseVersion /1.7 /ExtensionLevel %d>>>>", dc.extensionLevel))
+	}
 	return dc.bracket(fmt.Sprintf(
 		"<</Type /Catalog %s>>",
 		strings.Join(options, " ")))
@@ -53,4 +301,17 @@ func (dc *documentCatalog) walk(walker func(obj pdfObject)) {
 	if dc.outline != nil {
 		dc.outline.walk(walker)
 	}
+	if dc.metadata != nil {
+		walker(dc.metadata)
+	}
+	dc.names.walk(walker)
+	dc.form.walk(walker)
+	dc.dss.walk(walker)
+	dc.structTree.walk(walker)
+	for _, l := range dc.layers {
+		l.walk(walker)
+	}
+	for _, oi := range dc.outputIntents {
+		walker(oi.profile)
+	}
 }
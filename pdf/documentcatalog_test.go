@@ -25,3 +25,19 @@ func TestDocumentCatalog(t *testing.T) {
 	}
 	testCompillation(t, "1 0 obj\n<</Type /Catalog /Pages 2 0 R>>\nendobj\n", dc.compile())
 }
+
+func TestDocumentCatalogPageLabels(t *testing.T) {
+	dc := newDocumentCatalog(NewBoxA4(), NewBoxA4())
+	p1 := dc.pages.newPage(nil, nil, nil)
+	p2 := dc.pages.newPage(nil, nil, nil)
+	dc.pageLabels().add(p1, PageLabelRomanLower, "", 1)
+	dc.pageLabels().add(p2, PageLabelDecimal, "", 1)
+	if dc.pageLabels() != dc.labels {
+		t.Error("page labels is dup")
+	}
+	dc.pages.buildPageTree(pageTreeOrder)
+	if err := dc.build(); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	testCompillation(t, "1 0 obj\n<</Type /Catalog /Pages 2 0 R /PageLabels <</Nums [0 <</S /r>> 1 <</S /D>>]>>>>\nendobj\n", dc.compile())
+}
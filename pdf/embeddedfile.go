@@ -0,0 +1,135 @@
+package pdf
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AFRelationship is the relationship between the document and an associated file.
+type AFRelationship int
+
+const (
+	afRelationshipUndefined AFRelationship = iota
+	// AFRelationshipSource : The original source material for the associated content.
+	AFRelationshipSource
+	// AFRelationshipData : Information used to derive a visual presentation, such as for a table or a graph.
+	AFRelationshipData
+	// AFRelationshipAlternative : An alternative representation of content, for example audio.
+	AFRelationshipAlternative
+	// AFRelationshipSupplement : A supplemental representation of the original source or data that may be more easily consumable.
+	AFRelationshipSupplement
+	// AFRelationshipUnspecified : The relationship is not known or cannot be described using one of the other values.
+	AFRelationshipUnspecified
+)
+
+func (r AFRelationship) compile() string {
+	switch r {
+	case AFRelationshipSource:
+		return "/Source"
+	case AFRelationshipData:
+		return "/Data"
+	case AFRelationshipAlternative:
+		return "/Alternative"
+	case AFRelationshipSupplement:
+		return "/Supplement"
+	case AFRelationshipUnspecified:
+		return "/Unspecified"
+	default:
+		return ""
+	}
+}
+
+// EmbeddedFile is a file embedded in the document.
+// It is listed in the attachments of the document, and may be shown by a file attachment annotation.
+type EmbeddedFile interface {
+	// Description specifies the description of the file displayed to the user.
+	Description(description string) EmbeddedFile
+	// ModDate specifies the date and time when the file was last modified.
+	// If it is not specified, the time when the document is built is used.
+	ModDate(t time.Time) EmbeddedFile
+	// Relationship specifies the relationship between the document and the file.
+	// PDF/A-3 requires it, and AFRelationshipUnspecified is used if it is not specified.
+	Relationship(r AFRelationship) EmbeddedFile
+	// file returns the implementation, so that the interface is not implemented outside the package.
+	file() *embeddedFile
+}
+
+// embeddedFile is a file specification of an embedded file stream.
+type embeddedFile struct {
+	objectIdentifier
+	name        string
+	description string
+	modDate     time.Time
+	// size and checksum are the length and the MD5 digest of the uncompressed data.
+	size         int
+	checksum     []byte
+	stream       *stream
+	relationship AFRelationship
+}
+
+func newEmbeddedFile(name, mimeType string, data []byte) *embeddedFile {
+	s := newDeflatedStream()
+	s.dict["/Type"] = "/EmbeddedFile"
+	if mimeType != "" {
+		s.dict["/Subtype"] = nameObject(mimeType)
+	}
+	s.addBinaryDatum(data)
+	sum := md5.Sum(data)
+	return &embeddedFile{
+		objectIdentifier: objectIdentifier{},
+		name:             name,
+		size:             len(data),
+		checksum:         sum[:],
+		stream:           s,
+	}
+}
+
+func (ef *embeddedFile) Description(description string) EmbeddedFile {
+	ef.description = description
+	return ef
+}
+
+func (ef *embeddedFile) ModDate(t time.Time) EmbeddedFile {
+	ef.modDate = t
+	return ef
+}
+
+func (ef *embeddedFile) Relationship(r AFRelationship) EmbeddedFile {
+	ef.relationship = r
+	return ef
+}
+
+func (ef *embeddedFile) file() *embeddedFile {
+	return ef
+}
+
+// build fills the modification date if it has not been specified, and writes the parameters of the stream.
+func (ef *embeddedFile) build(now time.Time) {
+	if ef.modDate.IsZero() {
+		ef.modDate = now
+	}
+	ef.stream.dict["/Params"] = fmt.Sprintf("<</Size %d /CheckSum %s /ModDate %s>>",
+		ef.size, hexString(ef.checksum), dateString(ef.modDate))
+}
+
+func (ef *embeddedFile) compile() string {
+	dict := make([]string, 0, 6)
+	dict = append(dict, "/Type /Filespec")
+	dict = append(dict, fmt.Sprintf("/F %s /UF %s", textString(ef.name), textString(ef.name)))
+	if ef.description != "" {
+		dict = append(dict, fmt.Sprintf("/Desc %s", textString(ef.description)))
+	}
+	ref := ef.stream.indirectReference()
+	dict = append(dict, fmt.Sprintf("/EF <</F %s /UF %s>>", ref, ref))
+	if r := ef.relationship.compile(); r != "" {
+		dict = append(dict, fmt.Sprintf("/AFRelationship %s", r))
+	}
+	return ef.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
+}
+
+func (ef *embeddedFile) walk(walker func(obj pdfObject)) {
+	walker(ef)
+	walker(ef.stream)
+}
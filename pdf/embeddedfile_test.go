@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbeddedFile(t *testing.T) {
+	data := []byte("id,name\n1,apple\n")
+	ef := newEmbeddedFile("source.csv", "text/csv", data)
+	ef.Description("Source data").ModDate(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	ef.objectNumber = 4
+	ef.stream.objectNumber = 5
+	ef.build(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	expected := "4 0 obj\n<</Type /Filespec /F (source.csv) /UF (source.csv) /Desc (Source data) /EF <</F 5 0 R /UF 5 0 R>>>>\nendobj\n"
+	testCompillation(t, expected, ef.compile())
+	stream, err := ef.stream.compile()
+	if err != nil {
+		t.Fatalf("compile: unexpected error:%s", err)
+	}
+	sum := md5.Sum(data)
+	params := fmt.Sprintf("/Params <</Size 16 /CheckSum <%X> /ModDate (D:20180102030405Z)>>", sum)
+	for _, entry := range []string{"/Type /EmbeddedFile", "/Subtype /text#2Fcsv", "/Filter /FlateDecode", params} {
+		if !bytes.Contains(stream, []byte(entry)) {
+			t.Errorf("compile: stream does not contain:%s\n%s", entry, stream)
+		}
+	}
+	start := bytes.Index(stream, []byte("stream\n")) + len("stream\n")
+	end := bytes.LastIndex(stream, []byte("\nendstream"))
+	r, err := zlib.NewReader(bytes.NewReader(stream[start:end]))
+	if err != nil {
+		t.Fatalf("compile: data is not deflated:%s", err)
+	}
+	if inflated, _ := ioutil.ReadAll(r); !bytes.Equal(inflated, data) {
+		t.Errorf("compile: unexpected data:%q", inflated)
+	}
+}
+
+func TestEmbeddedFileDefaultModDate(t *testing.T) {
+	ef := newEmbeddedFile("empty.bin", "", nil)
+	ef.build(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	if _, ok := ef.stream.dict["/Subtype"]; ok {
+		t.Error("newEmbeddedFile: subtype must be omitted without MIME type")
+	}
+	if !strings.Contains(ef.stream.dict["/Params"], "/Size 0 ") || !strings.Contains(ef.stream.dict["/Params"], "/ModDate (D:20190102030405Z)") {
+		t.Errorf("build: unexpected params:%s", ef.stream.dict["/Params"])
+	}
+}
+
+func TestFileAttachment(t *testing.T) {
+	p := newTestPage()
+	ef := newEmbeddedFile("source.csv", "text/csv", []byte("1,2"))
+	ef.objectNumber = 6
+	a := p.FileAttachment(5, 10, ef).Author("reporter")
+	if len(p.annotations()) != 1 || p.annotations()[0] != a {
+		t.Fatal("FileAttachment: annotation is not added to the page")
+	}
+	fa := a.(*fileAttachment)
+	fa.objectNumber = 4
+	fa.appearance.objectNumber = 5
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	fa.build(now)
+	expected := "4 0 obj\n<</Type /Annot /Subtype /FileAttachment /Rect [15 150 35 170] /P 3 0 R /F 28 /C [0.600000 0.750000 0.850000] /Contents (source.csv) /T (reporter) /CreationDate (D:20190102030405Z) /M (D:20190102030405Z) /AP <</N 5 0 R>> /FS 6 0 R /Name /Paperclip>>\nendobj\n"
+	testCompillation(t, expected, fa.compile())
+	testRendering(t, "q 0.000000 G 0.600000 0.750000 0.850000 rg 4 0 12 20 re B Q\n", string(fa.appearance.data[0]))
+	testRendering(t, "q 12 20 m 16 16 l 1 w  S Q\n", string(fa.appearance.data[1]))
+	walked := make([]pdfObject, 0)
+	fa.walk(func(obj pdfObject) {
+		walked = append(walked, obj)
+	})
+	if len(walked) != 4 || walked[0] != a || walked[1] != fa.appearance || walked[2] != ef || walked[3] != ef.stream {
+		t.Errorf("walk: unexpected objects: %v", walked)
+	}
+}
+
+func TestFileAttachmentNil(t *testing.T) {
+	var ef *embeddedFile
+	for _, file := range []EmbeddedFile{nil, ef} {
+		p := newTestPage()
+		p.FileAttachment(5, 10, file).Author("reporter")
+		if len(p.annotations()) != 0 {
+			t.Error("FileAttachment: annotation without the file must not be added to the page")
+		}
+		if err := validatePage(p, 1); err == nil || err.Error() != "file attachment: embedded file is not specified on the page 1" {
+			t.Errorf("validatePage: unexpected error:%v", err)
+		}
+	}
+}
+
+type errorReader struct{}
+
+func (errorReader) Read(p []byte) (int, error) {
+	return 0, errors.New("error")
+}
+
+func TestBuilderAttachFile(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetReproducible(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	ef, err := b.AttachFile("source.csv", "text/csv", strings.NewReader("1,2"))
+	if err != nil {
+		t.Fatalf("AttachFile: unexpected error:%s", err)
+	}
+	if _, err := b.AttachFile("source.csv", "text/csv", strings.NewReader("3,4")); err == nil {
+		t.Error("AttachFile: duplicate name must be error")
+	}
+	if _, err := b.AttachFile("broken.csv", "text/csv", errorReader{}); err == nil {
+		t.Error("AttachFile: read error must be error")
+	}
+	b.AddPage().FileAttachment(10, 10, ef)
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.String()
+	f := ef.(*embeddedFile)
+	for _, expected := range []string{
+		fmt.Sprintf("/Names <</EmbeddedFiles %s>>", b.dc.names.embeddedFiles.indirectReference()),
+		fmt.Sprintf("<</Names [(source.csv) %s]>>", f.indirectReference()),
+		fmt.Sprintf("/FS %s", f.indirectReference()),
+		fmt.Sprintf("%d 0 obj\n<</Type /Filespec ", f.refNo()),
+		fmt.Sprintf("%d 0 obj\n<</Filter /FlateDecode", f.stream.refNo()),
+		"/ModDate (D:20190102030405Z)",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Build: output does not contain:%q", expected)
+		}
+	}
+	if strings.Count(out, "/Type /Filespec") != 1 {
+		t.Error("Build: file specification must be written once")
+	}
+}
@@ -0,0 +1,281 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encryption is a security handler which encrypts the strings and streams of the document.
+//
+// ex. PasswordEncryption
+type Encryption interface {
+	// newEncryption computes the file encryption key and the encryption dictionary
+	// for the first element of the file identifier.
+	newEncryption(id []byte, random io.Reader) (*encryption, error)
+}
+
+// cryptFilter is the method used to encrypt strings and streams.
+type cryptFilter int
+
+const (
+	cryptFilterUndefined cryptFilter = iota
+	// cryptFilterV2 : RC4 with the 128-bit key.
+	cryptFilterV2
+	// cryptFilterAESV2 : AES-128 in CBC mode.
+	cryptFilterAESV2
+	// cryptFilterAESV3 : AES-256 in CBC mode.
+	cryptFilterAESV3
+)
+
+func (cf cryptFilter) compile() string {
+	switch cf {
+	case cryptFilterV2:
+		return "/V2"
+	case cryptFilterAESV2:
+		return "/AESV2"
+	case cryptFilterAESV3:
+		return "/AESV3"
+	default:
+		return ""
+	}
+}
+
+// keyLength returns the length of the file encryption key in bytes.
+func (cf cryptFilter) keyLength() int {
+	if cf == cryptFilterAESV3 {
+		return 32
+	}
+	return 16
+}
+
+// entries returns the entries of the encryption dictionary which specify the crypt filter.
+// RC4 is specified without crypt filter dictionaries for compatibility.
+// Arguments name and params are the name of the crypt filter and its additional entries.
+func (cf cryptFilter) entries(name string, params ...string) []string {
+	if cf == cryptFilterV2 {
+		return []string{"/V 2 /Length 128"}
+	}
+	v := 4
+	if cf == cryptFilterAESV3 {
+		v = 5
+	}
+	dict := append([]string{"/AuthEvent /DocOpen", fmt.Sprintf("/CFM %s /Length %d", cf.compile(), cf.keyLength())}, params...)
+	return []string{
+		fmt.Sprintf("/V %d /Length %d", v, cf.keyLength()*8),
+		fmt.Sprintf("/CF <<%s <<%s>>>>", name, strings.Join(dict, " ")),
+		fmt.Sprintf("/StmF %s /StrF %s", name, name),
+	}
+}
+
+// encryption is an encryption dictionary.
+// It encrypts the strings and the streams of indirect objects with the file encryption key.
+type encryption struct {
+	objectIdentifier
+	key    []byte
+	filter cryptFilter
+	// entries are the entries of the encryption dictionary.
+	entries []string
+	// random is the source of initialization vectors.
+	random io.Reader
+	// extensionLevel is the Adobe extension level required to decrypt the document, or 0.
+	extensionLevel int
+}
+
+func newEncryption(key []byte, filter cryptFilter, random io.Reader, entries ...string) *encryption {
+	e := &encryption{
+		objectIdentifier: objectIdentifier{},
+		key:              key,
+		filter:           filter,
+		entries:          entries,
+		random:           random,
+	}
+	if filter == cryptFilterAESV3 {
+		e.extensionLevel = 8
+	}
+	return e
+}
+
+// objectKey returns the key to encrypt the strings and the stream of the object.
+// AES-256 uses the file encryption key for all objects.
+func (e *encryption) objectKey(obj pdfObject) []byte {
+	if e.filter == cryptFilterAESV3 {
+		return e.key
+	}
+	h := md5.New()
+	h.Write(e.key)
+	num, gen := obj.refNo(), obj.age()
+	h.Write([]byte{byte(num), byte(num >> 8), byte(num >> 16), byte(gen), byte(gen >> 8)})
+	if e.filter == cryptFilterAESV2 {
+		h.Write([]byte("sAlT"))
+	}
+	n := len(e.key) + 5
+	if n > 16 {
+		n = 16
+	}
+	return h.Sum(nil)[:n]
+}
+
+// encrypt encrypts the data of the object.
+// AES ciphertext is preceded by the random initialization vector.
+func (e *encryption) encrypt(obj pdfObject, data []byte) ([]byte, error) {
+	key := e.objectKey(obj)
+	if e.filter == cryptFilterV2 {
+		c, err := rc4.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]byte, len(data))
+		c.XORKeyStream(res, data)
+		return res, nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	// PKCS#5 padding is always added.
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	plain := append(append(make([]byte, 0, len(data)+padding), data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	res := make([]byte, aes.BlockSize+len(plain))
+	if _, err := io.ReadFull(e.random, res[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, res[:aes.BlockSize]).CryptBlocks(res[aes.BlockSize:], plain)
+	return res, nil
+}
+
+// encryptStrings replaces the literal and hexadecimal strings in the compiled object with encrypted hexadecimal strings.
+func (e *encryption) encryptStrings(obj pdfObject, s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		var raw []byte
+		var n int
+		var err error
+		switch {
+		case s[i] == '(':
+			raw, n, err = parseLiteralString(s[i:])
+		case s[i] == '<' && i+1 < len(s) && s[i+1] == '<':
+			// The beginning of a dictionary.
+			b.WriteString("<<")
+			i += 2
+			continue
+		case s[i] == '<':
+			raw, n, err = parseHexString(s[i:])
+		default:
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("encryption: object %d: %s", obj.refNo(), err)
+		}
+		enc, err := e.encrypt(obj, raw)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(hexString(enc))
+		i += n
+	}
+	return b.String(), nil
+}
+
+func (e *encryption) compile() string {
+	return e.bracket(fmt.Sprintf("<<%s>>", strings.Join(e.entries, " ")))
+}
+
+func (e *encryption) walk(walker func(obj pdfObject)) {
+	if e != nil {
+		walker(e)
+	}
+}
+
+// parseLiteralString returns the bytes of the literal string at the beginning of s,
+// and the length of its expression.
+func parseLiteralString(s string) ([]byte, int, error) {
+	res := make([]byte, 0, len(s))
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(':
+			if depth > 0 {
+				res = append(res, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return res, i + 1, nil
+			}
+			res = append(res, c)
+		case '\\':
+			i++
+			if i >= len(s) {
+				break
+			}
+			switch d := s[i]; d {
+			case 'n':
+				res = append(res, '\n')
+			case 'r':
+				res = append(res, '\r')
+			case 't':
+				res = append(res, '\t')
+			case 'b':
+				res = append(res, '\b')
+			case 'f':
+				res = append(res, '\f')
+			case '\r':
+				// A backslash at the end of a line continues the string.
+				if i+1 < len(s) && s[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if d < '0' || d > '7' {
+					res = append(res, d)
+					break
+				}
+				octal := 0
+				for j := 0; j < 3 && i < len(s) && s[i] >= '0' && s[i] <= '7'; j++ {
+					octal = octal*8 + int(s[i]-'0')
+					i++
+				}
+				i--
+				res = append(res, byte(octal))
+			}
+		default:
+			res = append(res, c)
+		}
+	}
+	return nil, 0, errors.New("unterminated literal string")
+}
+
+// parseHexString returns the bytes of the hexadecimal string at the beginning of s,
+// and the length of its expression.
+func parseHexString(s string) ([]byte, int, error) {
+	end := strings.IndexByte(s, '>')
+	if end < 0 {
+		return nil, 0, errors.New("unterminated hexadecimal string")
+	}
+	digits := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" \t\r\n\f", r) {
+			return -1
+		}
+		return r
+	}, s[1:end])
+	// The final digit is assumed to be 0 if it is missing.
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	res, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, 0, err
+	}
+	return res, end + 1, nil
+}
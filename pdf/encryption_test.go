@@ -0,0 +1,158 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rc4"
+	"strings"
+	"testing"
+)
+
+// decrypt decrypts the data encrypted by the encryption.
+func decrypt(t *testing.T, e *encryption, obj pdfObject, data []byte) []byte {
+	key := e.objectKey(obj)
+	if e.filter == cryptFilterV2 {
+		c, _ := rc4.NewCipher(key)
+		res := make([]byte, len(data))
+		c.XORKeyStream(res, data)
+		return res
+	}
+	if len(data) < 2*aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		t.Fatalf("decrypt: invalid length of AES ciphertext:%d", len(data))
+	}
+	block, _ := aes.NewCipher(key)
+	res := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(res, data[aes.BlockSize:])
+	return res[:len(res)-int(res[len(res)-1])]
+}
+
+func TestCryptFilter(t *testing.T) {
+	testCompillation(t, "/V 2 /Length 128", strings.Join(cryptFilterV2.entries("/StdCF"), " "))
+	testCompillation(t, "/V 4 /Length 128 /CF <</StdCF <</AuthEvent /DocOpen /CFM /AESV2 /Length 16>>>> /StmF /StdCF /StrF /StdCF",
+		strings.Join(cryptFilterAESV2.entries("/StdCF"), " "))
+	testCompillation(t, "/V 5 /Length 256 /CF <</DefaultCryptFilter <</AuthEvent /DocOpen /CFM /AESV3 /Length 32 /Recipients []>>>> /StmF /DefaultCryptFilter /StrF /DefaultCryptFilter",
+		strings.Join(cryptFilterAESV3.entries("/DefaultCryptFilter", "/Recipients []"), " "))
+}
+
+func TestObjectKey(t *testing.T) {
+	obj := &objectIdentifier{objectNumber: 5}
+	key := bytes.Repeat([]byte{1}, 16)
+	rc := newEncryption(key, cryptFilterV2, nil)
+	aesv2 := newEncryption(key, cryptFilterAESV2, nil)
+	if len(rc.objectKey(obj)) != 16 {
+		t.Errorf("objectKey: unexpected length:%d", len(rc.objectKey(obj)))
+	}
+	if bytes.Equal(rc.objectKey(obj), aesv2.objectKey(obj)) {
+		t.Error("objectKey: AES key must be salted")
+	}
+	if bytes.Equal(rc.objectKey(obj), rc.objectKey(&objectIdentifier{objectNumber: 6})) {
+		t.Error("objectKey: key must depend on the object number")
+	}
+	aesv3 := newEncryption(bytes.Repeat([]byte{1}, 32), cryptFilterAESV3, nil)
+	if !bytes.Equal(aesv3.key, aesv3.objectKey(obj)) || aesv3.extensionLevel != 8 {
+		t.Error("objectKey: AES-256 must use the file key")
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	obj := &objectIdentifier{objectNumber: 5}
+	data := []byte("0123456789abcdef")
+	for _, cf := range []cryptFilter{cryptFilterV2, cryptFilterAESV2, cryptFilterAESV3} {
+		e := newEncryption(bytes.Repeat([]byte{1}, cf.keyLength()), cf, bytes.NewReader(make([]byte, 16)))
+		enc, err := e.encrypt(obj, data)
+		if err != nil {
+			t.Fatalf("encrypt: unexpected error:%s", err)
+		}
+		if bytes.Contains(enc, data) {
+			t.Errorf("encrypt: data is not encrypted: %s", cf.compile())
+		}
+		if cf != cryptFilterV2 && len(enc) != 48 {
+			t.Errorf("encrypt: unexpected length: %s: %d", cf.compile(), len(enc))
+		}
+		if dec := decrypt(t, e, obj, enc); !bytes.Equal(data, dec) {
+			t.Errorf("encrypt: %s: expected:%s actual:%s", cf.compile(), data, dec)
+		}
+	}
+}
+
+func TestEncryptStrings(t *testing.T) {
+	obj := &objectIdentifier{objectNumber: 5}
+	e := newEncryption(bytes.Repeat([]byte{1}, 16), cryptFilterV2, nil)
+	src := "5 0 obj\n<</T (a\\(b\\)) /V <FEFF0041> /P 3 0 R /N [(x)]>>\nendobj\n"
+	actual, err := e.encryptStrings(obj, src)
+	if err != nil {
+		t.Fatalf("encryptStrings: unexpected error:%s", err)
+	}
+	enc := func(s string) string {
+		b, _ := e.encrypt(obj, []byte(s))
+		return hexString(b)
+	}
+	expected := "5 0 obj\n<</T " + enc("a(b)") + " /V " + enc("\xfe\xff\x00A") + " /P 3 0 R /N [" + enc("x") + "]>>\nendobj\n"
+	testCompillation(t, expected, actual)
+	if _, err := e.encryptStrings(obj, "<</T (a>>"); err == nil {
+		t.Error("encryptStrings: unterminated string must be error")
+	}
+}
+
+func TestParseLiteralString(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+		length   int
+	}{
+		{"(abc) /X", "abc", 5},
+		{"(a(b)c)", "a(b)c", 7},
+		{`(\(\)\\)`, `()\`, 8},
+		{`(\n\r\t\b\f)`, "\n\r\t\b\f", 12},
+		{`(\101\60\0050)`, "A0\x050", 14},
+		{"(a\\\nb)", "ab", 6},
+		{"(a\\\r\nb)", "ab", 7},
+	}
+	for _, test := range tests {
+		actual, n, err := parseLiteralString(test.src)
+		if err != nil {
+			t.Fatalf("parseLiteralString: unexpected error:%s", err)
+		}
+		testCompillation(t, test.expected, string(actual))
+		if n != test.length {
+			t.Errorf("parseLiteralString: %s: expected length:%d actual:%d", test.src, test.length, n)
+		}
+	}
+	if _, _, err := parseLiteralString("(a(b)"); err == nil {
+		t.Error("parseLiteralString: unterminated string must be error")
+	}
+}
+
+func TestParseHexString(t *testing.T) {
+	actual, n, err := parseHexString("<41 42\n4>>")
+	if err != nil {
+		t.Fatalf("parseHexString: unexpected error:%s", err)
+	}
+	testCompillation(t, "AB@", string(actual))
+	if n != 9 {
+		t.Errorf("parseHexString: unexpected length:%d", n)
+	}
+	if _, _, err := parseHexString("<4G>"); err == nil {
+		t.Error("parseHexString: invalid digit must be error")
+	}
+	if _, _, err := parseHexString("<41"); err == nil {
+		t.Error("parseHexString: unterminated string must be error")
+	}
+}
+
+func TestStreamCompileWith(t *testing.T) {
+	e := newEncryption(bytes.Repeat([]byte{1}, 16), cryptFilterV2, nil)
+	s := newFlatStream()
+	s.objectNumber = 4
+	s.dict["/Desc"] = "(desc)"
+	s.addStringDatum("content")
+	data, err := s.compileWith(e)
+	if err != nil {
+		t.Fatalf("compileWith: unexpected error:%s", err)
+	}
+	enc, _ := e.encrypt(s, []byte("content"))
+	desc, _ := e.encrypt(s, []byte("desc"))
+	expected := "4 0 obj\n<</Desc " + hexString(desc) + " /Length 7>>\nstream\n" + string(enc) + "\nendstream\nendobj\n"
+	testCompillation(t, expected, string(data))
+}
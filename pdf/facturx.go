@@ -0,0 +1,124 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+const (
+	xmpNamespaceFacturX        = "urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#"
+	xmpNamespacePDFAExtension  = "http://www.aiim.org/pdfa/ns/extension/"
+	xmpNamespacePDFASchema     = "http://www.aiim.org/pdfa/ns/schema#"
+	xmpNamespacePDFAProperty   = "http://www.aiim.org/pdfa/ns/property#"
+	facturXFileName            = "factur-x.xml"
+	facturXVersion             = "1.0"
+	facturXDocumentTypeInvoice = "INVOICE"
+)
+
+// FacturXProfile is a profile of Factur-X (ZUGFeRD 2), which determines the information contained in the invoice.
+type FacturXProfile int
+
+const (
+	facturXProfileUndefined FacturXProfile = iota
+	// FacturXProfileMinimum : MINIMUM, the minimum information for the booking of the invoice.
+	FacturXProfileMinimum
+	// FacturXProfileBasicWL : BASIC WL, the document level information without the invoice lines.
+	FacturXProfileBasicWL
+	// FacturXProfileBasic : BASIC, the subset of EN 16931 with the invoice lines.
+	FacturXProfileBasic
+	// FacturXProfileEN16931 : EN 16931, the core invoice model of the European standard.
+	FacturXProfileEN16931
+	// FacturXProfileExtended : EXTENDED, EN 16931 with the extensions for complex invoices.
+	FacturXProfileExtended
+)
+
+func (p FacturXProfile) String() string {
+	switch p {
+	case FacturXProfileMinimum:
+		return "MINIMUM"
+	case FacturXProfileBasicWL:
+		return "BASIC WL"
+	case FacturXProfileBasic:
+		return "BASIC"
+	case FacturXProfileEN16931:
+		return "EN 16931"
+	case FacturXProfileExtended:
+		return "EXTENDED"
+	default:
+		return ""
+	}
+}
+
+// relationship returns the relationship between the visual invoice and the XML.
+// The XML of MINIMUM and BASIC WL is not a complete invoice, so that it is only the data of the document.
+func (p FacturXProfile) relationship() AFRelationship {
+	switch p {
+	case FacturXProfileMinimum, FacturXProfileBasicWL:
+		return AFRelationshipData
+	default:
+		return AFRelationshipAlternative
+	}
+}
+
+// AttachFacturX makes the document a Factur-X (ZUGFeRD 2) hybrid invoice.
+// The CII XML of the invoice is attached as factur-x.xml, and the document conforms to PDF/A-3b.
+// The XML must conform to the profile, which is written in the XMP metadata.
+func (b *Builder) AttachFacturX(profile FacturXProfile, xml io.Reader) (EmbeddedFile, error) {
+	if profile == facturXProfileUndefined {
+		return nil, fmt.Errorf("Factur-X: profile is not specified")
+	}
+	if b.facturX != facturXProfileUndefined {
+		return nil, fmt.Errorf("Factur-X: invoice is already attached")
+	}
+	f, err := b.AttachFile(facturXFileName, "text/xml", xml)
+	if err != nil {
+		return nil, err
+	}
+	f.Description("Factur-X invoice").Relationship(profile.relationship())
+	b.facturX = profile
+	b.SetConformance(ConformancePDFA3B)
+	return f, nil
+}
+
+// buildFacturXMetadata adds the Factur-X properties and their PDF/A extension schema to the XMP metadata.
+func (b *Builder) buildFacturXMetadata(m *xmpMetadata) {
+	if b.facturX == facturXProfileUndefined {
+		return
+	}
+	fx := m.schema("fx", xmpNamespaceFacturX)
+	fx.set("DocumentType", facturXDocumentTypeInvoice)
+	fx.set("DocumentFileName", facturXFileName)
+	fx.set("Version", facturXVersion)
+	fx.set("ConformanceLevel", b.facturX.String())
+	ext := m.schema("pdfaExtension", xmpNamespacePDFAExtension)
+	ext.setRaw("schemas", xmpExtensionSchema("Factur-X PDFA Extension Schema", xmpNamespaceFacturX, "fx", [][2]string{
+		{"DocumentFileName", "name of the embedded XML invoice file"},
+		{"DocumentType", "INVOICE"},
+		{"Version", "The actual version of the Factur-X XML schema"},
+		{"ConformanceLevel", "The conformance level of the embedded Factur-X data"},
+	}))
+}
+
+// xmpExtensionSchema returns the bag of the PDF/A extension schema which describes the text properties.
+// PDF/A requires that the properties of the schemas which are not predefined are described in the metadata.
+func xmpExtensionSchema(name, namespace, prefix string, properties [][2]string) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<rdf:Bag xmlns:pdfaSchema=\"%s\" xmlns:pdfaProperty=\"%s\">", xmpNamespacePDFASchema, xmpNamespacePDFAProperty)
+	b.WriteString("<rdf:li rdf:parseType=\"Resource\">")
+	fmt.Fprintf(&b, "<pdfaSchema:schema>%s</pdfaSchema:schema>", xmlEscape(name))
+	fmt.Fprintf(&b, "<pdfaSchema:namespaceURI>%s</pdfaSchema:namespaceURI>", xmlEscape(namespace))
+	fmt.Fprintf(&b, "<pdfaSchema:prefix>%s</pdfaSchema:prefix>", xmlEscape(prefix))
+	b.WriteString("<pdfaSchema:property><rdf:Seq>")
+	for _, p := range properties {
+		b.WriteString("<rdf:li rdf:parseType=\"Resource\">")
+		fmt.Fprintf(&b, "<pdfaProperty:name>%s</pdfaProperty:name>", xmlEscape(p[0]))
+		b.WriteString("<pdfaProperty:valueType>Text</pdfaProperty:valueType>")
+		b.WriteString("<pdfaProperty:category>external</pdfaProperty:category>")
+		fmt.Fprintf(&b, "<pdfaProperty:description>%s</pdfaProperty:description>", xmlEscape(p[1]))
+		b.WriteString("</rdf:li>")
+	}
+	b.WriteString("</rdf:Seq></pdfaSchema:property>")
+	b.WriteString("</rdf:li></rdf:Bag>")
+	return b.String()
+}
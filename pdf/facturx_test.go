@@ -0,0 +1,82 @@
+package pdf
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const testFacturXInvoice = `<?xml version="1.0" encoding="UTF-8"?>
+<rsm:CrossIndustryInvoice xmlns:rsm="urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100"></rsm:CrossIndustryInvoice>`
+
+func TestBuilderAttachFacturX(t *testing.T) {
+	out, err := buildConformance(conformanceUndefined, func(b *Builder) {
+		b.AddPage()
+		if _, err := b.AttachFacturX(FacturXProfileEN16931, strings.NewReader(testFacturXInvoice)); err != nil {
+			t.Fatalf("AttachFacturX: unexpected error:%s", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	for _, entry := range []string{
+		"/F (factur-x.xml) /UF (factur-x.xml) /Desc (Factur-X invoice)",
+		"/AFRelationship /Alternative",
+		"/Subtype /text#2Fxml",
+		"/AF [",
+		"<pdfaid:part>3</pdfaid:part>",
+		"<fx:DocumentType>INVOICE</fx:DocumentType>",
+		"<fx:DocumentFileName>factur-x.xml</fx:DocumentFileName>",
+		"<fx:Version>1.0</fx:Version>",
+		"<fx:ConformanceLevel>EN 16931</fx:ConformanceLevel>",
+		"<pdfaSchema:namespaceURI>urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#</pdfaSchema:namespaceURI>",
+		"<pdfaSchema:prefix>fx</pdfaSchema:prefix>",
+	} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+	start := strings.Index(out, "<?xpacket begin=")
+	end := strings.Index(out, "<?xpacket end=\"w\"?>")
+	d := xml.NewDecoder(strings.NewReader(out[start:end]))
+	for {
+		if _, err := d.Token(); err != nil {
+			if err.Error() != "EOF" {
+				t.Errorf("Build: metadata is not well-formed:%s", err)
+			}
+			break
+		}
+	}
+}
+
+func TestFacturXProfileRelationship(t *testing.T) {
+	for p, expected := range map[FacturXProfile]AFRelationship{
+		FacturXProfileMinimum:  AFRelationshipData,
+		FacturXProfileBasicWL:  AFRelationshipData,
+		FacturXProfileBasic:    AFRelationshipAlternative,
+		FacturXProfileExtended: AFRelationshipAlternative,
+	} {
+		if actual := p.relationship(); actual != expected {
+			t.Errorf("relationship: %s: expected:%s, actual:%s", p, expected.compile(), actual.compile())
+		}
+	}
+}
+
+func TestBuilderAttachFacturXError(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	if _, err := b.AttachFacturX(facturXProfileUndefined, strings.NewReader(testFacturXInvoice)); err == nil {
+		t.Error("AttachFacturX: undefined profile must be error")
+	}
+	if _, err := b.AttachFacturX(FacturXProfileBasic, strings.NewReader(testFacturXInvoice)); err != nil {
+		t.Fatalf("AttachFacturX: unexpected error:%s", err)
+	}
+	if _, err := b.AttachFacturX(FacturXProfileBasic, strings.NewReader(testFacturXInvoice)); err == nil {
+		t.Error("AttachFacturX: second invoice must be error")
+	}
+	_, err := buildConformance(ConformancePDFA2B, func(bb *Builder) {
+		bb.facturX = FacturXProfileBasic
+	})
+	if err == nil || err.Error() != "Factur-X: PDF/A-3b is required" {
+		t.Errorf("Build: unexpected error:%v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// iccTag is a tag of an ICC profile.
+type iccTag struct {
+	signature string
+	data      []byte
+}
+
+// srgbProfile returns the ICC profile (version 2.1) of the sRGB IEC61966-2.1 color space.
+// The profile is generated from the definition of sRGB, so that it is identical for every document.
+func srgbProfile() []byte {
+	trc := iccCurve(1024, srgbDecode)
+	// The colorants are adapted to the D50 illuminant of the profile connection space by the Bradford transform.
+	tags := []iccTag{
+		{"desc", iccTextDescription("sRGB IEC61966-2.1")},
+		{"cprt", iccText("No copyright, use freely")},
+		{"wtpt", iccXYZ(0.9505, 1.0, 1.0891)},
+		{"rXYZ", iccXYZ(srgbColorants[0][0], srgbColorants[1][0], srgbColorants[2][0])},
+		{"gXYZ", iccXYZ(srgbColorants[0][1], srgbColorants[1][1], srgbColorants[2][1])},
+		{"bXYZ", iccXYZ(srgbColorants[0][2], srgbColorants[1][2], srgbColorants[2][2])},
+		{"rTRC", trc},
+		{"gTRC", trc},
+		{"bTRC", trc},
+	}
+	return iccProfile("mntr", "RGB ", "XYZ ", tags)
+}
+
+// srgbColorants is the matrix which converts linear sRGB to XYZ adapted to D50.
+var srgbColorants = [3][3]float64{
+	{0.4361, 0.3851, 0.1431},
+	{0.2225, 0.7169, 0.0606},
+	{0.0139, 0.0971, 0.7141},
+}
+
+// iccD50 is the illuminant of the profile connection space.
+var iccD50 = [3]float64{0.9642, 1.0, 0.8249}
+
+// srgbDecode returns the linear value of the sRGB component.
+func srgbDecode(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// iccProfileClass returns the device class and the color space of the ICC profile,
+// or false if the data is not an ICC profile.
+func iccProfileClass(profile []byte) (class, colorSpace string, ok bool) {
+	if len(profile) < 128 || string(profile[36:40]) != "acsp" {
+		return "", "", false
+	}
+	return string(profile[12:16]), string(profile[16:20]), true
+}
+
+// iccProfile returns the ICC profile of the device class, the color space and the profile connection space with the tags.
+// Tags which have the same data share it.
+func iccProfile(class, colorSpace, pcs string, tags []iccTag) []byte {
+	var data bytes.Buffer
+	offset := 128 + 4 + 12*len(tags)
+	table := new(bytes.Buffer)
+	binary.Write(table, binary.BigEndian, uint32(len(tags)))
+	offsets := make(map[string]int)
+	for _, t := range tags {
+		o, ok := offsets[string(t.data)]
+		if !ok {
+			o = offset + data.Len()
+			offsets[string(t.data)] = o
+			data.Write(t.data)
+			// Tag data is aligned on 4 bytes.
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+		}
+		table.WriteString(t.signature)
+		binary.Write(table, binary.BigEndian, uint32(o))
+		binary.Write(table, binary.BigEndian, uint32(len(t.data)))
+	}
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header[0:], uint32(offset+data.Len()))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], class)
+	copy(header[16:], colorSpace)
+	copy(header[20:], pcs)
+	// The date of creation is fixed.
+	for i, v := range []uint16{2000, 1, 1, 0, 0, 0} {
+		binary.BigEndian.PutUint16(header[24+2*i:], v)
+	}
+	copy(header[36:], "acsp")
+	// The illuminant of the profile connection space is D50.
+	copy(header[68:], iccXYZ(iccD50[0], iccD50[1], iccD50[2])[8:])
+	return bytes.Join([][]byte{header, table.Bytes(), data.Bytes()}, nil)
+}
+
+// iccS15Fixed16 returns the fixed-point number whose 16 bits are the fraction.
+func iccS15Fixed16(v float64) uint32 {
+	return uint32(int32(math.Round(v * 65536)))
+}
+
+// iccXYZ returns the XYZType data.
+func iccXYZ(x, y, z float64) []byte {
+	b := bytes.NewBufferString("XYZ \x00\x00\x00\x00")
+	for _, v := range []float64{x, y, z} {
+		binary.Write(b, binary.BigEndian, iccS15Fixed16(v))
+	}
+	return b.Bytes()
+}
+
+// iccText returns the textType data.
+func iccText(text string) []byte {
+	return []byte("text\x00\x00\x00\x00" + text + "\x00")
+}
+
+// iccTextDescription returns the textDescriptionType data which has only the ASCII description.
+func iccTextDescription(text string) []byte {
+	b := bytes.NewBufferString("desc\x00\x00\x00\x00")
+	binary.Write(b, binary.BigEndian, uint32(len(text)+1))
+	b.WriteString(text + "\x00")
+	// The empty Unicode and ScriptCode descriptions.
+	b.Write(make([]byte, 4+4+2+1+67))
+	return b.Bytes()
+}
+
+// iccCurve returns the curveType data which samples the function on [0, 1].
+func iccCurve(n int, f func(v float64) float64) []byte {
+	b := bytes.NewBufferString("curv\x00\x00\x00\x00")
+	binary.Write(b, binary.BigEndian, uint32(n))
+	for i := 0; i < n; i++ {
+		binary.Write(b, binary.BigEndian, uint16(math.Round(f(float64(i)/float64(n-1))*65535)))
+	}
+	return b.Bytes()
+}
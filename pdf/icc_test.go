@@ -0,0 +1,66 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestSRGBProfile(t *testing.T) {
+	p := srgbProfile()
+	if size := binary.BigEndian.Uint32(p[0:]); int(size) != len(p) {
+		t.Errorf("srgbProfile: unexpected size:%d, actual:%d", size, len(p))
+	}
+	if version := binary.BigEndian.Uint32(p[8:]); version != 0x02100000 {
+		t.Errorf("srgbProfile: unexpected version:%X", version)
+	}
+	for offset, expected := range map[int]string{12: "mntr", 16: "RGB ", 20: "XYZ ", 36: "acsp"} {
+		if actual := string(p[offset : offset+4]); actual != expected {
+			t.Errorf("srgbProfile: expected:%s, actual:%s", expected, actual)
+		}
+	}
+	count := int(binary.BigEndian.Uint32(p[128:]))
+	if count != 9 {
+		t.Fatalf("srgbProfile: unexpected tag count:%d", count)
+	}
+	offsets := make(map[string]uint32)
+	for i := 0; i < count; i++ {
+		entry := p[132+12*i:]
+		signature := string(entry[0:4])
+		offset := binary.BigEndian.Uint32(entry[4:])
+		size := binary.BigEndian.Uint32(entry[8:])
+		if offset%4 != 0 || int(offset+size) > len(p) {
+			t.Errorf("srgbProfile: invalid tag %s: offset:%d, size:%d", signature, offset, size)
+		}
+		offsets[signature] = offset
+	}
+	if offsets["rTRC"] != offsets["gTRC"] || offsets["rTRC"] != offsets["bTRC"] {
+		t.Error("srgbProfile: curves must be shared")
+	}
+	if string(p[offsets["wtpt"]:offsets["wtpt"]+4]) != "XYZ " || string(p[offsets["rTRC"]:offsets["rTRC"]+4]) != "curv" {
+		t.Error("srgbProfile: unexpected tag type")
+	}
+}
+
+func TestICCCurve(t *testing.T) {
+	c := iccCurve(3, func(v float64) float64 {
+		return v
+	})
+	expected := "curv\x00\x00\x00\x00\x00\x00\x00\x03\x00\x00\x80\x00\xFF\xFF"
+	if string(c) != expected {
+		t.Errorf("iccCurve: expected:%q, actual:%q", expected, c)
+	}
+}
+
+// newTestCMYKProfile returns the header and the description of a CMYK output profile.
+func newTestCMYKProfile() []byte {
+	return iccProfile("prtr", "CMYK", "Lab ", []iccTag{{"desc", iccTextDescription("Test CMYK")}})
+}
+
+func TestICCProfileClass(t *testing.T) {
+	if class, cs, ok := iccProfileClass(newTestCMYKProfile()); !ok || class != "prtr" || cs != "CMYK" {
+		t.Errorf("iccProfileClass: unexpected class:%s, color space:%s", class, cs)
+	}
+	if _, _, ok := iccProfileClass([]byte("not a profile")); ok {
+		t.Error("iccProfileClass: data which is not a profile must not be ok")
+	}
+}
@@ -50,6 +50,10 @@ type Image interface {
 	Width(pt float64) Image
 	Height(pt float64) Image
 	Rotate(radian float64) Image
+	// Alt specifies the alternate description of the image.
+	// If the image is rendered in a figure structure element without the description, it is given to the element.
+	// In a tagged document, the image which has the description must be rendered in a figure structure element.
+	Alt(text string) Image
 	GraphicsObject
 }
 
@@ -61,6 +65,7 @@ type image struct {
 	width   float64
 	height  float64
 	rotate  float64
+	alt     string
 }
 
 // newImage returns the new image.
@@ -96,6 +101,13 @@ func (i *image) Rotate(rotate float64) Image {
 	return i
 }
 
+func (i *image) Alt(text string) Image {
+	i.ifNotRendered(func() {
+		i.alt = text
+	})
+	return i
+}
+
 func (i *image) Render() {
 	i.renderSelf(i)
 }
@@ -13,6 +13,7 @@ type mockImage struct {
 func (i *mockImage) Width(width float64) Image   { return i }
 func (i *mockImage) Height(height float64) Image { return i }
 func (i *mockImage) Rotate(roate float64) Image  { return i }
+func (i *mockImage) Alt(text string) Image       { return i }
 
 func TestImage1(t *testing.T) {
 	p := &mockPage{}
@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DocumentInfo is a metadata for the document.
+// Empty strings and zero dates are not written.
+type DocumentInfo struct {
+	// Title is the document’s title.
+	Title string
+	// Author is the name of the person who created the document.
+	Author string
+	// Subject is the subject of the document.
+	Subject string
+	// Keywords associated with the document.
+	Keywords string
+	// Creator is the name of the product that created the original document.
+	Creator string
+	// Producer is the name of the product that converted the original document to pdf.
+	Producer string
+	// CreationDate is the date and time the document was created.
+	// If it is zero, the time when the document is built is used.
+	CreationDate time.Time
+	// ModDate is the date and time the document was most recently modified.
+	// If it is zero, the time when the document is built is used.
+	ModDate time.Time
+}
+
+// documentInfo is a document information dictionary.
+// It is referred from the trailer.
+type documentInfo struct {
+	objectIdentifier
+	info DocumentInfo
+	// trapped is the name which indicates whether the document has been trapped, or empty.
+	trapped string
+	// pdfxVersion is the version of PDF/X to which the document conforms, or empty.
+	pdfxVersion string
+}
+
+func newDocumentInfo(info DocumentInfo) *documentInfo {
+	return &documentInfo{
+		objectIdentifier: objectIdentifier{},
+		info:             info,
+	}
+}
+
+// fillDates sets now to the dates which have not been specified.
+func (di *documentInfo) fillDates(now time.Time) {
+	if di.info.CreationDate.IsZero() {
+		di.info.CreationDate = now
+	}
+	if di.info.ModDate.IsZero() {
+		di.info.ModDate = now
+	}
+}
+
+func (di *documentInfo) compile() string {
+	dict := make([]string, 0, 10)
+	texts := []struct {
+		key   string
+		value string
+	}{
+		{"/Title", di.info.Title},
+		{"/Author", di.info.Author},
+		{"/Subject", di.info.Subject},
+		{"/Keywords", di.info.Keywords},
+		{"/Creator", di.info.Creator},
+		{"/Producer", di.info.Producer},
+	}
+	for _, t := range texts {
+		if t.value != "" {
+			dict = append(dict, fmt.Sprintf("%s %s", t.key, textString(t.value)))
+		}
+	}
+	if !di.info.CreationDate.IsZero() {
+		dict = append(dict, fmt.Sprintf("/CreationDate %s", dateString(di.info.CreationDate)))
+	}
+	if !di.info.ModDate.IsZero() {
+		dict = append(dict, fmt.Sprintf("/ModDate %s", dateString(di.info.ModDate)))
+	}
+	if di.trapped != "" {
+		dict = append(dict, fmt.Sprintf("/Trapped %s", di.trapped))
+	}
+	if di.pdfxVersion != "" {
+		dict = append(dict, fmt.Sprintf("/GTS_PDFXVersion %s", textString(di.pdfxVersion)))
+	}
+	return di.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
+}
+
+func (di *documentInfo) walk(walker func(obj pdfObject)) {
+	if di != nil {
+		walker(di)
+	}
+}
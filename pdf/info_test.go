@@ -0,0 +1,36 @@
+package pdf
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDocumentInfo(t *testing.T) {
+	di := newDocumentInfo(DocumentInfo{
+		Title:  "title",
+		Author: "著者",
+	})
+	if di.refNo() != 0 {
+		t.Error("documentInfo is not initial state: refNo != 0")
+	}
+	testCompillation(t, "0 0 obj\n<</Title (title) /Author <FEFF84578005>>>\nendobj\n", di.compile())
+	created := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	di.info.CreationDate = created
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	di.fillDates(now)
+	if !di.info.CreationDate.Equal(created) {
+		t.Errorf("fillDates: CreationDate is overwritten: %s", di.info.CreationDate)
+	}
+	if !di.info.ModDate.Equal(now) {
+		t.Errorf("fillDates: ModDate: expected:%s actual:%s", now, di.info.ModDate)
+	}
+	expected := "0 0 obj\n<</Title (title) /Author <FEFF84578005> /CreationDate (D:20180102030405Z) /ModDate (D:20190102030405Z)>>\nendobj\n"
+	testCompillation(t, expected, di.compile())
+}
+
+func TestDocumentInfoPDFX(t *testing.T) {
+	di := newDocumentInfo(DocumentInfo{Title: "title"})
+	di.trapped = "/False"
+	di.pdfxVersion = "PDF/X-4"
+	testCompillation(t, "0 0 obj\n<</Title (title) /Trapped /False /GTS_PDFXVersion (PDF/X-4)>>\nendobj\n", di.compile())
+}
@@ -0,0 +1,77 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Layer is an optional content group, whose contents can be shown or hidden by the viewer.
+// Contents of pages are put in the layer by Page.BeginLayer.
+type Layer interface {
+	// Locked prevents the visibility of the layer from being changed by the user.
+	Locked(locked bool) Layer
+	// group returns the implementation, so that the interface is not implemented outside the package.
+	group() *optionalContentGroup
+}
+
+// optionalContentGroup is a implementation of a Layer interface.
+type optionalContentGroup struct {
+	objectIdentifier
+	name string
+	// resourceName is the name of the group in the properties of resources.
+	resourceName string
+	visible      bool
+	locked       bool
+}
+
+func newOptionalContentGroup(name, resourceName string, visible bool) *optionalContentGroup {
+	return &optionalContentGroup{
+		objectIdentifier: objectIdentifier{},
+		name:             name,
+		resourceName:     resourceName,
+		visible:          visible,
+	}
+}
+
+func (ocg *optionalContentGroup) Locked(locked bool) Layer {
+	ocg.locked = locked
+	return ocg
+}
+
+func (ocg *optionalContentGroup) group() *optionalContentGroup {
+	return ocg
+}
+
+func (ocg *optionalContentGroup) compile() string {
+	return ocg.bracket(fmt.Sprintf("<</Type /OCG /Name %s>>", textString(ocg.name)))
+}
+
+func (ocg *optionalContentGroup) walk(walker func(obj pdfObject)) {
+	walker(ocg)
+}
+
+// compileOCProperties returns the optional content properties dictionary of the layers.
+// The default configuration shows the layers in the order in which they were declared.
+func compileOCProperties(layers []*optionalContentGroup) string {
+	all := make([]string, 0, len(layers))
+	off := make([]string, 0)
+	locked := make([]string, 0)
+	for _, l := range layers {
+		ref := l.indirectReference()
+		all = append(all, ref)
+		if !l.visible {
+			off = append(off, ref)
+		}
+		if l.locked {
+			locked = append(locked, ref)
+		}
+	}
+	config := []string{"/Name (Default)", fmt.Sprintf("/Order [%s]", strings.Join(all, " "))}
+	if len(off) > 0 {
+		config = append(config, fmt.Sprintf("/OFF [%s]", strings.Join(off, " ")))
+	}
+	if len(locked) > 0 {
+		config = append(config, fmt.Sprintf("/Locked [%s]", strings.Join(locked, " ")))
+	}
+	return fmt.Sprintf("<</OCGs [%s] /D <<%s>>>>", strings.Join(all, " "), strings.Join(config, " "))
+}
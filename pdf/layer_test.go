@@ -0,0 +1,95 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOptionalContentGroup(t *testing.T) {
+	ocg := newOptionalContentGroup("Dimensions", "/OC0", true)
+	ocg.objectNumber = 4
+	testCompillation(t, "4 0 obj\n<</Type /OCG /Name (Dimensions)>>\nendobj\n", ocg.compile())
+}
+
+func TestCompileOCProperties(t *testing.T) {
+	dimensions := newOptionalContentGroup("Dimensions", "/OC0", true)
+	dimensions.objectNumber = 4
+	grid := newOptionalContentGroup("Grid", "/OC1", false)
+	grid.objectNumber = 5
+	grid.Locked(true)
+	expected := "<</OCGs [4 0 R 5 0 R] /D <</Name (Default) /Order [4 0 R 5 0 R] /OFF [5 0 R] /Locked [5 0 R]>>>>"
+	testCompillation(t, expected, compileOCProperties([]*optionalContentGroup{dimensions, grid}))
+	expected = "<</OCGs [4 0 R] /D <</Name (Default) /Order [4 0 R]>>>>"
+	testCompillation(t, expected, compileOCProperties([]*optionalContentGroup{dimensions}))
+}
+
+func TestPageBeginLayer(t *testing.T) {
+	p := newTestPage()
+	ocg := newOptionalContentGroup("Grid", "/OC0", true)
+	p.BeginLayer(ocg)
+	p.Line(0, 0, 10, 0, 1).Render()
+	p.EndMarkedContent()
+	testRendering(t, "/OC /OC0 BDC\nq 10 180 m 20 180 l 1 w  S Q\nEMC\n", string(bytes.Join(p.contents.data, nil)))
+	if p.resource != nil {
+		t.Error("BeginLayer: default resource must be used")
+	}
+	p.AddFont(newFontType1("/F0", "/Helvetica"))
+	p.BeginLayer(ocg)
+	p.EndMarkedContent()
+	if p.resource.properties["/OC0"] != ocg {
+		t.Error("BeginLayer: layer is not added to the resource of the page")
+	}
+}
+
+func TestPageBeginLayerNil(t *testing.T) {
+	var ocg *optionalContentGroup
+	for _, l := range []Layer{nil, ocg} {
+		p := newTestPage()
+		p.BeginLayer(l)
+		p.EndMarkedContent()
+		if err := validatePage(p, 2); err == nil || err.Error() != "layer: layer is not specified on the page 2" {
+			t.Errorf("validatePage: unexpected error:%v", err)
+		}
+	}
+}
+
+func TestBuilderLayerNotEnded(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	grid := b.AddLayer("Grid", true)
+	b.AddPage()
+	p := b.AddPage()
+	p.BeginLayer(grid)
+	p.Line(0, 0, 100, 0, 1).Render()
+	if err := b.Build(&bytes.Buffer{}); err == nil || err.Error() != "page: marked content is not ended on the page 2" {
+		t.Errorf("Build: unexpected error:%v", err)
+	}
+}
+
+func TestBuilderLayer(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	dimensions := b.AddLayer("Dimensions", true)
+	grid := b.AddLayer("Grid", false).Locked(true)
+	p := b.AddPage()
+	p.BeginLayer(grid)
+	p.Line(0, 0, 100, 0, 1).Render()
+	p.EndMarkedContent()
+	p.BeginLayer(dimensions)
+	p.Rectangle(0, 0, 10, 10).Render()
+	p.EndMarkedContent()
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.String()
+	g, d := grid.(*optionalContentGroup).indirectReference(), dimensions.(*optionalContentGroup).indirectReference()
+	for _, entry := range []string{
+		"/OCProperties <</OCGs [" + d + " " + g + "] /D <</Name (Default) /Order [" + d + " " + g + "] /OFF [" + g + "] /Locked [" + g + "]>>>>",
+		"/Properties <</OC0 " + d + " /OC1 " + g + ">>",
+		"<</Type /OCG /Name (Grid)>>",
+	} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+}
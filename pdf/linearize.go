@@ -0,0 +1,456 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+)
+
+// maxLayoutPasses is the maximum number of passes to lay out a linearized document.
+// The sizes of the linearization dictionary, the first-page cross-reference section and the hint stream
+// depend on the offsets, which depend on the sizes in turn.
+const maxLayoutPasses = 10
+
+// WithLinearization linearizes the document, so that viewers can display the first page before the whole file is read.
+// Linearized documents are not available with object streams.
+// An incremental update such as a document timestamp is not linearized,
+// and viewers read the updated document as a whole.
+func WithLinearization() BuildOption {
+	return func(o *buildOptions) {
+		o.linearized = true
+	}
+}
+
+// linearizationDict is the linearization parameter dictionary.
+type linearizationDict struct {
+	objectIdentifier
+	// length is the length of the file.
+	length int
+	// hintOffset and hintLength locate the primary hint stream.
+	hintOffset int
+	hintLength int
+	// firstPage is the object number of the first page.
+	firstPage int
+	// endOfFirstPage is the offset of the end of the first page section.
+	endOfFirstPage int
+	pages          int
+	// mainXRef is the offset of the white-space preceding the first entry of the main cross-reference table.
+	mainXRef int
+}
+
+func (ld *linearizationDict) compile() string {
+	return ld.bracket(fmt.Sprintf("<</Linearized 1 /L %d /H [%d %d] /O %d /E %d /N %d /T %d>>",
+		ld.length, ld.hintOffset, ld.hintLength, ld.firstPage, ld.endOfFirstPage, ld.pages, ld.mainXRef))
+}
+
+// linearization is the layout of a linearized document.
+// The objects are written in the order of the fields.
+type linearization struct {
+	dict *linearizationDict
+	// catalog are the document catalog and the objects required to open the document.
+	catalog []pdfObject
+	hint    *stream
+	// first are the objects of the first page, which include the objects shared with the other pages.
+	first []pdfObject
+	// pages are the objects private to the other pages. The page object is the first of them.
+	pages [][]pdfObject
+	// shared are the objects shared by the pages other than the first page.
+	shared []pdfObject
+	others []pdfObject
+	// contents are the content streams of the pages.
+	contents []pdfObject
+	// sharedRefs are the identifiers in the shared object hint table of the objects referred by the other pages.
+	sharedRefs [][]int
+	// mainSize is the size of the main cross-reference table, whose objects are numbered before the first page section.
+	mainSize int
+}
+
+// firstPageSection returns the objects of the first page section, which are numbered after the other objects.
+func (l *linearization) firstPageSection() []pdfObject {
+	objs := []pdfObject{l.dict}
+	objs = append(objs, l.catalog...)
+	objs = append(objs, l.first...)
+	return append(objs, l.hint)
+}
+
+// mainSection returns the objects of the main cross-reference table.
+func (l *linearization) mainSection() []pdfObject {
+	objs := make([]pdfObject, 0)
+	for _, p := range l.pages {
+		objs = append(objs, p...)
+	}
+	objs = append(objs, l.shared...)
+	return append(objs, l.others...)
+}
+
+// linearize lays out the objects of the built document, and renumbers them in the linearized order.
+func (b *Builder) linearize() error {
+	pages := b.dc.pages.allPages()
+	if len(pages) == 0 {
+		return errors.New("linearization: document has no pages")
+	}
+	for _, p := range pages {
+		if pg, ok := p.(*page); ok {
+			pg.inherit()
+		}
+	}
+	l := &linearization{
+		dict:       &linearizationDict{pages: len(pages)},
+		catalog:    []pdfObject{b.dc},
+		hint:       newDeflatedStream(),
+		pages:      make([][]pdfObject, len(pages)-1),
+		contents:   make([]pdfObject, len(pages)),
+		sharedRefs: make([][]int, len(pages)-1),
+	}
+	if b.encryption != nil {
+		l.catalog = append(l.catalog, b.encryption)
+	}
+	assigned := make(map[pdfObject]bool)
+	for _, obj := range l.catalog {
+		assigned[obj] = true
+	}
+	// reach lists the objects referred from each page without duplicates.
+	reach := make([][]pdfObject, len(pages))
+	owners := make(map[pdfObject]int)
+	for i, p := range pages {
+		visited := make(map[pdfObject]bool)
+		p.walk(func(obj pdfObject) {
+			if visited[obj] || assigned[obj] {
+				return
+			}
+			visited[obj] = true
+			reach[i] = append(reach[i], obj)
+			if owner, ok := owners[obj]; !ok {
+				owners[obj] = i
+			} else if owner != i {
+				owners[obj] = -1
+			}
+		})
+		if pg, ok := p.(*page); ok {
+			l.contents[i] = pg.contents
+		}
+	}
+	identifiers := make(map[pdfObject]int)
+	for _, obj := range reach[0] {
+		identifiers[obj] = len(l.first)
+		assigned[obj] = true
+		l.first = append(l.first, obj)
+	}
+	shared := make([]pdfObject, 0)
+	for i := 1; i < len(pages); i++ {
+		for _, obj := range reach[i] {
+			if owners[obj] == i {
+				l.pages[i-1] = append(l.pages[i-1], obj)
+				assigned[obj] = true
+				continue
+			}
+			if _, ok := identifiers[obj]; !ok {
+				shared = append(shared, obj)
+				identifiers[obj] = -1
+			}
+		}
+	}
+	for _, obj := range shared {
+		identifiers[obj] = len(l.first) + len(l.shared)
+		assigned[obj] = true
+		l.shared = append(l.shared, obj)
+	}
+	for i := 1; i < len(pages); i++ {
+		for _, obj := range reach[i] {
+			if owners[obj] != i {
+				l.sharedRefs[i-1] = append(l.sharedRefs[i-1], identifiers[obj])
+			}
+		}
+	}
+	collect := func(obj pdfObject) {
+		if !assigned[obj] {
+			assigned[obj] = true
+			l.others = append(l.others, obj)
+		}
+	}
+	b.dc.walk(collect)
+	b.info.walk(collect)
+	// The objects of the first page section are numbered after the other objects,
+	// so that the main cross-reference table begins with the object number 0.
+	n := 0
+	for _, obj := range l.mainSection() {
+		n++
+		obj.renumber(n)
+	}
+	l.mainSize = n + 1
+	for _, obj := range l.firstPageSection() {
+		n++
+		obj.renumber(n)
+	}
+	b.c.count = n
+	b.linearization = l
+	return nil
+}
+
+// writeLinearized writes the linearized document.
+func (b *Builder) writeLinearized(w io.Writer) error {
+	l := b.linearization
+	pw := newWriter(w).encrypt(b.encryption).start(b.headerVersion())
+	compiled := make(map[pdfObject][]byte)
+	digest := md5.New()
+	for _, obj := range append(l.firstPageSection(), l.mainSection()...) {
+		if obj == l.dict || obj == l.hint {
+			continue
+		}
+		data, err := pw.compile(obj)
+		if err != nil {
+			return err
+		}
+		compiled[obj] = data
+		digest.Write(data)
+	}
+	t := newTrailer(b.dc.objectIdentifier, l.dict.refNo()+len(l.firstPageSection()), 0)
+	if b.info != nil {
+		t.info = b.info
+	}
+	if b.encryption != nil {
+		t.encrypt = b.encryption
+	}
+	id := b.id
+	if id == nil {
+		id = b.fileID(digest.Sum(nil))
+	}
+	t.id = [][]byte{id, id}
+	// The sizes are determined when the offsets computed from them do not change the sizes.
+	var firstXRef, mainXRef int
+	var first, main []byte
+	offsets := make(map[pdfObject]int)
+	for pass := 0; ; pass++ {
+		if pass == maxLayoutPasses {
+			return errors.New("linearization: layout does not converge")
+		}
+		sizes := []int{len(compiled[l.dict]), len(first), len(compiled[l.hint])}
+		offset := pw.offset
+		place := func(objs ...pdfObject) {
+			for _, obj := range objs {
+				offsets[obj] = offset
+				offset += len(compiled[obj])
+			}
+		}
+		place(l.dict)
+		firstXRef = offset
+		offset += len(first)
+		place(l.catalog...)
+		place(l.hint)
+		place(l.first...)
+		l.dict.endOfFirstPage = offset
+		place(l.mainSection()...)
+		mainXRef = offset
+		main = l.compileMainXRef(offsets, firstXRef)
+		l.dict.length = offset + len(main)
+		l.dict.hintOffset = offsets[l.hint]
+		l.dict.hintLength = len(compiled[l.hint])
+		l.dict.firstPage = l.first[0].refNo()
+		l.dict.mainXRef = mainXRef + len(fmt.Sprintf("xref\n0 %d", l.mainSize))
+		t.prev = mainXRef
+		first = l.compileFirstXRef(offsets, t)
+		l.buildHint(offsets, compiled)
+		data, err := pw.compile(l.hint)
+		if err != nil {
+			return err
+		}
+		compiled[l.hint] = data
+		compiled[l.dict] = []byte(l.dict.compile())
+		if sizes[0] == len(compiled[l.dict]) && sizes[1] == len(first) && sizes[2] == len(compiled[l.hint]) {
+			break
+		}
+	}
+	pw.write(compiled[l.dict])
+	pw.write(first)
+	for _, objs := range [][]pdfObject{l.catalog, {l.hint}, l.first, l.mainSection()} {
+		for _, obj := range objs {
+			pw.write(compiled[obj])
+		}
+	}
+	pw.write(main)
+	// An incremental update refers to the first-page cross-reference section, which the last startxref locates.
+	t.startXRef = firstXRef
+	b.trailer = t
+	b.revision = pw.digests
+	return pw.err
+}
+
+// compileFirstXRef returns the cross-reference section of the first page section and the trailer of the document.
+// The trailer refers to the main cross-reference section, and startxref of the section is ignored.
+func (l *linearization) compileFirstXRef(offsets map[pdfObject]int, t *trailer) []byte {
+	crt := newCrossRefTable()
+	delete(crt.entries, 0)
+	for _, obj := range l.firstPageSection() {
+		crt.addNewEntry(obj, offsets[obj])
+	}
+	return []byte(fmt.Sprintf("%s%s\n", crt.compile(), t.compile()))
+}
+
+// compileMainXRef returns the main cross-reference section, whose startxref locates the first-page cross-reference section.
+func (l *linearization) compileMainXRef(offsets map[pdfObject]int, firstXRef int) []byte {
+	crt := newCrossRefTable()
+	for _, obj := range l.mainSection() {
+		crt.addNewEntry(obj, offsets[obj])
+	}
+	return []byte(fmt.Sprintf("%strailer\n<</Size %d>>\nstartxref\n%d\n%%%%EOF", crt.compile(), l.mainSize, firstXRef))
+}
+
+// buildHint builds the primary hint stream, which consists of the page offset hint table and the shared object hint table.
+// The offsets in the tables are computed as if the hint stream were not present.
+func (l *linearization) buildHint(offsets map[pdfObject]int, compiled map[pdfObject][]byte) {
+	hintOffset, hintLength := offsets[l.hint], len(compiled[l.hint])
+	adjust := func(offset int) int {
+		if offset > hintOffset {
+			return offset - hintLength
+		}
+		return offset
+	}
+	size := func(objs []pdfObject) (n int) {
+		for _, obj := range objs {
+			n += len(compiled[obj])
+		}
+		return
+	}
+	pages := append([][]pdfObject{l.first}, l.pages...)
+	sharedRefs := append([][]int{nil}, l.sharedRefs...)
+	objects := make([]int, len(pages))
+	lengths := make([]int, len(pages))
+	contentOffsets := make([]int, len(pages))
+	contentLengths := make([]int, len(pages))
+	shares := make([]int, len(pages))
+	maxShares, maxIdentifier := 0, 0
+	for i, objs := range pages {
+		objects[i] = len(objs)
+		lengths[i] = size(objs)
+		if c := l.contents[i]; c != nil {
+			contentOffsets[i] = offsets[c] - offsets[objs[0]]
+			contentLengths[i] = len(compiled[c])
+		}
+		shares[i] = len(sharedRefs[i])
+		if shares[i] > maxShares {
+			maxShares = shares[i]
+		}
+		for _, id := range sharedRefs[i] {
+			if id > maxIdentifier {
+				maxIdentifier = id
+			}
+		}
+	}
+	bw := &bitWriter{}
+	// The page offset hint table.
+	items := [][]int{objects, lengths, contentOffsets, contentLengths}
+	mins := make([]int, len(items))
+	widths := make([]int, len(items))
+	for i, item := range items {
+		mins[i], widths[i] = bitRange(item)
+	}
+	bw.write(mins[0], 32)
+	bw.write(adjust(offsets[l.first[0]]), 32)
+	bw.write(widths[0], 16)
+	bw.write(mins[1], 32)
+	bw.write(widths[1], 16)
+	bw.write(mins[2], 32)
+	bw.write(widths[2], 16)
+	bw.write(mins[3], 32)
+	bw.write(widths[3], 16)
+	sharesWidth, identifierWidth := bits.Len(uint(maxShares)), bits.Len(uint(maxIdentifier))
+	bw.write(sharesWidth, 16)
+	bw.write(identifierWidth, 16)
+	// The fractional positions of the shared objects are not used.
+	bw.write(0, 16)
+	bw.write(1, 16)
+	bw.writeAll(objects, mins[0], widths[0])
+	bw.writeAll(lengths, mins[1], widths[1])
+	bw.writeAll(shares, 0, sharesWidth)
+	for _, refs := range sharedRefs {
+		for _, id := range refs {
+			bw.write(id, identifierWidth)
+		}
+	}
+	bw.flush()
+	bw.writeAll(contentOffsets, mins[2], widths[2])
+	bw.writeAll(contentLengths, mins[3], widths[3])
+	sharedTable := bw.buf.Len()
+	// The shared object hint table, whose groups consist of an object.
+	groups := append(append([]pdfObject{}, l.first...), l.shared...)
+	groupLengths := make([]int, len(groups))
+	for i, obj := range groups {
+		groupLengths[i] = len(compiled[obj])
+	}
+	minLength, lengthWidth := bitRange(groupLengths)
+	if len(l.shared) > 0 {
+		bw.write(l.shared[0].refNo(), 32)
+		bw.write(adjust(offsets[l.shared[0]]), 32)
+	} else {
+		bw.write(0, 32)
+		bw.write(0, 32)
+	}
+	bw.write(len(l.first), 32)
+	bw.write(len(groups), 32)
+	bw.write(0, 16)
+	bw.write(minLength, 32)
+	bw.write(lengthWidth, 16)
+	bw.writeAll(groupLengths, minLength, lengthWidth)
+	// The groups have no MD5 signatures.
+	bw.writeAll(make([]int, len(groups)), 0, 1)
+	l.hint.reset()
+	l.hint.dict["/S"] = strconv.Itoa(sharedTable)
+	l.hint.addBinaryDatum(bw.buf.Bytes())
+}
+
+// bitRange returns the least value and the number of bits needed to represent the differences from it.
+func bitRange(values []int) (min, width int) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min, bits.Len(uint(max - min))
+}
+
+// bitWriter packs the integers of the hint tables into bit fields.
+type bitWriter struct {
+	buf bytes.Buffer
+	cur byte
+	n   uint
+}
+
+// write writes the value in the number of bits.
+func (w *bitWriter) write(value, width int) {
+	for i := width - 1; i >= 0; i-- {
+		w.cur = w.cur<<1 | byte(value>>uint(i)&1)
+		w.n++
+		if w.n == 8 {
+			w.buf.WriteByte(w.cur)
+			w.cur, w.n = 0, 0
+		}
+	}
+}
+
+// writeAll writes the differences of the values from min, and pads the last byte,
+// since each item of the hint tables begins at a byte boundary.
+func (w *bitWriter) writeAll(values []int, min, width int) {
+	for _, v := range values {
+		w.write(v-min, width)
+	}
+	w.flush()
+}
+
+// flush pads the last byte with zeros.
+func (w *bitWriter) flush() {
+	if w.n > 0 {
+		w.buf.WriteByte(w.cur << (8 - w.n))
+		w.cur, w.n = 0, 0
+	}
+}
@@ -0,0 +1,231 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bitReader reads the bit fields of the hint tables.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(width int) int {
+	v := 0
+	for i := 0; i < width; i++ {
+		v = v<<1 | int(r.data[r.pos/8]>>uint(7-r.pos%8)&1)
+		r.pos++
+	}
+	return v
+}
+
+func (r *bitReader) align() {
+	r.pos = (r.pos + 7) / 8 * 8
+}
+
+// checkLinearized checks the linearization parameters, the cross-reference sections and the page offset hint table.
+// The hint table is checked unless the document is encrypted.
+func checkLinearized(t *testing.T, pdf []byte, pages []Page) {
+	t.Helper()
+	m := regexp.MustCompile(`^%PDF-\d\.\d\n%[^\n]*\n(\d+) 0 obj\n<</Linearized 1 /L (\d+) /H \[(\d+) (\d+)\] /O (\d+) /E (\d+) /N (\d+) /T (\d+)>>\nendobj\n`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatalf("checkLinearized: linearization dictionary is not found:%q", pdf[:100])
+	}
+	v := make([]int, len(m))
+	for i := 1; i < len(m); i++ {
+		v[i], _ = strconv.Atoi(string(m[i]))
+	}
+	length, hintOffset, hintLength, first, end, n, mainEntry := v[2], v[3], v[4], v[5], v[6], v[7], v[8]
+	if length != len(pdf) || first != pages[0].refNo() || n != len(pages) {
+		t.Errorf("checkLinearized: unexpected parameters:%s", m[0])
+	}
+	if !bytes.HasPrefix(pdf[mainEntry:], []byte("\n0000000000 65535 f")) {
+		t.Errorf("checkLinearized: /T does not locate the main cross-reference table:%d", mainEntry)
+	}
+	if !bytes.HasPrefix(pdf[len(m[0]):], []byte("xref\n")) {
+		t.Error("checkLinearized: first-page cross-reference section does not follow the dictionary")
+	}
+	if !bytes.HasSuffix(pdf[:end], []byte("endobj\n")) {
+		t.Errorf("checkLinearized: /E is not the end of an object:%d", end)
+	}
+	hint := pdf[hintOffset : hintOffset+hintLength]
+	if !regexp.MustCompile(`^\d+ 0 obj\n<<.*/S \d+.*>>\nstream\n`).Match(hint) || !bytes.HasSuffix(hint, []byte("endobj\n")) {
+		t.Errorf("checkLinearized: /H does not locate the hint stream:%q", hint)
+	}
+	// The last startxref locates the first-page section, which refers to the main section.
+	trailer := checkLastXRef(t, pdf)
+	prev := regexp.MustCompile(`/Prev (\d+)`).FindStringSubmatch(trailer)
+	if prev == nil {
+		t.Fatalf("checkLinearized: first-page trailer does not refer the main section:%s", trailer)
+	}
+	checkLastXRef(t, append(append([]byte{}, pdf...), fmt.Sprintf("\nstartxref\n%s\n%%%%EOF", prev[1])...))
+	if strings.Contains(trailer, "/Encrypt ") {
+		return
+	}
+	_, data := readStream(t, pdf, hintOffset)
+	r := &bitReader{data: data}
+	minObjects, location, objectsWidth := r.read(32), r.read(32), r.read(16)
+	minLength, lengthWidth := r.read(32), r.read(16)
+	minContentOffset, contentOffsetWidth := r.read(32), r.read(16)
+	r.read(32 + 16 + 16 + 16 + 16 + 16)
+	objects := make([]int, len(pages))
+	for i := range pages {
+		objects[i] = minObjects + r.read(objectsWidth)
+	}
+	r.align()
+	lengths := make([]int, len(pages))
+	for i := range pages {
+		lengths[i] = minLength + r.read(lengthWidth)
+	}
+	r.align()
+	shares := make([]int, len(pages))
+	sharesWidth := int(data[29])
+	for i := range pages {
+		shares[i] = r.read(sharesWidth)
+	}
+	r.align()
+	total := 0
+	for _, s := range shares {
+		total += s
+	}
+	r.read(total * int(data[31]))
+	r.align()
+	// The offsets in the hint table are computed as if the hint stream were not present.
+	offset := location + hintLength
+	for i, p := range pages {
+		if !bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n<</Type /Page ", p.refNo()))) {
+			t.Errorf("checkLinearized: hint table does not locate the page %d", i+1)
+		}
+		contents := offset + minContentOffset + r.read(contentOffsetWidth)
+		if !bytes.HasPrefix(pdf[contents:], []byte(fmt.Sprintf("%d 0 obj\n", p.(*page).contents.refNo()))) {
+			t.Errorf("checkLinearized: hint table does not locate the contents of the page %d", i+1)
+		}
+		offset += lengths[i]
+		if i == 0 && offset != end {
+			t.Errorf("checkLinearized: first page does not end at /E:%d %d", offset, end)
+		}
+	}
+}
+
+func buildLinearized(t *testing.T, options ...BuildOption) (*Builder, []Page, []byte) {
+	t.Helper()
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetReproducible(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	b.SetInfo(DocumentInfo{Title: "Catalog"})
+	font := b.NewFontType1("/Helvetica")
+	b.AddFont(font)
+	shared := b.NewFontType1("/Courier")
+	pages := make([]Page, 0)
+	for i := 0; i < 5; i++ {
+		p := b.AddPage()
+		p.WriteText(10, 10, font, 10, fmt.Sprintf("page%d", i))
+		if i > 0 {
+			p.AddFont(font)
+			p.AddFont(shared)
+			p.WriteText(10, 30, shared, 10, "shared")
+		}
+		pages = append(pages, p)
+	}
+	pages[1].Note(10, 10, "note")
+	b.SetOpenAction(pages[0], OutlineDestinationBasic())
+	var buf bytes.Buffer
+	if err := b.Build(&buf, append(options, WithLinearization())...); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	return b, pages, buf.Bytes()
+}
+
+func TestBuilderLinearization(t *testing.T) {
+	b, pages, out := buildLinearized(t)
+	checkLinearized(t, out, pages)
+	checkReferences(t, out)
+	l := b.linearization
+	// The first page section is numbered after the other objects.
+	if b.dc.refNo() <= pages[1].refNo() || pages[0].refNo() <= pages[4].refNo() {
+		t.Errorf("Build: unexpected numbers:%d %d %d", b.dc.refNo(), pages[0].refNo(), pages[1].refNo())
+	}
+	if len(l.pages) != len(pages)-1 || l.pages[0][0] != pages[1] {
+		t.Error("Build: pages are not laid out in order")
+	}
+	// The font is shared by the pages other than the first page.
+	if len(l.shared) != 1 || !bytes.Contains(out, []byte(fmt.Sprintf("%d 0 obj\n<</Type /Font", l.shared[0].refNo()))) {
+		t.Errorf("Build: unexpected shared objects:%v", l.shared)
+	}
+	for i, refs := range l.sharedRefs {
+		if len(refs) == 0 {
+			t.Errorf("Build: page %d does not refer the shared objects", i+2)
+		}
+	}
+	// Inherited attributes are copied into the pages.
+	if !bytes.Contains(out, []byte(fmt.Sprintf("%d 0 obj\n<</Type /Page /Parent ", pages[0].refNo()))) ||
+		!strings.Contains(pages[0].compile(), "/MediaBox ") || !strings.Contains(pages[0].compile(), "/Resources ") {
+		t.Error("Build: inherited attributes are not copied into the page")
+	}
+	if _, _, again := buildLinearized(t); !bytes.Equal(out, again) {
+		t.Error("Build: linearized output is not reproducible")
+	}
+}
+
+func TestBuilderLinearizationEncryption(t *testing.T) {
+	_, pages, out := buildLinearized(t, WithEncryption(&PasswordEncryption{Algorithm: EncryptionAES128}))
+	checkLinearized(t, out, pages)
+	if bytes.Contains(out, []byte("(Catalog)")) {
+		t.Error("Build: strings are not encrypted")
+	}
+}
+
+func TestBuilderLinearizationSignature(t *testing.T) {
+	cert, key := newTestCertificate(t, "signer")
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	p := b.AddPage()
+	sf := p.SignatureField("approval", 0, 0, 0, 0)
+	b.AddPage()
+	var buf bytes.Buffer
+	if err := b.Build(&buf, WithSignature(sf, key, []*x509.Certificate{cert}), WithLinearization()); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.Bytes()
+	checkLinearized(t, out, b.dc.pages.allPages())
+	data, der := signedBytes(t, out)
+	verifyDetached(t, der, data)
+}
+
+func TestBuilderLinearizationError(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	if err := b.Build(&buf, WithLinearization()); err == nil || err.Error() != "linearization: document has no pages" {
+		t.Errorf("Build: unexpected error:%v", err)
+	}
+	b.AddPage()
+	b.SetObjectStreams(true)
+	if err := b.Build(&buf, WithLinearization()); err == nil || err.Error() != "linearization: object streams are not supported" {
+		t.Errorf("Build: unexpected error:%v", err)
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	if err := b.StartStreaming(&buf, WithLinearization()); err == nil || err.Error() != "streaming: linearization is not supported" {
+		t.Errorf("StartStreaming: unexpected error:%v", err)
+	}
+}
+
+func TestBitWriter(t *testing.T) {
+	w := &bitWriter{}
+	w.write(5, 3)
+	w.write(1, 1)
+	w.flush()
+	w.writeAll([]int{3, 4, 5}, 3, 2)
+	w.write(0x1234, 16)
+	expected := []byte{0xB0, 0x18, 0x12, 0x34}
+	if !bytes.Equal(expected, w.buf.Bytes()) {
+		t.Errorf("bitWriter: expected:%x, actual:%x", expected, w.buf.Bytes())
+	}
+	if min, width := bitRange([]int{7, 3, 5}); min != 3 || width != 3 {
+		t.Errorf("bitRange: unexpected:%d %d", min, width)
+	}
+}
@@ -0,0 +1,173 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// timestampSize is the number of bytes reserved for a time-stamp token.
+const timestampSize = 16384
+
+// ValidationData is the information to validate signatures after the certificates expire or are revoked.
+type ValidationData struct {
+	// Certificates are the certificates of the signers, the certification authorities and the time-stamp authorities.
+	Certificates []*x509.Certificate
+	// CRLs are the DER encoded certificate revocation lists.
+	CRLs [][]byte
+	// OCSPs are the DER encoded OCSP responses.
+	OCSPs [][]byte
+}
+
+// dss is a document security store.
+// Each certificate, CRL and OCSP response is stored in a stream.
+type dss struct {
+	objectIdentifier
+	certs []*stream
+	crls  []*stream
+	ocsps []*stream
+	// stored are the DER encoded data which have been stored.
+	stored map[string]bool
+}
+
+func newDSS(vd *ValidationData) *dss {
+	d := &dss{
+		objectIdentifier: objectIdentifier{},
+		certs:            make([]*stream, 0, len(vd.Certificates)),
+		crls:             make([]*stream, 0, len(vd.CRLs)),
+		ocsps:            make([]*stream, 0, len(vd.OCSPs)),
+		stored:           make(map[string]bool),
+	}
+	d.add(vd)
+	return d
+}
+
+// add stores the validation data which has not been stored.
+// The streams of the stored data are kept, so that the later revisions refer to them.
+func (d *dss) add(vd *ValidationData) {
+	for _, c := range vd.Certificates {
+		d.certs = d.addStream(d.certs, c.Raw)
+	}
+	for _, c := range vd.CRLs {
+		d.crls = d.addStream(d.crls, c)
+	}
+	for _, o := range vd.OCSPs {
+		d.ocsps = d.addStream(d.ocsps, o)
+	}
+}
+
+func (d *dss) addStream(streams []*stream, der []byte) []*stream {
+	if d.stored[string(der)] {
+		return streams
+	}
+	d.stored[string(der)] = true
+	return append(streams, newDSSStream(der))
+}
+
+func newDSSStream(der []byte) *stream {
+	s := newDeflatedStream()
+	s.addBinaryDatum(der)
+	return s
+}
+
+func (d *dss) compile() string {
+	dict := []string{"/Type /DSS"}
+	for _, e := range []struct {
+		key     string
+		streams []*stream
+	}{
+		{"/Certs", d.certs},
+		{"/CRLs", d.crls},
+		{"/OCSPs", d.ocsps},
+	} {
+		if len(e.streams) == 0 {
+			continue
+		}
+		refs := make([]string, 0, len(e.streams))
+		for _, s := range e.streams {
+			refs = append(refs, s.indirectReference())
+		}
+		dict = append(dict, fmt.Sprintf("%s [%s]", e.key, strings.Join(refs, " ")))
+	}
+	return d.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
+}
+
+func (d *dss) walk(walker func(obj pdfObject)) {
+	if d == nil {
+		return
+	}
+	walker(d)
+	for _, streams := range [][]*stream{d.certs, d.crls, d.ocsps} {
+		for _, s := range streams {
+			walker(s)
+		}
+	}
+}
+
+// documentTimestamp is an incremental update which adds a document timestamp and a document security store.
+type documentTimestamp struct {
+	tsa TimestampAuthority
+	vd  *ValidationData
+}
+
+// WithDocumentTimestamp appends an incremental update to the document,
+// which adds the document security store of the validation data and a document timestamp issued by the time-stamp authority.
+// The timestamp covers the whole document, including the signature of WithSignature and the validation data.
+// If vd is nil, only the timestamp is added.
+// The validation data added by the previous revisions remains in the store.
+// The time-stamped document cannot be built again, and its later revisions are appended by Update.
+func WithDocumentTimestamp(tsa TimestampAuthority, vd *ValidationData) BuildOption {
+	return func(o *buildOptions) {
+		o.timestamp = &documentTimestamp{
+			tsa: tsa,
+			vd:  vd,
+		}
+	}
+}
+
+// update appends the incremental update to the written document, and embeds the timestamp in it.
+// The invisible signature field of the timestamp is added to the first page.
+func (dt *documentTimestamp) update(b *Builder, pdf []byte) ([]byte, error) {
+	pages := b.dc.pages.allPages()
+	if len(pages) == 0 {
+		return nil, errors.New("timestamp: document has no pages")
+	}
+	form := b.dc.acroForm()
+	name := "DocumentTimestamp"
+	for i := 2; form.nodes[name] != nil || form.terminals[name] != nil; i++ {
+		name = fmt.Sprintf("DocumentTimestamp%d", i)
+	}
+	page := pages[0]
+	sf := page.SignatureField(name, 0, 0, 0, 0).(*signatureField)
+	sf.value = &signatureValue{timestamp: true, size: timestampSize}
+	if err := form.addField(sf); err != nil {
+		return nil, err
+	}
+	// The validation data of the previous revisions is kept in the store.
+	switch {
+	case dt.vd == nil:
+	case b.dc.dss == nil:
+		b.dc.dss = newDSS(dt.vd)
+	default:
+		b.dc.dss.add(dt.vd)
+	}
+	b.dc.walk(func(obj pdfObject) {
+		obj.number(b.c)
+	})
+	// The update contains the changed catalog and page, and the new objects.
+	buf := bytes.NewBuffer(pdf)
+	if err := b.appendUpdate(buf); err != nil {
+		return nil, err
+	}
+	signed, err := embedSignature(buf.Bytes(), sf.value, func(data []byte) ([]byte, error) {
+		return timestampData(dt.tsa, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The field remains on the page, since the later revisions must keep it.
+	b.timestamped = true
+	return signed, nil
+}
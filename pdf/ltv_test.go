@@ -0,0 +1,224 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestDSS(t *testing.T) {
+	cert, _ := newTestCertificate(t, "signer")
+	d := newDSS(&ValidationData{
+		Certificates: []*x509.Certificate{cert},
+		OCSPs:        [][]byte{{0x30, 0x00}, {0x30, 0x01, 0x00}},
+	})
+	c := newCounter()
+	d.walk(func(obj pdfObject) {
+		obj.number(c)
+	})
+	testCompillation(t, "3 0 obj\n<</Type /DSS /Certs [4 0 R] /OCSPs [5 0 R 6 0 R]>>\nendobj\n", d.compile())
+	data, _ := d.certs[0].compile()
+	if !bytes.Contains(data, []byte("/Filter /FlateDecode")) {
+		t.Error("newDSS: stream is not deflated")
+	}
+	var nilDSS *dss
+	nilDSS.walk(func(obj pdfObject) {
+		t.Error("walk: nil store must not walk objects")
+	})
+}
+
+// checkLastXRef checks that the last cross-reference section points to the objects,
+// and returns its trailer dictionary.
+func checkLastXRef(t *testing.T, pdf []byte) string {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("checkLastXRef: startxref is not found")
+	}
+	start, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[start:], []byte("xref\n")) {
+		t.Fatalf("checkLastXRef: startxref does not point to the cross-reference section:%d", start)
+	}
+	section := string(pdf[start:])
+	end := regexp.MustCompile(`trailer\n`).FindStringIndex(section)[0]
+	subsection := regexp.MustCompile(`(\d+) (\d+)\n((?:\d{10} \d{5} [nf] \n)+)`)
+	for _, sub := range subsection.FindAllStringSubmatch(section[:end], -1) {
+		first, _ := strconv.Atoi(sub[1])
+		for i, entry := range regexp.MustCompile(`(\d{10}) (\d{5}) ([nf])`).FindAllStringSubmatch(sub[3], -1) {
+			if entry[3] == "f" {
+				continue
+			}
+			offset, _ := strconv.Atoi(entry[1])
+			expected := fmt.Sprintf("%d 0 obj\n", first+i)
+			if !bytes.HasPrefix(pdf[offset:], []byte(expected)) {
+				t.Errorf("checkLastXRef: entry does not point to the object %d", first+i)
+			}
+		}
+	}
+	return regexp.MustCompile(`trailer\n<<(.*)>>\n`).FindStringSubmatch(section)[1]
+}
+
+func TestBuilderDocumentTimestamp(t *testing.T) {
+	cert, key := newTestCertificate(t, "signer")
+	tsa := newTestTimestampAuthority(t)
+	vd := &ValidationData{
+		Certificates: []*x509.Certificate{cert, tsa.cert},
+		CRLs:         [][]byte{{0x30, 0x00}},
+	}
+	for _, encryption := range []Encryption{nil, &PasswordEncryption{}} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		p := b.AddPage()
+		p.SignatureField("DocumentTimestamp", 0, 0, 0, 0)
+		sf := p.SignatureField("approval", 0, 0, 0, 0)
+		options := []BuildOption{WithSignature(sf, key, []*x509.Certificate{cert}), WithDocumentTimestamp(tsa, vd)}
+		if encryption != nil {
+			options = append(options, WithEncryption(encryption))
+		}
+		var buf bytes.Buffer
+		if err := b.Build(&buf, options...); err != nil {
+			t.Fatalf("Build: unexpected error:%s", err)
+		}
+		out := buf.Bytes()
+		// The signature covers the original revision.
+		eof := bytes.Index(out, []byte("%%EOF")) + len("%%EOF")
+		original := out[:eof]
+		data, der := signedBytes(t, original)
+		verifyDetached(t, der, data)
+		// The timestamp covers the whole document.
+		data, token := signedBytes(t, out)
+		verifyTimestamp(t, token, data)
+		trailer := checkLastXRef(t, out)
+		m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF`).FindSubmatch(original)
+		if !regexp.MustCompile(fmt.Sprintf(`/Prev %s\b`, m[1])).MatchString(trailer) {
+			t.Errorf("Build: update does not refer the previous section:%s", trailer)
+		}
+		if !bytes.Contains([]byte(trailer), []byte(fmt.Sprintf("/Size %d", b.c.count+1))) {
+			t.Errorf("Build: unexpected size:%s", trailer)
+		}
+		update := out[eof:]
+		if !bytes.Contains(update, []byte("/Type /DocTimeStamp /Filter /Adobe.PPKLite /SubFilter /ETSI.RFC3161")) {
+			t.Error("Build: document timestamp is not written")
+		}
+		if !bytes.Contains(update, []byte("/T (DocumentTimestamp2)")) && encryption == nil {
+			t.Error("Build: name of the timestamp field is not unique")
+		}
+		dss := b.dc.dss
+		for _, expected := range []string{
+			"1 0 obj\n<</Type /Catalog ",
+			fmt.Sprintf("/DSS %s", dss.indirectReference()),
+			fmt.Sprintf("%d 0 obj\n<</Type /DSS /Certs [%s %s] /CRLs [%s]>>", dss.refNo(),
+				dss.certs[0].indirectReference(), dss.certs[1].indirectReference(), dss.crls[0].indirectReference()),
+			fmt.Sprintf("%d 0 obj\n<</Type /Page ", p.refNo()),
+		} {
+			if !bytes.Contains(update, []byte(expected)) {
+				t.Errorf("Build: update does not contain:%q", expected)
+			}
+		}
+		if len(p.annotations()) != 3 {
+			t.Errorf("Build: timestamp field is not added to the page:%d", len(p.annotations()))
+		}
+	}
+}
+
+func TestBuilderDocumentTimestampUpdate(t *testing.T) {
+	signer, _ := newTestCertificate(t, "signer")
+	tsa := newTestTimestampAuthority(t)
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	var buf bytes.Buffer
+	if err := b.Build(&buf, WithDocumentTimestamp(tsa, &ValidationData{Certificates: []*x509.Certificate{signer}, CRLs: [][]byte{{0x30, 0x00}}})); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	original := buf.Bytes()
+	stored := b.dc.dss
+	cert, crl := stored.certs[0], stored.crls[0]
+	var out bytes.Buffer
+	vd := &ValidationData{Certificates: []*x509.Certificate{signer, tsa.cert}, OCSPs: [][]byte{{0x30, 0x01, 0x00}}}
+	if err := b.Update(&out, original, WithDocumentTimestamp(tsa, vd)); err != nil {
+		t.Fatalf("Update: unexpected error:%s", err)
+	}
+	updated := out.Bytes()
+	data, token := signedBytes(t, updated)
+	verifyTimestamp(t, token, data)
+	d := b.dc.dss
+	if d != stored || len(d.certs) != 2 || d.certs[0] != cert || len(d.crls) != 1 || d.crls[0] != crl || len(d.ocsps) != 1 {
+		t.Fatalf("Update: validation data is not merged:%d certificates, %d CRLs, %d OCSP responses", len(d.certs), len(d.crls), len(d.ocsps))
+	}
+	expected := fmt.Sprintf("%d 0 obj\n<</Type /DSS /Certs [%s %s] /CRLs [%s] /OCSPs [%s]>>", d.refNo(),
+		cert.indirectReference(), d.certs[1].indirectReference(), crl.indirectReference(), d.ocsps[0].indirectReference())
+	if !bytes.Contains(updated[len(original):], []byte(expected)) {
+		t.Errorf("Update: update does not contain:%q", expected)
+	}
+	written := updatedObjects(updated, original)
+	for _, s := range []*stream{cert, crl} {
+		for _, n := range written {
+			if n == fmt.Sprintf("%d", s.refNo()) {
+				t.Errorf("Update: stored stream %d is written again", s.refNo())
+			}
+		}
+	}
+}
+
+func TestBuilderDocumentTimestampObjectStreams(t *testing.T) {
+	tsa := newTestTimestampAuthority(t)
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetObjectStreams(true)
+	b.AddPage()
+	var buf bytes.Buffer
+	if err := b.Build(&buf, WithDocumentTimestamp(tsa, &ValidationData{})); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.Bytes()
+	data, token := signedBytes(t, out)
+	verifyTimestamp(t, token, data)
+	eof := bytes.Index(out, []byte("%%EOF")) + len("%%EOF")
+	original := out[:eof]
+	checkXRefStream(t, original)
+	dict := checkXRefStream(t, out)
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF`).FindSubmatch(original)
+	if !regexp.MustCompile(fmt.Sprintf(`/Prev %s\b`, m[1])).MatchString(dict) {
+		t.Errorf("Build: update does not refer the previous section:%s", dict)
+	}
+}
+
+func TestBuilderDocumentTimestampError(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	if err := b.Build(&bytes.Buffer{}, WithDocumentTimestamp(nil, nil)); err == nil {
+		t.Error("Build: timestamp without authority must be error")
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	failure := timestampAuthorityFunc(func(digest []byte) ([]byte, error) {
+		return nil, errors.New("unavailable")
+	})
+	if err := b.Build(&bytes.Buffer{}, WithDocumentTimestamp(failure, nil)); err == nil {
+		t.Error("Build: failure of the authority must be error")
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	var buf bytes.Buffer
+	if err := b.Build(&buf, WithDocumentTimestamp(newTestTimestampAuthority(t), nil)); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("/DSS")) {
+		t.Error("Build: store must not be written without validation data")
+	}
+	data, token := signedBytes(t, buf.Bytes())
+	verifyTimestamp(t, token, data)
+	// The timestamp field would be written without the timestamp by a later build.
+	if err := b.Build(&bytes.Buffer{}); err == nil || err.Error() != "timestamp: document has been time-stamped, use Update to append revisions" {
+		t.Errorf("Build: unexpected error:%v", err)
+	}
+	var updated bytes.Buffer
+	if err := b.Update(&updated, buf.Bytes()); err != nil {
+		t.Fatalf("Update: unexpected error:%s", err)
+	}
+	if bytes.Contains(updated.Bytes()[buf.Len():], []byte("/DocTimeStamp")) {
+		t.Error("Update: timestamp must not be written again")
+	}
+}
@@ -0,0 +1,410 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// noteIconSize is the width and height of the icon of a text annotation.
+const noteIconSize = 20
+
+// annotationBuilder is an annotation which completes itself when the document is built.
+type annotationBuilder interface {
+	// build fills the dates which have not been specified, and generates the appearance stream.
+	build(now time.Time)
+}
+
+// MarkupAnnotation is an annotation for marking up the document.
+// Its appearance stream is generated when the document is built.
+type MarkupAnnotation interface {
+	Annotation
+	// Author specifies the name of the user who created the annotation.
+	Author(author string) MarkupAnnotation
+	// Contents specifies the text displayed for the annotation.
+	Contents(contents string) MarkupAnnotation
+	// Color specifies the color of the annotation.
+	Color(c Color) MarkupAnnotation
+	// CreationDate specifies the date and time when the annotation was created.
+	// If it is not specified, the time when the document is built is used.
+	CreationDate(t time.Time) MarkupAnnotation
+}
+
+// markupAnnotation provides a common functionality of markup annotations.
+type markupAnnotation struct {
+	annotation
+	self         MarkupAnnotation
+	author       string
+	creationDate time.Time
+	modDate      time.Time
+	appearance   *stream
+}
+
+func newMarkupAnnotation(subtype string, page Page, rect *Box) markupAnnotation {
+	return markupAnnotation{
+		annotation: newAnnotation(subtype, page, rect),
+		appearance: newFormXObject(rect.rightTopX-rect.leftBottomX, rect.rightTopY-rect.leftBottomY),
+	}
+}
+
+func (m *markupAnnotation) Author(author string) MarkupAnnotation {
+	m.author = author
+	return m.self
+}
+
+func (m *markupAnnotation) Contents(contents string) MarkupAnnotation {
+	m.contents = contents
+	return m.self
+}
+
+func (m *markupAnnotation) Color(c Color) MarkupAnnotation {
+	m.color = c
+	return m.self
+}
+
+func (m *markupAnnotation) CreationDate(t time.Time) MarkupAnnotation {
+	m.creationDate = t
+	return m.self
+}
+
+// width is the width of the annotation rectangle.
+func (m *markupAnnotation) width() int {
+	return m.rect.rightTopX - m.rect.leftBottomX
+}
+
+// height is the height of the annotation rectangle.
+func (m *markupAnnotation) height() int {
+	return m.rect.rightTopY - m.rect.leftBottomY
+}
+
+// bbox is the bounding box of the appearance stream.
+func (m *markupAnnotation) bbox() *Box {
+	return NewBox(0, 0, m.width(), m.height())
+}
+
+// buildHelper fills the dates, and replaces the appearance stream with the contents.
+func (m *markupAnnotation) buildHelper(now time.Time, contents ...string) {
+	if m.creationDate.IsZero() {
+		m.creationDate = now
+	}
+	m.modDate = now
+	m.appearance.reset()
+	for _, c := range contents {
+		m.appearance.addStringDatum(c)
+	}
+}
+
+// compileHelper compiles the markup annotation dictionary with the subtype-specific entries.
+func (m *markupAnnotation) compileHelper(entries ...string) string {
+	dict := make([]string, 0, 4+len(entries))
+	if m.author != "" {
+		dict = append(dict, fmt.Sprintf("/T %s", textString(m.author)))
+	}
+	if !m.creationDate.IsZero() {
+		dict = append(dict, fmt.Sprintf("/CreationDate %s", dateString(m.creationDate)))
+	}
+	if !m.modDate.IsZero() {
+		dict = append(dict, fmt.Sprintf("/M %s", dateString(m.modDate)))
+	}
+	dict = append(dict, fmt.Sprintf("/AP <</N %s>>", m.appearance.indirectReference()))
+	return m.annotation.compileHelper(append(dict, entries...)...)
+}
+
+func (m *markupAnnotation) walk(walker func(obj pdfObject)) {
+	walker(m.self)
+	m.appearance.walk(walker)
+}
+
+// note is a text annotation.
+// It represents a “sticky note” attached to a point in the document.
+type note struct {
+	markupAnnotation
+}
+
+func newNote(page Page, rect *Box, contents string) *note {
+	n := &note{newMarkupAnnotation("/Text", page, rect)}
+	n.self = n
+	n.contents = contents
+	n.color = NewColorRGB(1, 1, 0)
+	n.flags |= annotationFlagNoZoom | annotationFlagNoRotate
+	return n
+}
+
+func (n *note) build(now time.Time) {
+	bbox := n.bbox()
+	icon := newRectangle(nil, 0, 0, n.width(), n.height())
+	icon.StrokeColor(NewColorGrayScale(0)).FillColor(n.color)
+	contents := []string{icon.render(bbox)}
+	for y := n.height() / 4; y < n.height(); y += n.height() / 4 {
+		contents = append(contents, newLine(nil, n.width()/5, y, n.width()*4/5, y, 1).render(bbox))
+	}
+	n.buildHelper(now, contents...)
+}
+
+func (n *note) compile() string {
+	return n.compileHelper("/Name /Note")
+}
+
+// fileAttachment is a file attachment annotation.
+// It represents a reference to an embedded file with an icon.
+type fileAttachment struct {
+	markupAnnotation
+	file *embeddedFile
+}
+
+func newFileAttachment(page Page, rect *Box, file *embeddedFile) *fileAttachment {
+	fa := &fileAttachment{
+		markupAnnotation: newMarkupAnnotation("/FileAttachment", page, rect),
+		file:             file,
+	}
+	fa.self = fa
+	fa.contents = file.name
+	fa.color = NewColorRGB(0.6, 0.75, 0.85)
+	fa.flags |= annotationFlagNoZoom | annotationFlagNoRotate
+	return fa
+}
+
+func (fa *fileAttachment) build(now time.Time) {
+	bbox := fa.bbox()
+	w, h := fa.width(), fa.height()
+	// A sheet of paper with a folded corner.
+	sheet := newRectangle(nil, w/5, 0, w*3/5, h)
+	sheet.StrokeColor(NewColorGrayScale(0)).FillColor(fa.color)
+	contents := []string{sheet.render(bbox), newLine(nil, w*3/5, 0, w*4/5, h/5, 1).render(bbox)}
+	for y := h / 4; y < h*3/4; y += h / 4 {
+		contents = append(contents, newLine(nil, w*2/5, y, w*3/5, y, 1).render(bbox))
+	}
+	fa.buildHelper(now, contents...)
+}
+
+func (fa *fileAttachment) compile() string {
+	return fa.compileHelper(fmt.Sprintf("/FS %s /Name /Paperclip", fa.file.indirectReference()))
+}
+
+func (fa *fileAttachment) walk(walker func(obj pdfObject)) {
+	fa.markupAnnotation.walk(walker)
+	fa.file.walk(walker)
+}
+
+// textMarkup is a highlight or an underline annotation.
+type textMarkup struct {
+	markupAnnotation
+}
+
+func newTextMarkup(subtype string, page Page, rect *Box) *textMarkup {
+	tm := &textMarkup{newMarkupAnnotation(subtype, page, rect)}
+	tm.self = tm
+	tm.color = NewColorRGB(1, 1, 0)
+	if subtype == "/Underline" {
+		tm.color = NewColorRGB(0, 0, 1)
+	}
+	return tm
+}
+
+func (tm *textMarkup) build(now time.Time) {
+	bbox := tm.bbox()
+	if tm.subtype == "/Underline" {
+		tm.buildHelper(now, newLine(nil, 0, tm.height()-1, tm.width(), tm.height()-1, 1).Color(tm.color).render(bbox))
+		return
+	}
+	// The multiply blend mode keeps the highlighted text visible.
+	tm.appearance.resetResources()
+	tm.appearance.resources.addExtGState("/GS0", "<</Type /ExtGState /BM /Multiply>>")
+	fill := newRectangle(nil, 0, 0, tm.width(), tm.height()).FillColor(tm.color)
+	tm.buildHelper(now, "/GS0 gs\n", fill.render(bbox))
+}
+
+// quadPoints returns the coordinates of the marked up region.
+func (tm *textMarkup) quadPoints() string {
+	r := tm.rect
+	return fmt.Sprintf("[%d %d %d %d %d %d %d %d]",
+		r.leftBottomX, r.rightTopY, r.rightTopX, r.rightTopY,
+		r.leftBottomX, r.leftBottomY, r.rightTopX, r.leftBottomY)
+}
+
+func (tm *textMarkup) compile() string {
+	return tm.compileHelper(fmt.Sprintf("/QuadPoints %s", tm.quadPoints()))
+}
+
+// freeText is a free text annotation.
+// It displays text directly on the page.
+type freeText struct {
+	markupAnnotation
+	font     Font
+	fontSize int
+	text     string
+}
+
+func newFreeText(page Page, rect *Box, font Font, fontSize int, text string) *freeText {
+	ft := &freeText{
+		markupAnnotation: newMarkupAnnotation("/FreeText", page, rect),
+		font:             font,
+		fontSize:         fontSize,
+		text:             text,
+	}
+	ft.self = ft
+	ft.contents = text
+	ft.appearance.resources = newResource()
+	ft.appearance.resources.addFont(font)
+	// The glyphs used in the text are registered to the font before the font is built.
+	ft.textOperator()
+	return ft
+}
+
+// textOperator returns the operators painting the text in the appearance stream.
+func (ft *freeText) textOperator() string {
+	return ft.font.createText(2, ft.height()-ft.fontSize-2, ft.fontSize, ft.text)
+}
+
+func (ft *freeText) build(now time.Time) {
+	contents := make([]string, 0, 2)
+	if ft.color.colorSpace() != colorSpaceUndefined {
+		frame := newRectangle(nil, 0, 0, ft.width(), ft.height()).StrokeColor(ft.color)
+		contents = append(contents, frame.render(ft.bbox()))
+	}
+	contents = append(contents, ft.textOperator())
+	ft.buildHelper(now, contents...)
+}
+
+func (ft *freeText) compile() string {
+	return ft.compileHelper(fmt.Sprintf("/DA (%s %d Tf 0 g)", ft.font.resourceName(), ft.fontSize))
+}
+
+// shape is a square or a circle annotation.
+type shape struct {
+	markupAnnotation
+	lineWidth int
+}
+
+func newShape(subtype string, page Page, rect *Box, lineWidth int) *shape {
+	s := &shape{
+		markupAnnotation: newMarkupAnnotation(subtype, page, rect),
+		lineWidth:        lineWidth,
+	}
+	s.self = s
+	s.color = NewColorRGB(1, 0, 0)
+	return s
+}
+
+func (s *shape) build(now time.Time) {
+	lw := s.lineWidth
+	if s.subtype == "/Circle" {
+		rx := float64(s.width()-lw) / 2
+		ry := float64(s.height()-lw) / 2
+		s.buildHelper(now, fmt.Sprintf("q %s %d w %s S Q\n",
+			s.color.strokeColor(), lw, ellipse(float64(s.width())/2, float64(s.height())/2, rx, ry)))
+		return
+	}
+	// The border is drawn inside the annotation rectangle.
+	frame := newRectangle(nil, lw/2, lw/2, s.width()-lw, s.height()-lw).StrokeColor(s.color)
+	s.buildHelper(now, fmt.Sprintf("%d w\n", lw), frame.render(s.bbox()))
+}
+
+func (s *shape) compile() string {
+	return s.compileHelper(fmt.Sprintf("/BS <</W %d /S /S>>", s.lineWidth))
+}
+
+// ellipse returns the path of the ellipse approximated by four Bézier curves.
+func ellipse(cx, cy, rx, ry float64) string {
+	// kappa is the distance of control points for the quarter circle.
+	const kappa = 0.5522847498
+	kx := rx * kappa
+	ky := ry * kappa
+	return fmt.Sprintf(
+		"%f %f m %f %f %f %f %f %f c %f %f %f %f %f %f c %f %f %f %f %f %f c %f %f %f %f %f %f c",
+		cx+rx, cy,
+		cx+rx, cy+ky, cx+kx, cy+ry, cx, cy+ry,
+		cx-kx, cy+ry, cx-rx, cy+ky, cx-rx, cy,
+		cx-rx, cy-ky, cx-kx, cy-ry, cx, cy-ry,
+		cx+kx, cy-ry, cx+rx, cy-ky, cx+rx, cy)
+}
+
+// ink is an ink annotation.
+// It represents a freehand “scribble” composed of one or more disjoint paths.
+type ink struct {
+	markupAnnotation
+	lineWidth int
+	// paths are the points of the paths in the coordinate of the appearance stream.
+	paths [][]int
+}
+
+// newInk returns an ink annotation.
+// Arguments paths are the points of the paths in the coordinate of the page, such as x1, y1, x2, y2...
+func newInk(page Page, cb *Box, lineWidth int, paths [][]int) *ink {
+	minX, minY, maxX, maxY := 0, 0, 0, 0
+	for i, path := range paths {
+		for j := 0; j+1 < len(path); j += 2 {
+			x, y := path[j], path[j+1]
+			if (i == 0 && j == 0) || x < minX {
+				minX = x
+			}
+			if (i == 0 && j == 0) || y < minY {
+				minY = y
+			}
+			if (i == 0 && j == 0) || x > maxX {
+				maxX = x
+			}
+			if (i == 0 && j == 0) || y > maxY {
+				maxY = y
+			}
+		}
+	}
+	// The annotation rectangle contains the stroke of the paths.
+	left := minX - lineWidth
+	top := minY - lineWidth
+	width := maxX - minX + 2*lineWidth
+	height := maxY - minY + 2*lineWidth
+	rect := NewBox(cb.leftBottomX+left, cb.rightTopY-top-height, cb.leftBottomX+left+width, cb.rightTopY-top)
+	local := make([][]int, len(paths))
+	for i, path := range paths {
+		local[i] = make([]int, 0, len(path))
+		for j := 0; j+1 < len(path); j += 2 {
+			local[i] = append(local[i], path[j]-left, path[j+1]-top)
+		}
+	}
+	in := &ink{
+		markupAnnotation: newMarkupAnnotation("/Ink", page, rect),
+		lineWidth:        lineWidth,
+		paths:            local,
+	}
+	in.self = in
+	in.color = NewColorRGB(0, 0, 1)
+	return in
+}
+
+func (in *ink) build(now time.Time) {
+	contents := make([]string, 0, len(in.paths))
+	for _, path := range in.paths {
+		if len(path) < 4 {
+			continue
+		}
+		l := newLine(nil, path[0], path[1], path[2], path[3], in.lineWidth).
+			Color(in.color).
+			CapStyle(LineCapStyleRound).
+			JoinStyle(LineJoinStyleRound)
+		for j := 4; j+1 < len(path); j += 2 {
+			l.MoveTo(path[j], path[j+1])
+		}
+		contents = append(contents, l.render(in.bbox()))
+	}
+	in.buildHelper(now, contents...)
+}
+
+// inkList returns the paths in the default user space.
+func (in *ink) inkList() string {
+	list := make([]string, 0, len(in.paths))
+	for _, path := range in.paths {
+		points := make([]string, 0, len(path))
+		for j := 0; j+1 < len(path); j += 2 {
+			points = append(points, fmt.Sprintf("%d %d", in.rect.leftBottomX+path[j], in.rect.rightTopY-path[j+1]))
+		}
+		list = append(list, fmt.Sprintf("[%s]", strings.Join(points, " ")))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(list, " "))
+}
+
+func (in *ink) compile() string {
+	return in.compileHelper(
+		fmt.Sprintf("/InkList %s", in.inkList()),
+		fmt.Sprintf("/BS <</W %d /S /S>>", in.lineWidth))
+}
@@ -0,0 +1,138 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestPage() *page {
+	pl := newRootPage(NewBox(0, 0, 100, 200), NewBox(10, 20, 90, 180))
+	p := pl.newPage(nil, nil, nil).(*page)
+	p.objectNumber = 3
+	return p
+}
+
+func TestNote(t *testing.T) {
+	p := newTestPage()
+	created := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := p.Note(5, 10, "comment").Author("reviewer").CreationDate(created).Color(NewColorGrayScale(0.5))
+	if len(p.annotations()) != 1 || p.annotations()[0] != a {
+		t.Fatal("Note: note is not added to the page")
+	}
+	n := a.(*note)
+	n.objectNumber = 4
+	n.appearance.objectNumber = 5
+	n.build(now)
+	expected := "4 0 obj\n<</Type /Annot /Subtype /Text /Rect [15 150 35 170] /P 3 0 R /F 28 /C [0.500000] /Contents (comment) /T (reviewer) /CreationDate (D:20180102030405Z) /M (D:20190102030405Z) /AP <</N 5 0 R>> /Name /Note>>\nendobj\n"
+	testCompillation(t, expected, n.compile())
+	ap := string(n.appearance.data[0])
+	testRendering(t, "q 0.000000 G 0.500000 g 0 0 20 20 re B Q\n", ap)
+	if len(n.appearance.data) != 4 {
+		t.Errorf("build: appearance: expected 3 lines, actual:%d", len(n.appearance.data)-1)
+	}
+	n.build(now)
+	if len(n.appearance.data) != 4 {
+		t.Error("build: appearance is not reset")
+	}
+	testCompillation(t, "[0 0 20 20]", n.appearance.dict["/BBox"])
+	walked := make([]pdfObject, 0)
+	n.walk(func(obj pdfObject) {
+		walked = append(walked, obj)
+	})
+	if len(walked) != 2 || walked[0] != a || walked[1] != n.appearance {
+		t.Errorf("walk: unexpected objects: %v", walked)
+	}
+}
+
+func TestTextMarkup(t *testing.T) {
+	p := newTestPage()
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := p.Highlight(0, 0, 40, 10).(*textMarkup)
+	h.build(now)
+	if !strings.Contains(h.compile(), "/Subtype /Highlight /Rect [10 170 50 180]") {
+		t.Errorf("Highlight: unexpected compillation: %s", h.compile())
+	}
+	if !strings.Contains(h.compile(), "/QuadPoints [10 180 50 180 10 170 50 170]") {
+		t.Errorf("Highlight: unexpected quad points: %s", h.compile())
+	}
+	testRendering(t, "/GS0 gs\n", string(h.appearance.data[0]))
+	testRendering(t, "q 1.000000 1.000000 0.000000 rg 0 0 40 10 re f Q\n", string(h.appearance.data[1]))
+	if h.appearance.resources == nil || h.appearance.resources.extGState["/GS0"] == "" {
+		t.Error("Highlight: blend mode is not added to the resources")
+	}
+	u := p.Underline(0, 0, 40, 10).(*textMarkup)
+	u.build(now)
+	testRendering(t, "q 0 1 m 40 1 l 1 w 0.000000 0.000000 1.000000 RG S Q\n", string(u.appearance.data[0]))
+}
+
+func TestFreeText(t *testing.T) {
+	p := newTestPage()
+	f := newFontType1("/F0", "/Helvetica")
+	ft := p.FreeText(0, 0, 100, 20, f, 12, "text").(*freeText)
+	ft.build(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	if !strings.Contains(ft.compile(), "/Contents (text)") || !strings.Contains(ft.compile(), "/DA (/F0 12 Tf 0 g)") {
+		t.Errorf("FreeText: unexpected compillation: %s", ft.compile())
+	}
+	testRendering(t, f.createText(2, 6, 12, "text"), string(ft.appearance.data[0]))
+	if ft.appearance.resources.font["/F0"] != f {
+		t.Error("FreeText: font is not added to the resources")
+	}
+}
+
+func TestShape(t *testing.T) {
+	p := newTestPage()
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	sq := p.Square(0, 0, 40, 20, 2).(*shape)
+	sq.build(now)
+	testRendering(t, "2 w\n", string(sq.appearance.data[0]))
+	testRendering(t, "q 1.000000 0.000000 0.000000 RG 1 1 38 18 re S Q\n", string(sq.appearance.data[1]))
+	if !strings.Contains(sq.compile(), "/Subtype /Square") || !strings.Contains(sq.compile(), "/BS <</W 2 /S /S>>") {
+		t.Errorf("Square: unexpected compillation: %s", sq.compile())
+	}
+	c := p.Circle(0, 0, 40, 20, 2).(*shape)
+	c.build(now)
+	expected := "q 1.000000 0.000000 0.000000 RG 2 w " + ellipse(20, 10, 19, 9) + " S Q\n"
+	testRendering(t, expected, string(c.appearance.data[0]))
+}
+
+func TestEllipse(t *testing.T) {
+	actual := ellipse(0, 0, 1, 1)
+	if !strings.HasPrefix(actual, "1.000000 0.000000 m 1.000000 0.552285 0.552285 1.000000 0.000000 1.000000 c") {
+		t.Errorf("ellipse: unexpected path: %s", actual)
+	}
+	if strings.Count(actual, " c") != 4 {
+		t.Errorf("ellipse: the path must consist of 4 curves: %s", actual)
+	}
+}
+
+func TestInk(t *testing.T) {
+	p := newTestPage()
+	in := p.Ink(2, []int{10, 10, 20, 30, 30, 10}, []int{5, 40, 5, 50}).(*ink)
+	isEqualBox(t, 13, 128, 42, 172, in.rect)
+	testCompillation(t, "[[20 170 30 150 40 170] [15 140 15 130]]", in.inkList())
+	in.build(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	if len(in.appearance.data) != 2 {
+		t.Fatalf("Ink: appearance: expected 2 paths actual:%d", len(in.appearance.data))
+	}
+	testRendering(t, "q 7 42 m 17 22 l 27 42 l 2 w 0.000000 0.000000 1.000000 RG 1 J 1 j S Q\n", string(in.appearance.data[0]))
+}
+
+func TestBuilderAnnotations(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	b.SetReproducible(now)
+	p := b.AddPage()
+	n := p.Note(0, 0, "note").(*note)
+	p.Link(0, 0, 10, 10, LinkURI("https://example.com"))
+	if err := b.build(); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	if !n.creationDate.Equal(now) || !n.modDate.Equal(now) {
+		t.Error("build: dates of the annotation are not filled")
+	}
+	if n.appearance.refNo() == 0 {
+		t.Error("build: appearance stream is not numbered")
+	}
+}
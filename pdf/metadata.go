@@ -0,0 +1,166 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+const (
+	xmpNamespaceDC  = "http://purl.org/dc/elements/1.1/"
+	xmpNamespaceXMP = "http://ns.adobe.com/xap/1.0/"
+	xmpNamespacePDF = "http://ns.adobe.com/pdf/1.3/"
+)
+
+// xmpMetadata is a metadata in Extensible Metadata Platform (XMP).
+// It is written as a metadata stream referred from the document catalog.
+type xmpMetadata struct {
+	schemas []*xmpSchema
+}
+
+func newXMPMetadata() *xmpMetadata {
+	return &xmpMetadata{
+		schemas: make([]*xmpSchema, 0),
+	}
+}
+
+// newXMPMetadataFromInfo returns a XMP metadata whose values are the same as the document information dictionary.
+func newXMPMetadataFromInfo(info DocumentInfo) *xmpMetadata {
+	m := newXMPMetadata()
+	dc := m.schema("dc", xmpNamespaceDC)
+	dc.setRaw("format", "application/pdf")
+	if info.Title != "" {
+		dc.setRaw("title", xmpAlt(info.Title))
+	}
+	if info.Author != "" {
+		dc.setRaw("creator", xmpSeq(info.Author))
+	}
+	if info.Subject != "" {
+		dc.setRaw("description", xmpAlt(info.Subject))
+	}
+	x := m.schema("xmp", xmpNamespaceXMP)
+	if info.Creator != "" {
+		x.set("CreatorTool", info.Creator)
+	}
+	if !info.CreationDate.IsZero() {
+		x.set("CreateDate", xmpDate(info.CreationDate))
+	}
+	if !info.ModDate.IsZero() {
+		x.set("ModifyDate", xmpDate(info.ModDate))
+		x.set("MetadataDate", xmpDate(info.ModDate))
+	}
+	p := m.schema("pdf", xmpNamespacePDF)
+	if info.Keywords != "" {
+		p.set("Keywords", info.Keywords)
+	}
+	if info.Producer != "" {
+		p.set("Producer", info.Producer)
+	}
+	return m
+}
+
+// schema returns the schema identified by the namespace.
+// If the schema has not been created, creates new schema and returns it.
+func (m *xmpMetadata) schema(prefix, namespace string) *xmpSchema {
+	for _, s := range m.schemas {
+		if s.namespace == namespace {
+			return s
+		}
+	}
+	s := &xmpSchema{
+		prefix:     prefix,
+		namespace:  namespace,
+		properties: make([]xmpProperty, 0),
+	}
+	m.schemas = append(m.schemas, s)
+	return s
+}
+
+// bytes returns the serialized XMP packet.
+func (m *xmpMetadata) bytes() []byte {
+	var b bytes.Buffer
+	b.WriteString("<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString("<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	for _, s := range m.schemas {
+		if len(s.properties) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "<rdf:Description rdf:about=\"\" xmlns:%s=\"%s\">\n", s.prefix, s.namespace)
+		for _, p := range s.properties {
+			fmt.Fprintf(&b, "<%s:%s>%s</%s:%s>\n", s.prefix, p.name, p.value, s.prefix, p.name)
+		}
+		b.WriteString("</rdf:Description>\n")
+	}
+	b.WriteString("</rdf:RDF>\n")
+	b.WriteString("</x:xmpmeta>\n")
+	b.WriteString("<?xpacket end=\"w\"?>")
+	return b.Bytes()
+}
+
+// asStream returns the metadata stream.
+// Metadata stream is not compressed so that it can be read by applications which do not understand pdf.
+func (m *xmpMetadata) asStream() *stream {
+	s := newFlatStream()
+	s.dict["/Type"] = "/Metadata"
+	s.dict["/Subtype"] = "/XML"
+	s.addBinaryDatum(m.bytes())
+	return s
+}
+
+// xmpSchema is a set of properties in a namespace.
+type xmpSchema struct {
+	prefix     string
+	namespace  string
+	properties []xmpProperty
+}
+
+// xmpProperty is a XMP property whose value is serialized XML.
+type xmpProperty struct {
+	name  string
+	value string
+}
+
+// set sets the text value to the property.
+func (s *xmpSchema) set(name, value string) {
+	s.setRaw(name, xmlEscape(value))
+}
+
+// setRaw sets the serialized XML to the property.
+func (s *xmpSchema) setRaw(name, value string) {
+	for i, p := range s.properties {
+		if p.name == name {
+			s.properties[i].value = value
+			return
+		}
+	}
+	s.properties = append(s.properties, xmpProperty{name, value})
+}
+
+// xmpAlt returns a language alternative whose default is value.
+func xmpAlt(value string) string {
+	return fmt.Sprintf("<rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt>", xmlEscape(value))
+}
+
+// xmpSeq returns a ordered array of values.
+func xmpSeq(values ...string) string {
+	var b bytes.Buffer
+	b.WriteString("<rdf:Seq>")
+	for _, v := range values {
+		fmt.Fprintf(&b, "<rdf:li>%s</rdf:li>", xmlEscape(v))
+	}
+	b.WriteString("</rdf:Seq>")
+	return b.String()
+}
+
+// xmpDate returns the XMP expression of a date.
+func xmpDate(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
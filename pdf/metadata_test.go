@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXMPSchema(t *testing.T) {
+	m := newXMPMetadata()
+	s := m.schema("dc", xmpNamespaceDC)
+	if m.schema("dc", xmpNamespaceDC) != s {
+		t.Error("schema: schema is dup")
+	}
+	s.set("a", "1 < 2")
+	s.set("b", "x")
+	s.set("a", "3")
+	if len(s.properties) != 2 {
+		t.Fatalf("set: property size: expected:2 actual:%d", len(s.properties))
+	}
+	testCompillation(t, "3", s.properties[0].value)
+	s.set("a", "1 < 2")
+	testCompillation(t, "1 &lt; 2", s.properties[0].value)
+}
+
+func TestXMPMetadataFromInfo(t *testing.T) {
+	m := newXMPMetadataFromInfo(DocumentInfo{
+		Title:        "a & b",
+		Author:       "author",
+		Subject:      "subject",
+		Keywords:     "k1, k2",
+		Creator:      "creator",
+		Producer:     "producer",
+		CreationDate: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		ModDate:      time.Date(2018, 1, 2, 3, 4, 5, 0, time.FixedZone("JST", 9*60*60)),
+	})
+	actual := string(m.bytes())
+	expected := []string{
+		"<dc:format>application/pdf</dc:format>",
+		"<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">a &amp; b</rdf:li></rdf:Alt></dc:title>",
+		"<dc:creator><rdf:Seq><rdf:li>author</rdf:li></rdf:Seq></dc:creator>",
+		"<dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">subject</rdf:li></rdf:Alt></dc:description>",
+		"<xmp:CreatorTool>creator</xmp:CreatorTool>",
+		"<xmp:CreateDate>2018-01-02T03:04:05Z</xmp:CreateDate>",
+		"<xmp:ModifyDate>2018-01-02T03:04:05+09:00</xmp:ModifyDate>",
+		"<pdf:Keywords>k1, k2</pdf:Keywords>",
+		"<pdf:Producer>producer</pdf:Producer>",
+	}
+	for _, e := range expected {
+		if !strings.Contains(actual, e) {
+			t.Errorf("bytes: %s is not found in\n%s", e, actual)
+		}
+	}
+	if !strings.HasPrefix(actual, "<?xpacket begin=") || !strings.HasSuffix(actual, "<?xpacket end=\"w\"?>") {
+		t.Errorf("bytes: XMP packet wrapper is not found:\n%s", actual)
+	}
+	s := m.asStream()
+	if s.dict["/Type"] != "/Metadata" || s.dict["/Subtype"] != "/XML" {
+		t.Errorf("asStream: unexpected dictionary: %v", s.dict)
+	}
+	if _, ok := s.filter.(*flatEncoder); !ok {
+		t.Error("asStream: metadata stream must not be compressed")
+	}
+}
@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// nameTree is a name tree that maps strings to pdf objects.
+// The tree is written as a single root node whose keys are sorted.
+type nameTree struct {
+	objectIdentifier
+	names map[string]stringCompiler
+}
+
+func newNameTree() *nameTree {
+	return &nameTree{
+		objectIdentifier: objectIdentifier{},
+		names:            make(map[string]stringCompiler),
+	}
+}
+
+// add adds the value with the key.
+// The value is compiled when the tree is compiled.
+// If the key is already in the tree, returns an error.
+func (nt *nameTree) add(key string, value stringCompiler) error {
+	if _, ok := nt.names[key]; ok {
+		return fmt.Errorf("name tree: duplicate key: %s", key)
+	}
+	nt.names[key] = value
+	return nil
+}
+
+func (nt *nameTree) keys() []string {
+	keys := make([]string, 0, len(nt.names))
+	for k := range nt.names {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (nt *nameTree) compile() string {
+	names := make([]string, 0, len(nt.names))
+	for _, k := range nt.keys() {
+		names = append(names, fmt.Sprintf("%s %s", textString(k), nt.names[k].compile()))
+	}
+	return nt.bracket(fmt.Sprintf("<</Names [%s]>>", strings.Join(names, " ")))
+}
+
+// walk returns this tree and the values which are indirect objects in the order of the keys.
+func (nt *nameTree) walk(walker func(obj pdfObject)) {
+	if nt == nil {
+		return
+	}
+	walker(nt)
+	for _, k := range nt.keys() {
+		if t, ok := nt.names[k].(traversableObject); ok {
+			t.walk(walker)
+		}
+	}
+}
+
+// reference is a value of a name tree which refers to the indirect object.
+type reference struct {
+	obj pdfObject
+}
+
+func (r *reference) compile() string {
+	return r.obj.indirectReference()
+}
+
+func (r *reference) walk(walker func(obj pdfObject)) {
+	if t, ok := r.obj.(traversableObject); ok {
+		t.walk(walker)
+	} else {
+		walker(r.obj)
+	}
+}
+
+// names is a name dictionary of the document catalog.
+type names struct {
+	dests         *nameTree
+	embeddedFiles *nameTree
+}
+
+func newNames() *names {
+	return &names{
+		dests:         nil,
+		embeddedFiles: nil,
+	}
+}
+
+// isEmpty returns true if the name dictionary has no entry.
+func (n *names) isEmpty() bool {
+	return n.dests == nil && n.embeddedFiles == nil
+}
+
+func (n *names) compile() string {
+	dict := make([]string, 0, 2)
+	if n.dests != nil {
+		dict = append(dict, fmt.Sprintf("/Dests %s", n.dests.indirectReference()))
+	}
+	if n.embeddedFiles != nil {
+		dict = append(dict, fmt.Sprintf("/EmbeddedFiles %s", n.embeddedFiles.indirectReference()))
+	}
+	return fmt.Sprintf("<<%s>>", strings.Join(dict, " "))
+}
+
+func (n *names) walk(walker func(obj pdfObject)) {
+	n.dests.walk(walker)
+	n.embeddedFiles.walk(walker)
+}
@@ -0,0 +1,67 @@
+package pdf
+
+import "testing"
+
+type mockStringCompiler string
+
+func (s mockStringCompiler) compile() string {
+	return string(s)
+}
+
+func TestNameTree(t *testing.T) {
+	nt := newNameTree()
+	if nt.refNo() != 0 {
+		t.Error("nameTree is not initial state: refNo != 0")
+	}
+	if err := nt.add("b", mockStringCompiler("2")); err != nil {
+		t.Errorf("add: unexpected error:%s", err)
+	}
+	if err := nt.add("a", mockStringCompiler("1")); err != nil {
+		t.Errorf("add: unexpected error:%s", err)
+	}
+	if err := nt.add("a", mockStringCompiler("3")); err == nil {
+		t.Error("add: duplicate key must be error")
+	}
+	testCompillation(t, "0 0 obj\n<</Names [(a) 1 (b) 2]>>\nendobj\n", nt.compile())
+}
+
+func TestNames(t *testing.T) {
+	n := newNames()
+	if !n.isEmpty() {
+		t.Error("names is not initial state: not empty")
+	}
+	n.dests = newNameTree()
+	n.dests.objectNumber = 5
+	if n.isEmpty() {
+		t.Error("isEmpty: dests is not counted")
+	}
+	testCompillation(t, "<</Dests 5 0 R>>", n.compile())
+}
+
+func TestNameTreeReference(t *testing.T) {
+	nt := newNameTree()
+	nt.objectNumber = 3
+	b := &objectIdentifier{5, 0}
+	a := newEmbeddedFile("a.txt", "", []byte("a"))
+	a.objectNumber = 6
+	nt.add("b", &reference{b})
+	nt.add("a", &reference{a})
+	testCompillation(t, "3 0 obj\n<</Names [(a) 6 0 R (b) 5 0 R]>>\nendobj\n", nt.compile())
+	walked := make([]pdfObject, 0)
+	nt.walk(func(obj pdfObject) {
+		walked = append(walked, obj)
+	})
+	if len(walked) != 4 || walked[0] != nt || walked[1] != a || walked[2] != a.stream || walked[3] != b {
+		t.Errorf("walk: unexpected objects: %v", walked)
+	}
+}
+
+func TestNamesEmbeddedFiles(t *testing.T) {
+	n := newNames()
+	n.embeddedFiles = newNameTree()
+	n.embeddedFiles.objectNumber = 7
+	if n.isEmpty() {
+		t.Error("isEmpty: embedded files are not counted")
+	}
+	testCompillation(t, "<</EmbeddedFiles 7 0 R>>", n.compile())
+}
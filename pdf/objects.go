@@ -14,6 +14,8 @@ type pdfObject interface {
 	indirectReference() string
 	// number sets the own object number by using object number counter.
 	number(c *counter)
+	// renumber replaces the own object number, which has been set by number.
+	renumber(n int)
 }
 
 // stringCompiler compiles itself and returns string.
@@ -36,6 +38,13 @@ type binaryObject interface {
 	binaryCompiler
 }
 
+// encryptableObject compiles itself with its strings and data encrypted.
+type encryptableObject interface {
+	pdfObject
+	// compileWith compiles itself with encryption, or compiles it without encryption if e is nil.
+	compileWith(e *encryption) ([]byte, error)
+}
+
 // traversableObject returns itself and descendant objects recursively.
 // walker function traverse it.
 type traversableObject interface {
@@ -69,6 +78,11 @@ func (obj *objectIdentifier) number(c *counter) {
 	}
 }
 
+func (obj *objectIdentifier) renumber(n int) {
+	obj.objectNumber = n
+	obj.generationNumber = 0
+}
+
 // bracket is a utility function to compile own object expression.
 func (obj *objectIdentifier) bracket(value string) string {
 	return fmt.Sprintf("%d %d obj\n%s\nendobj\n", obj.objectNumber, obj.generationNumber, value)
@@ -12,6 +12,9 @@ type Outline interface {
 	traversableObject
 	// AddItem adds a new Outline to its children.
 	AddItem(title string, page Page, destType OutlineDestination) (o Outline)
+	// AddNamedItem adds a new Outline which refers the named destination to its children.
+	// The destination must be registered by Page.AddDestination, otherwise Build returns an error.
+	AddNamedItem(title string, name string) (o Outline)
 }
 
 // outline is a root node of a document outline tree.
@@ -37,6 +40,13 @@ func (o *outline) AddItem(title string, page Page, destType OutlineDestination)
 	return ol
 }
 
+// AddNamedItem adds the new OutlineItem which refers the named destination to Outline
+func (o *outline) AddNamedItem(title string, name string) Outline {
+	oi := o.AddItem(title, nil, nil).(*outlineItem)
+	oi.destName = name
+	return oi
+}
+
 func (o *outline) compile() string {
 	return o.bracket(fmt.Sprintf(
 		"<</Type /Outlines /First %s /Last %s>>",
@@ -62,6 +72,7 @@ type outlineItem struct {
 	first    *outlineItem
 	page     Page
 	destType OutlineDestination
+	destName string
 }
 
 func newOutlineItem(title string, parent Outline, prev *outlineItem, page Page, destType OutlineDestination) *outlineItem {
@@ -89,6 +100,13 @@ func (oi *outlineItem) AddItem(title string, page Page, destType OutlineDestinat
 	return child
 }
 
+// AddNamedItem adds the new child OutlineItem which refers the named destination to OutlineItem
+func (oi *outlineItem) AddNamedItem(title string, name string) Outline {
+	child := oi.AddItem(title, nil, nil).(*outlineItem)
+	child.destName = name
+	return child
+}
+
 func (oi *outlineItem) lastItem() *outlineItem {
 	if oi.next != nil {
 		return oi.next.lastItem()
@@ -100,7 +118,11 @@ func (oi *outlineItem) compile() string {
 	dict := make([]string, 3, 7)
 	dict[0] = fmt.Sprintf("/Title (%s)", oi.title)
 	dict[1] = fmt.Sprintf("/Parent %s", oi.parent.indirectReference())
-	dict[2] = fmt.Sprintf("/Dest [%s %s]", oi.page.indirectReference(), oi.destType.compile())
+	if oi.destName != "" {
+		dict[2] = fmt.Sprintf("/Dest %s", textString(oi.destName))
+	} else {
+		dict[2] = fmt.Sprintf("/Dest %s", explicitDestination(oi.page, oi.destType))
+	}
 	if oi.prev != nil {
 		dict = append(dict, fmt.Sprintf("/Prev %s", oi.prev.indirectReference()))
 	}
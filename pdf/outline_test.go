@@ -156,3 +156,21 @@ func TestOutline(t *testing.T) {
 		}
 	}
 }
+
+func TestOutlineNamedItem(t *testing.T) {
+	o := newOutline()
+	o.objectNumber = 1
+	ol1 := o.AddNamedItem("first", "chapter-1")
+	il1 := ol1.(*outlineItem)
+	il1.objectNumber = 2
+	ol2 := ol1.AddNamedItem("second", "chapter-2")
+	il2 := ol2.(*outlineItem)
+	il2.objectNumber = 3
+	if il1.first != il2 || il2.parent != il1 {
+		t.Error("AddNamedItem: is item not added?")
+	}
+	expected1 := "2 0 obj\n<</Title (first) /Parent 1 0 R /Dest (chapter-1) /First 3 0 R /Last 3 0 R>>\nendobj\n"
+	testCompillation(t, expected1, il1.compile())
+	expected2 := "3 0 obj\n<</Title (second) /Parent 2 0 R /Dest (chapter-2)>>\nendobj\n"
+	testCompillation(t, expected2, il2.compile())
+}
@@ -2,6 +2,7 @@ package pdf
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -23,12 +24,108 @@ type Page interface {
 	Line(startX, startY, endX, endY, lineWidth int) Line
 	// Image adds the image to this page.
 	Image(i *ImageResource, centerX, centerY float64) Image
+	// AddDestination registers the named destination which locates the coordinate of this page.
+	AddDestination(name string, x, y int)
+	// Link adds a link annotation to this page.
+	Link(startX, startY, width, height int, target LinkTarget) Link
+	// Note adds a text annotation, that is a sticky note, to this page.
+	Note(x, y int, contents string) MarkupAnnotation
+	// Highlight adds a highlight annotation to this page.
+	Highlight(startX, startY, width, height int) MarkupAnnotation
+	// Underline adds an underline annotation to this page.
+	Underline(startX, startY, width, height int) MarkupAnnotation
+	// FreeText adds a free text annotation to this page.
+	FreeText(startX, startY, width, height int, font Font, fontSize int, text string) MarkupAnnotation
+	// Square adds a square annotation to this page.
+	Square(startX, startY, width, height, lineWidth int) MarkupAnnotation
+	// Circle adds a circle annotation to this page.
+	Circle(startX, startY, width, height, lineWidth int) MarkupAnnotation
+	// FileAttachment adds a file attachment annotation, which shows the icon of the file, to this page.
+	// The file must be attached by Builder.AttachFile, and if it is nil, Build returns an error.
+	FileAttachment(x, y int, file EmbeddedFile) MarkupAnnotation
+	// Ink adds an ink annotation to this page.
+	// Each path consists of the points such as x1, y1, x2, y2...
+	Ink(lineWidth int, paths ...[]int) MarkupAnnotation
+	// TextField adds a text field of the interactive form to this page.
+	// Dotted names such as "address.city" make the field hierarchy.
+	TextField(name string, startX, startY, width, height int) TextField
+	// CheckBox adds a check box of the interactive form to this page.
+	CheckBox(name string, startX, startY, width, height int) CheckBox
+	// RadioButton adds a radio button of the interactive form to this page.
+	// Radio buttons of the same name form a group, and value identifies the button in the group.
+	RadioButton(name, value string, startX, startY, width, height int) RadioButton
+	// ComboBox adds a combo box of the interactive form to this page.
+	ComboBox(name string, startX, startY, width, height int) ChoiceField
+	// ListBox adds a list box of the interactive form to this page.
+	ListBox(name string, startX, startY, width, height int) ChoiceField
+	// SignatureField adds a signature field of the interactive form to this page.
+	// If width or height is zero, the signature is invisible.
+	SignatureField(name string, startX, startY, width, height int) SignatureField
+	// SetTrimBox specifies the intended dimensions of the finished page after trimming.
+	SetTrimBox(box *Box)
+	// SetBleedBox specifies the region to which the contents of the page are clipped in a production environment.
+	// It includes the bleed area needed to accommodate the inaccuracy of the trimming.
+	SetBleedBox(box *Box)
+	// SetArtBox specifies the extent of the meaningful contents of the page.
+	SetArtBox(box *Box)
+	// SetRotate specifies the clockwise rotation of the displayed page, which must be a multiple of 90 degrees.
+	SetRotate(degrees int)
+	// SetUserUnit specifies the size of the unit of the coordinates of the page in multiples of 1/72 inch.
+	SetUserUnit(unit float64)
+	// SetTransition specifies the transition effect used when moving to this page during a presentation.
+	SetTransition(t Transition)
+	// SetDuration specifies the display duration of this page in seconds during a presentation.
+	// The viewer advances to the next page automatically after the duration.
+	SetDuration(seconds float64)
+	// BeginStructure begins the marked content which belongs to the structure element.
+	// Contents written until EndMarkedContent is called are the part of the element.
+	// If the element is nil, Build returns an error.
+	BeginStructure(e StructureElement)
+	// BeginArtifact begins the marked content which is an artifact, such as a decoration or a page number.
+	// Artifacts are not the part of the logical structure, and are ignored by assistive technologies.
+	BeginArtifact()
+	// BeginLayer begins the marked content which belongs to the layer.
+	// Contents written until EndMarkedContent is called are shown or hidden with the layer.
+	// If the layer is nil, Build returns an error.
+	BeginLayer(l Layer)
+	// EndMarkedContent ends the marked content begun most recently.
+	EndMarkedContent()
 	render(obj GraphicsObject)
+	// destinations returns the named destinations registered on this page.
+	destinations() []*namedDestination
+	// annotations returns the annotations on this page.
+	annotations() []Annotation
+}
+
+// markedContent is a marked content which has not been ended.
+type markedContent struct {
+	// element is the structure element of the marked content, or nil.
+	element *structElem
+	// artifact is true if the marked content is an artifact.
+	artifact bool
 }
 
 type page struct {
 	pageNode
 	contents *stream
+	dests    []*namedDestination
+	annots   []Annotation
+	// mcids are the structure elements of the marked contents, indexed by their MCID.
+	mcids []*structElem
+	// markedContents is the stack of the marked contents which have not been ended.
+	markedContents []markedContent
+	// structParents is the key of the page in the parent tree.
+	structParents int
+	// untaggedContents is the number of the contents which are neither in structure elements nor in artifacts.
+	untaggedContents int
+	// untaggedFigures is the number of the figures which are not in figure structure elements.
+	untaggedFigures int
+	// errs are the errors of the invalid arguments, which are returned by Build.
+	errs []string
+	// transition is the transition effect to this page, or nil.
+	transition *Transition
+	// duration is the display duration in seconds, or 0 if the page is not advanced automatically.
+	duration float64
 }
 
 // AddFont adds the font to this page.
@@ -50,6 +147,7 @@ func (p *page) AddImage(i *ImageResource) {
 }
 
 func (p *page) WriteText(x, y int, font Font, fontSize int, text string) {
+	p.countUntagged()
 	p.addStringContent(p.text(x, y, font, fontSize, text))
 }
 
@@ -65,6 +163,128 @@ func (p *page) Image(i *ImageResource, centerX, centerY float64) Image {
 	return newImage(p, i, centerX, centerY)
 }
 
+func (p *page) AddDestination(name string, x, y int) {
+	p.dests = append(p.dests, newNamedDestination(name, p, p.cb().leftBottomX+x, p.cb().rightTopY-y))
+}
+
+func (p *page) destinations() []*namedDestination {
+	return p.dests
+}
+
+func (p *page) Link(startX, startY, width, height int, target LinkTarget) Link {
+	l := newLink(p, p.rect(startX, startY, width, height), target)
+	p.addAnnotation(l)
+	return l
+}
+
+func (p *page) Note(x, y int, contents string) MarkupAnnotation {
+	n := newNote(p, p.rect(x, y, noteIconSize, noteIconSize), contents)
+	p.addAnnotation(n)
+	return n
+}
+
+func (p *page) FileAttachment(x, y int, file EmbeddedFile) MarkupAnnotation {
+	var ef *embeddedFile
+	if file != nil {
+		ef = file.file()
+	}
+	if ef == nil {
+		// The annotation of the empty file is returned, and is not added to the page.
+		p.errs = append(p.errs, "file attachment: embedded file is not specified")
+		return newFileAttachment(p, p.rect(x, y, noteIconSize, noteIconSize), newEmbeddedFile("", "", nil))
+	}
+	fa := newFileAttachment(p, p.rect(x, y, noteIconSize, noteIconSize), ef)
+	p.addAnnotation(fa)
+	return fa
+}
+
+func (p *page) Highlight(startX, startY, width, height int) MarkupAnnotation {
+	tm := newTextMarkup("/Highlight", p, p.rect(startX, startY, width, height))
+	p.addAnnotation(tm)
+	return tm
+}
+
+func (p *page) Underline(startX, startY, width, height int) MarkupAnnotation {
+	tm := newTextMarkup("/Underline", p, p.rect(startX, startY, width, height))
+	p.addAnnotation(tm)
+	return tm
+}
+
+func (p *page) FreeText(startX, startY, width, height int, font Font, fontSize int, text string) MarkupAnnotation {
+	ft := newFreeText(p, p.rect(startX, startY, width, height), font, fontSize, text)
+	p.addAnnotation(ft)
+	return ft
+}
+
+func (p *page) Square(startX, startY, width, height, lineWidth int) MarkupAnnotation {
+	s := newShape("/Square", p, p.rect(startX, startY, width, height), lineWidth)
+	p.addAnnotation(s)
+	return s
+}
+
+func (p *page) Circle(startX, startY, width, height, lineWidth int) MarkupAnnotation {
+	s := newShape("/Circle", p, p.rect(startX, startY, width, height), lineWidth)
+	p.addAnnotation(s)
+	return s
+}
+
+func (p *page) Ink(lineWidth int, paths ...[]int) MarkupAnnotation {
+	in := newInk(p, p.cb(), lineWidth, paths)
+	p.addAnnotation(in)
+	return in
+}
+
+func (p *page) TextField(name string, startX, startY, width, height int) TextField {
+	tf := newTextField(name, p, p.rect(startX, startY, width, height))
+	p.addAnnotation(tf)
+	return tf
+}
+
+func (p *page) CheckBox(name string, startX, startY, width, height int) CheckBox {
+	cb := newCheckBox(name, p, p.rect(startX, startY, width, height))
+	p.addAnnotation(cb)
+	return cb
+}
+
+func (p *page) RadioButton(name, value string, startX, startY, width, height int) RadioButton {
+	rb := newRadioButton(name, value, p, p.rect(startX, startY, width, height))
+	p.addAnnotation(rb)
+	return rb
+}
+
+func (p *page) ComboBox(name string, startX, startY, width, height int) ChoiceField {
+	cf := newChoiceField(name, p, p.rect(startX, startY, width, height), true)
+	p.addAnnotation(cf)
+	return cf
+}
+
+func (p *page) ListBox(name string, startX, startY, width, height int) ChoiceField {
+	cf := newChoiceField(name, p, p.rect(startX, startY, width, height), false)
+	p.addAnnotation(cf)
+	return cf
+}
+
+func (p *page) SignatureField(name string, startX, startY, width, height int) SignatureField {
+	sf := newSignatureField(name, p, p.rect(startX, startY, width, height))
+	p.addAnnotation(sf)
+	return sf
+}
+
+// addAnnotation adds the annotation to this page.
+func (p *page) addAnnotation(a Annotation) {
+	p.annots = append(p.annots, a)
+}
+
+func (p *page) annotations() []Annotation {
+	return p.annots
+}
+
+// rect returns the box whose upper-left corner is located at the coordinate of this page.
+func (p *page) rect(startX, startY, width, height int) *Box {
+	cb := p.cb()
+	return NewBox(cb.leftBottomX+startX, cb.rightTopY-startY-height, cb.leftBottomX+startX+width, cb.rightTopY-startY)
+}
+
 // newPage creates Page.
 func newPage(pl *pageList, mb *Box, cb *Box, r *resource) *page {
 	return &page{
@@ -79,12 +299,113 @@ func newPage(pl *pageList, mb *Box, cb *Box, r *resource) *page {
 	}
 }
 
+// inherit copies the attributes inherited from the ancestors into this page,
+// so that the page is displayed without reading its ancestors.
+func (p *page) inherit() {
+	p.mediaBox = p.mb()
+	p.cropBox = p.cb()
+	for pl := p.parent; p.resource == nil && pl != nil; pl = pl.parent {
+		p.resource = pl.resource
+	}
+	if r := p.rot(); p.rotate == nil && r != 0 {
+		p.rotate = &r
+	}
+}
+
+func (p *page) SetTransition(t Transition) {
+	p.transition = &t
+}
+
+func (p *page) SetDuration(seconds float64) {
+	p.duration = seconds
+}
+
+func (p *page) BeginStructure(e StructureElement) {
+	var se *structElem
+	if e != nil {
+		se = e.element()
+	}
+	if se == nil {
+		p.errs = append(p.errs, "tagged: structure element is not specified")
+		p.markedContents = append(p.markedContents, markedContent{})
+		return
+	}
+	mcid := len(p.mcids)
+	p.mcids = append(p.mcids, se)
+	se.kids = append(se.kids, markedContentReference{page: p, mcid: mcid})
+	p.markedContents = append(p.markedContents, markedContent{element: se})
+	p.addStringContent(fmt.Sprintf("%s <</MCID %d>> BDC\n", se.role.compile(), mcid))
+}
+
+func (p *page) BeginArtifact() {
+	p.markedContents = append(p.markedContents, markedContent{artifact: true})
+	p.addStringContent("/Artifact BMC\n")
+}
+
+func (p *page) BeginLayer(l Layer) {
+	var ocg *optionalContentGroup
+	if l != nil {
+		ocg = l.group()
+	}
+	if ocg == nil {
+		p.errs = append(p.errs, "layer: layer is not specified")
+		p.markedContents = append(p.markedContents, markedContent{})
+		return
+	}
+	// The default resource has all layers.
+	if p.resource != nil {
+		p.resource.addProperties(ocg.resourceName, ocg)
+	}
+	p.markedContents = append(p.markedContents, markedContent{})
+	p.addStringContent(fmt.Sprintf("/OC %s BDC\n", ocg.resourceName))
+}
+
+func (p *page) EndMarkedContent() {
+	if len(p.markedContents) == 0 {
+		return
+	}
+	p.markedContents = p.markedContents[:len(p.markedContents)-1]
+	p.addStringContent("EMC\n")
+}
+
 func (p *page) setParent(pl *pageList) {
 	p.parent = pl
 }
 
 func (p *page) render(obj GraphicsObject) {
-	p.contents.addStringDatum(obj.render(p.cb()))
+	p.countUntagged()
+	content := obj.render(p.cb())
+	if i, ok := obj.(*image); ok && i.alt != "" {
+		content = p.figure(i.alt, content)
+	}
+	p.contents.addStringDatum(content)
+}
+
+// figure gives the alternate description to the content of a figure.
+// In a figure structure element, the description is given to the element.
+// Otherwise, the content is marked as a figure which has the description,
+// and it is an error in the tagged document since the figure is not in the logical structure.
+func (p *page) figure(alt string, content string) string {
+	if n := len(p.markedContents); n > 0 {
+		if se := p.markedContents[n-1].element; se != nil && se.role == StructureRoleFigure {
+			if se.alt == "" {
+				se.alt = alt
+			}
+			return content
+		}
+	}
+	p.untaggedFigures++
+	return fmt.Sprintf("/Figure <</Alt %s>> BDC\n%sEMC\n", textString(alt), content)
+}
+
+// countUntagged counts the content written outside structure elements and artifacts.
+func (p *page) countUntagged() {
+	for _, mc := range p.markedContents {
+		if mc.element != nil || mc.artifact {
+			return
+		}
+	}
+	p.untaggedContents++
 }
 
 // addStringContent adds content whoose type is string.
@@ -94,7 +415,7 @@ func (p *page) addStringContent(content string) {
 
 // asPDF is the pdf object expression of this Page node.
 func (p *page) compile() string {
-	list := make([]string, 0, 5)
+	list := make([]string, 0, 15)
 	list = append(list, fmt.Sprintf("/Type /Page /Parent %s", p.parent.indirectReference()))
 	if p.mediaBox != nil {
 		list = append(list, fmt.Sprintf("/MediaBox %s", p.mediaBox.compile()))
@@ -102,10 +423,30 @@ func (p *page) compile() string {
 	if p.cropBox != nil {
 		list = append(list, fmt.Sprintf("/CropBox %s", p.cropBox.compile()))
 	}
+	list = append(list, p.compileBoxes()...)
+	if r := p.compileRotate(); r != "" {
+		list = append(list, r)
+	}
 	if p.resource != nil {
 		list = append(list, fmt.Sprintf("/Resources %s", p.resource.indirectReference()))
 	}
+	if len(p.mcids) > 0 {
+		list = append(list, fmt.Sprintf("/StructParents %d", p.structParents))
+	}
+	if p.transition != nil {
+		list = append(list, fmt.Sprintf("/Trans %s", p.transition.compile()))
+	}
+	if p.duration > 0 {
+		list = append(list, fmt.Sprintf("/Dur %s", strconv.FormatFloat(p.duration, 'f', -1, 64)))
+	}
 	list = append(list, fmt.Sprintf("/Contents [%s]", p.contents.indirectReference()))
+	if len(p.annots) > 0 {
+		annots := make([]string, 0, len(p.annots))
+		for _, a := range p.annots {
+			annots = append(annots, a.indirectReference())
+		}
+		list = append(list, fmt.Sprintf("/Annots [%s]", strings.Join(annots, " ")))
+	}
 	return p.bracket(fmt.Sprintf("<<%s>>", strings.Join(list, " ")))
 }
 
@@ -113,6 +454,9 @@ func (p *page) walk(walker func(obj pdfObject)) {
 	walker(p)
 	p.resource.walk(walker)
 	walker(p.contents)
+	for _, a := range p.annots {
+		a.walk(walker)
+	}
 }
 
 func (p *page) text(x, y int, font Font, fontSize int, text string) string {
@@ -1,6 +1,9 @@
 package pdf
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 type mockPage struct {
 	objectIdentifier
@@ -23,9 +26,48 @@ func (p *mockPage) WriteText(x, y int, font Font, fontSize int, text string) {}
 func (p *mockPage) Rectangle(startX, startY, width, height int) Rectangle    { return &mockRectangle{} }
 func (p *mockPage) Line(startX, startY, endX, endY, lineWidth int) Line      { return &mockLine{} }
 func (p *mockPage) Image(i *ImageResource, centerX, centerY float64) Image   { return &mockImage{} }
+func (p *mockPage) AddDestination(name string, x, y int)                     {}
 func (p *mockPage) render(obj GraphicsObject) {
 	p.renderResult = obj
 }
+func (p *mockPage) Link(startX, startY, width, height int, target LinkTarget) Link {
+	return newLink(p, NewBox(startX, startY, startX+width, startY+height), target)
+}
+func (p *mockPage) Note(x, y int, contents string) MarkupAnnotation              { return nil }
+func (p *mockPage) Highlight(startX, startY, width, height int) MarkupAnnotation { return nil }
+func (p *mockPage) Underline(startX, startY, width, height int) MarkupAnnotation { return nil }
+func (p *mockPage) FreeText(startX, startY, width, height int, font Font, fontSize int, text string) MarkupAnnotation {
+	return nil
+}
+func (p *mockPage) Square(startX, startY, width, height, lineWidth int) MarkupAnnotation { return nil }
+func (p *mockPage) Circle(startX, startY, width, height, lineWidth int) MarkupAnnotation { return nil }
+func (p *mockPage) Ink(lineWidth int, paths ...[]int) MarkupAnnotation                   { return nil }
+func (p *mockPage) TextField(name string, startX, startY, width, height int) TextField {
+	return nil
+}
+func (p *mockPage) CheckBox(name string, startX, startY, width, height int) CheckBox { return nil }
+func (p *mockPage) RadioButton(name, value string, startX, startY, width, height int) RadioButton {
+	return nil
+}
+func (p *mockPage) ComboBox(name string, startX, startY, width, height int) ChoiceField { return nil }
+func (p *mockPage) ListBox(name string, startX, startY, width, height int) ChoiceField  { return nil }
+func (p *mockPage) SignatureField(name string, startX, startY, width, height int) SignatureField {
+	return nil
+}
+func (p *mockPage) FileAttachment(x, y int, file EmbeddedFile) MarkupAnnotation { return nil }
+func (p *mockPage) SetTrimBox(box *Box)                                         {}
+func (p *mockPage) SetArtBox(box *Box)                                          {}
+func (p *mockPage) SetRotate(degrees int)                                       {}
+func (p *mockPage) SetUserUnit(unit float64)                                    {}
+func (p *mockPage) SetBleedBox(box *Box)                                        {}
+func (p *mockPage) BeginStructure(e StructureElement)                           {}
+func (p *mockPage) BeginArtifact()                                              {}
+func (p *mockPage) SetTransition(t Transition)                                  {}
+func (p *mockPage) SetDuration(seconds float64)                                 {}
+func (p *mockPage) BeginLayer(l Layer)                                          {}
+func (p *mockPage) EndMarkedContent()                                           {}
+func (p *mockPage) destinations() []*namedDestination                           { return nil }
+func (p *mockPage) annotations() []Annotation                                   { return nil }
 
 func TestPage1(t *testing.T) {
 	pmb := NewBox(1, 2, 3, 4)
@@ -41,3 +83,13 @@ func TestPage1(t *testing.T) {
 		t.Error("page is not initial state: resource is not costructor argument")
 	}
 }
+
+func TestPageBoxes(t *testing.T) {
+	p := newTestPage()
+	p.SetTrimBox(NewBox(20, 30, 80, 170))
+	p.SetBleedBox(NewBox(15, 25, 85, 175))
+	actual := p.compile()
+	if !strings.Contains(actual, "/BleedBox [15 25 85 175] /TrimBox [20 30 80 170]") {
+		t.Errorf("compile: boxes are not found:\n%s", actual)
+	}
+}
@@ -0,0 +1,146 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PageLabelStyle is a numbering style of page labels.
+type PageLabelStyle int
+
+const (
+	// PageLabelNone : Page labels consist solely of a label prefix with no numeric portion.
+	PageLabelNone PageLabelStyle = iota
+	// PageLabelDecimal : Decimal arabic numerals.
+	PageLabelDecimal
+	// PageLabelRomanUpper : Uppercase roman numerals.
+	PageLabelRomanUpper
+	// PageLabelRomanLower : Lowercase roman numerals.
+	PageLabelRomanLower
+	// PageLabelLettersUpper : Uppercase letters (A to Z for the first 26 pages, AA to ZZ for the next 26, and so on).
+	PageLabelLettersUpper
+	// PageLabelLettersLower : Lowercase letters (a to z for the first 26 pages, aa to zz for the next 26, and so on).
+	PageLabelLettersLower
+)
+
+func (s PageLabelStyle) compile() string {
+	switch s {
+	case PageLabelDecimal:
+		return "/D"
+	case PageLabelRomanUpper:
+		return "/R"
+	case PageLabelRomanLower:
+		return "/r"
+	case PageLabelLettersUpper:
+		return "/A"
+	case PageLabelLettersLower:
+		return "/a"
+	default:
+		return ""
+	}
+}
+
+// pageLabelRange is a page label dictionary.
+// It applies to the page and the following pages until the next range begins.
+type pageLabelRange struct {
+	// page is the first page of the range, or nil if the index is resolved without the page.
+	page   Page
+	index  int
+	style  PageLabelStyle
+	prefix string
+	start  int
+}
+
+func (r *pageLabelRange) compile() string {
+	dict := make([]string, 0, 3)
+	if s := r.style.compile(); s != "" {
+		dict = append(dict, fmt.Sprintf("/S %s", s))
+	}
+	if r.prefix != "" {
+		dict = append(dict, fmt.Sprintf("/P %s", textString(r.prefix)))
+	}
+	if r.start > 1 {
+		dict = append(dict, fmt.Sprintf("/St %d", r.start))
+	}
+	return fmt.Sprintf("<<%s>>", strings.Join(dict, " "))
+}
+
+// pageLabels is a number tree that defines the page labelling for the document.
+// The tree is written as a single root node.
+type pageLabels struct {
+	ranges []*pageLabelRange
+}
+
+func newPageLabels() *pageLabels {
+	return &pageLabels{
+		ranges: make([]*pageLabelRange, 0),
+	}
+}
+
+// add adds the label range beginning with the page.
+// The range previously added for the same page is replaced.
+func (pls *pageLabels) add(page Page, style PageLabelStyle, prefix string, start int) {
+	r := &pageLabelRange{
+		page:   page,
+		style:  style,
+		prefix: prefix,
+		start:  start,
+	}
+	for i, old := range pls.ranges {
+		if old.page == page {
+			pls.ranges[i] = r
+			return
+		}
+	}
+	pls.ranges = append(pls.ranges, r)
+}
+
+// resolve resolves page indices of label ranges, and sorts them by the index.
+// The pages follow offset pages, whose ranges have been resolved by resolvePage.
+// If the first page is not labeled, decimal arabic numerals are used for it.
+func (pls *pageLabels) resolve(pages []Page, offset int) error {
+	indices := make(map[Page]int, len(pages))
+	for i, p := range pages {
+		indices[p] = offset + i
+	}
+	hasFirst := false
+	for _, r := range pls.ranges {
+		if r.page != nil {
+			i, ok := indices[r.page]
+			if !ok {
+				return fmt.Errorf("page label: page is not in the document")
+			}
+			r.index = i
+		}
+		if r.index == 0 {
+			hasFirst = true
+		}
+	}
+	if !hasFirst && offset+len(pages) > 0 {
+		pls.ranges = append(pls.ranges, &pageLabelRange{index: 0, style: PageLabelDecimal})
+	}
+	sort.SliceStable(pls.ranges, func(i, j int) bool {
+		return pls.ranges[i].index < pls.ranges[j].index
+	})
+	return nil
+}
+
+// resolvePage resolves the index of the range beginning with the page written by the streaming builder.
+// The range no longer refers to the page, so that the page is released from the memory.
+func (pls *pageLabels) resolvePage(p Page, index int) {
+	for _, r := range pls.ranges {
+		if r.page == p {
+			r.page = nil
+			r.index = index
+		}
+	}
+}
+
+func (pls *pageLabels) compile() string {
+	nums := make([]string, 0, len(pls.ranges))
+	for _, r := range pls.ranges {
+		nums = append(nums, fmt.Sprintf("%d %s", r.index, r.compile()))
+	}
+	return fmt.Sprintf("<</Nums [%s]>>", strings.Join(nums, " "))
+}
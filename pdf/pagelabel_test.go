@@ -0,0 +1,50 @@
+package pdf
+
+import "testing"
+
+func TestPageLabelStyle(t *testing.T) {
+	styles := map[PageLabelStyle]string{
+		PageLabelNone:         "",
+		PageLabelDecimal:      "/D",
+		PageLabelRomanUpper:   "/R",
+		PageLabelRomanLower:   "/r",
+		PageLabelLettersUpper: "/A",
+		PageLabelLettersLower: "/a",
+	}
+	for s, expected := range styles {
+		testCompillation(t, expected, s.compile())
+	}
+}
+
+func TestPageLabels(t *testing.T) {
+	pages := []Page{&mockPage{}, &mockPage{}, &mockPage{}, &mockPage{}}
+	pls := newPageLabels()
+	pls.add(pages[2], PageLabelDecimal, "", 1)
+	pls.add(pages[0], PageLabelRomanLower, "", 1)
+	pls.add(pages[3], PageLabelLettersUpper, "A-", 3)
+	pls.add(pages[0], PageLabelRomanUpper, "", 1)
+	if err := pls.resolve(pages, 0); err != nil {
+		t.Fatalf("resolve: unexpected error:%s", err)
+	}
+	expected := "<</Nums [0 <</S /R>> 2 <</S /D>> 3 <</S /A /P (A-) /St 3>>]>>"
+	testCompillation(t, expected, pls.compile())
+}
+
+func TestPageLabelsFirstPage(t *testing.T) {
+	pages := []Page{&mockPage{}, &mockPage{}}
+	pls := newPageLabels()
+	pls.add(pages[1], PageLabelNone, "cover", 1)
+	if err := pls.resolve(pages, 0); err != nil {
+		t.Fatalf("resolve: unexpected error:%s", err)
+	}
+	expected := "<</Nums [0 <</S /D>> 1 <</P (cover)>>]>>"
+	testCompillation(t, expected, pls.compile())
+}
+
+func TestPageLabelsUnknownPage(t *testing.T) {
+	pls := newPageLabels()
+	pls.add(&mockPage{}, PageLabelDecimal, "", 1)
+	if err := pls.resolve([]Page{&mockPage{}}, 0); err == nil {
+		t.Error("resolve: page which is not in the document must be error")
+	}
+}
@@ -2,6 +2,7 @@ package pdf
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -12,6 +13,40 @@ type pageNode struct {
 	mediaBox *Box
 	cropBox  *Box
 	resource *resource
+	bleedBox *Box
+	trimBox  *Box
+	artBox   *Box
+	// rotate is the clockwise rotation in degrees of the displayed pages, or nil.
+	rotate *int
+	// userUnit is the size of the default user space unit in multiples of 1/72 inch, or 0.
+	userUnit float64
+}
+
+// SetBleedBox specifies the region to which the contents of the pages are clipped in a production environment.
+// It includes the bleed area needed to accommodate the inaccuracy of the trimming.
+func (pt *pageNode) SetBleedBox(box *Box) {
+	pt.bleedBox = box
+}
+
+// SetTrimBox specifies the intended dimensions of the finished pages after trimming.
+func (pt *pageNode) SetTrimBox(box *Box) {
+	pt.trimBox = box
+}
+
+// SetArtBox specifies the extent of the meaningful contents of the pages.
+func (pt *pageNode) SetArtBox(box *Box) {
+	pt.artBox = box
+}
+
+// SetRotate specifies the clockwise rotation of the displayed pages, which must be a multiple of 90 degrees.
+func (pt *pageNode) SetRotate(degrees int) {
+	pt.rotate = &degrees
+}
+
+// SetUserUnit specifies the size of the unit of the coordinates of the pages in multiples of 1/72 inch.
+// It enables the pages larger than 200 inches, such as posters.
+func (pt *pageNode) SetUserUnit(unit float64) {
+	pt.userUnit = unit
 }
 
 // cb is the effective cropbox in this page.
@@ -34,11 +69,108 @@ func (pt *pageNode) mb() (mediaBox *Box) {
 	return
 }
 
+// bleed is the bleed box specified on this node or its nearest ancestor, or nil.
+func (pt *pageNode) bleed() *Box {
+	if pt.bleedBox != nil || pt.parent == nil {
+		return pt.bleedBox
+	}
+	return pt.parent.bleed()
+}
+
+// trim is the trim box specified on this node or its nearest ancestor, or nil.
+func (pt *pageNode) trim() *Box {
+	if pt.trimBox != nil || pt.parent == nil {
+		return pt.trimBox
+	}
+	return pt.parent.trim()
+}
+
+// art is the art box specified on this node or its nearest ancestor, or nil.
+func (pt *pageNode) art() *Box {
+	if pt.artBox != nil || pt.parent == nil {
+		return pt.artBox
+	}
+	return pt.parent.art()
+}
+
+// bb is the effective bleed box in this page, which defaults to the crop box.
+func (pt *pageNode) bb() *Box {
+	if b := pt.bleed(); b != nil {
+		return b
+	}
+	return pt.cb()
+}
+
+// tb is the effective trim box in this page, which defaults to the crop box.
+func (pt *pageNode) tb() *Box {
+	if b := pt.trim(); b != nil {
+		return b
+	}
+	return pt.cb()
+}
+
+// ab is the effective art box in this page, which defaults to the crop box.
+func (pt *pageNode) ab() *Box {
+	if b := pt.art(); b != nil {
+		return b
+	}
+	return pt.cb()
+}
+
+// rot is the effective rotation in this page.
+func (pt *pageNode) rot() int {
+	if pt.rotate != nil {
+		return *pt.rotate
+	}
+	if pt.parent == nil {
+		return 0
+	}
+	return pt.parent.rot()
+}
+
+// uu is the effective user unit in this page, or 0 if it is not specified.
+func (pt *pageNode) uu() float64 {
+	if pt.userUnit != 0 || pt.parent == nil {
+		return pt.userUnit
+	}
+	return pt.parent.uu()
+}
+
+// compileRotate returns the rotation entry, or empty if the rotation is not specified on this node.
+func (pt *pageNode) compileRotate() string {
+	if pt.rotate == nil {
+		return ""
+	}
+	return fmt.Sprintf("/Rotate %d", *pt.rotate)
+}
+
+// compileBoxes returns the entries of the boxes and the user unit of this page.
+// They are not inheritable in pdf, so that the values specified on the ancestors are resolved.
+func (pt *pageNode) compileBoxes() []string {
+	list := make([]string, 0, 4)
+	if b := pt.bleed(); b != nil {
+		list = append(list, fmt.Sprintf("/BleedBox %s", b.compile()))
+	}
+	if b := pt.trim(); b != nil {
+		list = append(list, fmt.Sprintf("/TrimBox %s", b.compile()))
+	}
+	if b := pt.art(); b != nil {
+		list = append(list, fmt.Sprintf("/ArtBox %s", b.compile()))
+	}
+	if u := pt.uu(); u != 0 {
+		list = append(list, fmt.Sprintf("/UserUnit %s", strconv.FormatFloat(u, 'f', -1, 64)))
+	}
+	return list
+}
+
 // pageList is a Pages node of a pdf page tree.
 type pageList struct {
 	pageNode
 	pageLists []*pageList
 	pages     []Page
+	// written are the child pages which have been written and released by the streaming builder.
+	// They precede the pages.
+	written []objectIdentifier
 }
 
 // newRootPage creates the root Pages node.
@@ -83,9 +215,22 @@ func (pl *pageList) addPage(p Page) {
 	pl.pages = append(pl.pages, p)
 }
 
+// release replaces the written child page with its object identifier, so that the page is released from the memory.
+func (pl *pageList) release(p Page) {
+	for i, child := range pl.pages {
+		if child == p {
+			pl.written = append(pl.written, objectIdentifier{objectNumber: p.refNo(), generationNumber: p.age()})
+			copy(pl.pages[i:], pl.pages[i+1:])
+			pl.pages[len(pl.pages)-1] = nil
+			pl.pages = pl.pages[:len(pl.pages)-1]
+			return
+		}
+	}
+}
+
 // asPDF is the pdf object expression of this Pages node.
 func (pl *pageList) compile() string {
-	list := make([]string, 0, 7)
+	list := make([]string, 0, 8)
 	list = append(list, "/Type /Pages")
 	if pl.parent != nil {
 		list = append(list, fmt.Sprintf("/Parent %s", pl.parent.indirectReference()))
@@ -96,6 +241,9 @@ func (pl *pageList) compile() string {
 	if pl.cropBox != nil {
 		list = append(list, fmt.Sprintf("/CropBox %s", pl.cropBox.compile()))
 	}
+	if r := pl.compileRotate(); r != "" {
+		list = append(list, r)
+	}
 	if pl.resource != nil {
 		list = append(list, fmt.Sprintf("/Resources %s", pl.resource.indirectReference()))
 	}
@@ -117,34 +265,57 @@ func (pl *pageList) walk(walker func(obj pdfObject)) {
 
 // kidsAsPDF is the pdf object expression of the children of this Pages node.
 func (pl *pageList) kidsAsPDF() string {
-	list := make([]string, 0, len(pl.pageLists)+len(pl.pages))
+	list := make([]string, 0, len(pl.pageLists)+len(pl.written)+len(pl.pages))
 	for _, pageList := range pl.pageLists {
 		list = append(list, pageList.indirectReference())
 	}
+	for _, written := range pl.written {
+		list = append(list, written.indirectReference())
+	}
 	for _, page := range pl.pages {
 		list = append(list, page.indirectReference())
 	}
 	return fmt.Sprintf("[%s]", strings.Join(list, " "))
 }
 
+// allPages returns the descendant Page nodes in the page order.
+// The pages written by the streaming builder are not included.
+func (pl *pageList) allPages() []Page {
+	pages := make([]Page, 0, len(pl.pages))
+	for _, pageList := range pl.pageLists {
+		pages = append(pages, pageList.allPages()...)
+	}
+	return append(pages, pl.pages...)
+}
+
 // count is The number of leaf nodes (page objects) that are descendants of this node within the page tree.
 func (pl *pageList) count() (c int) {
 	for _, pageList := range pl.pageLists {
 		c += pageList.count()
 	}
-	c += len(pl.pages)
+	c += len(pl.written) + len(pl.pages)
 	return
 }
 
+// countWritten is the number of the descendant pages which have been written by the streaming builder.
+func (pl *pageList) countWritten() (c int) {
+	for _, pageList := range pl.pageLists {
+		c += pageList.countWritten()
+	}
+	c += len(pl.written)
+	return
+}
+
+// buildPageTree moves the child pages into the leaves of the balanced page tree.
+// If the tree has been built, the pages added after it are moved into new leaves,
+// so that the written nodes of an incremental update are kept.
 func (pl *pageList) buildPageTree(order int) {
 	listSize := len(pl.pages)
 	n := listSize / order
 	if listSize%order > 0 {
 		n++
 	}
-	pageLists := make([]*pageList, 0)
 	descendants := pl.pages
-	pl.pageLists = pageLists
 	index := 0
 	for i := 0; i < n; i++ {
 		child := pl.newPageList(nil, nil, nil)
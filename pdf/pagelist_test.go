@@ -1,6 +1,9 @@
 package pdf
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestPageList1(t *testing.T) {
 	rmb := NewBox(1, 2, 3, 4)
@@ -121,3 +124,104 @@ func TestPageList2(t *testing.T) {
 		t.Errorf("walking failed: expected:%d actual:%d", expectedNum, actualNum)
 	}
 }
+
+func TestPageListAllPages(t *testing.T) {
+	rp := newRootPage(NewBoxA4(), NewBoxA4())
+	pages := make([]Page, 0, 20)
+	for i := 0; i < 20; i++ {
+		pages = append(pages, rp.newPage(nil, nil, nil))
+	}
+	rp.buildPageTree(3)
+	actual := rp.allPages()
+	if len(actual) != len(pages) {
+		t.Fatalf("allPages: size: expected:%d actual:%d", len(pages), len(actual))
+	}
+	for i, p := range pages {
+		if actual[i] != p {
+			t.Errorf("allPages: page order is broken at %d", i)
+		}
+	}
+}
+
+func TestPageNodeInheritance(t *testing.T) {
+	root := newRootPage(NewBox(0, 0, 100, 200), NewBox(10, 20, 90, 180))
+	root.SetBleedBox(NewBox(5, 5, 95, 195))
+	root.SetRotate(90)
+	root.SetUserUnit(2.5)
+	child := root.newPageList(nil, nil, nil)
+	p := child.newPage(nil, nil, nil).(*page)
+	isEqualBox(t, 5, 5, 95, 195, p.bb())
+	isEqualBox(t, 10, 20, 90, 180, p.tb())
+	isEqualBox(t, 10, 20, 90, 180, p.ab())
+	if p.rot() != 90 || p.uu() != 2.5 {
+		t.Errorf("inheritance: unexpected rotation:%d, user unit:%f", p.rot(), p.uu())
+	}
+	if p.trim() != nil {
+		t.Error("trim: trim box must not be specified")
+	}
+	child.SetTrimBox(NewBox(10, 10, 90, 190))
+	p.SetArtBox(NewBox(20, 30, 80, 170))
+	p.SetRotate(0)
+	isEqualBox(t, 10, 10, 90, 190, p.tb())
+	isEqualBox(t, 20, 30, 80, 170, p.ab())
+	if p.rot() != 0 {
+		t.Errorf("rot: page must override the rotation:%d", p.rot())
+	}
+	p.objectNumber = 3
+	child.objectNumber = 4
+	actual := p.compile()
+	expected := "/BleedBox [5 5 95 195] /TrimBox [10 10 90 190] /ArtBox [20 30 80 170] /UserUnit 2.5 /Rotate 0"
+	if !strings.Contains(actual, expected) {
+		t.Errorf("compile: expected:%s, actual:%s", expected, actual)
+	}
+	expected = "2 0 obj\n<</Type /Pages /MediaBox [0 0 100 200] /CropBox [10 20 90 180] /Rotate 90 /Kids [4 0 R] /Count 1>>\nendobj\n"
+	testCompillation(t, expected, root.compile())
+	if strings.Contains(child.compile(), "/Rotate") || strings.Contains(child.compile(), "/TrimBox") {
+		t.Error("compile: entries which are not specified or not inheritable must be omitted")
+	}
+}
+
+func TestValidatePages(t *testing.T) {
+	root := newRootPage(NewBoxA4(), NewBoxA4())
+	p1 := root.newPage(nil, nil, nil)
+	p2 := root.newPage(nil, nil, nil)
+	if err := validatePages([]Page{p1, p2}, 0); err != nil {
+		t.Errorf("validatePages: unexpected error:%s", err)
+	}
+	p2.SetRotate(45)
+	if err := validatePages([]Page{p1, p2}, 0); err == nil || err.Error() != "page: rotation must be a multiple of 90 on the page 2: 45" {
+		t.Errorf("validatePages: unexpected error:%v", err)
+	}
+	p2.SetRotate(-90)
+	root.SetUserUnit(-1)
+	if err := validatePages([]Page{p1, p2}, 0); err == nil || err.Error() != "page: user unit must be positive on the page 1: -1" {
+		t.Errorf("validatePages: unexpected error:%v", err)
+	}
+}
+
+func TestPageListBuildPageTreeAgain(t *testing.T) {
+	rp := newRootPage(NewBoxA4(), NewBoxA4())
+	pages := make([]Page, 0, 8)
+	for i := 0; i < 5; i++ {
+		pages = append(pages, rp.newPage(nil, nil, nil))
+	}
+	rp.buildPageTree(3)
+	leaves := append([]*pageList{}, rp.pageLists...)
+	for i := 0; i < 3; i++ {
+		pages = append(pages, rp.newPage(nil, nil, nil))
+	}
+	// The pages added after the tree is built are moved into new leaves.
+	rp.buildPageTree(3)
+	if len(rp.pageLists) != 3 || rp.pageLists[0] != leaves[0] || rp.pageLists[1] != leaves[1] || len(rp.pages) != 0 {
+		t.Fatalf("buildPageTree: built leaves are not kept:%d", len(rp.pageLists))
+	}
+	actual := rp.allPages()
+	if len(actual) != len(pages) || rp.count() != len(pages) {
+		t.Fatalf("buildPageTree: size: expected:%d actual:%d", len(pages), len(actual))
+	}
+	for i, p := range pages {
+		if actual[i] != p {
+			t.Errorf("buildPageTree: page order is broken at %d", i)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package pdf
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Recipient is a recipient of the document encrypted by the public-key security handler.
+type Recipient struct {
+	// Certificate is the X.509 certificate of the recipient, which has a RSA public key.
+	Certificate *x509.Certificate
+	// Permissions are the operations which are allowed for the recipient.
+	Permissions Permission
+}
+
+// PublicKeyEncryption is the public-key security handler.
+// The document is opened with the private key of one of the recipients.
+type PublicKeyEncryption struct {
+	// Algorithm is the encryption algorithm.
+	// EncryptionAES128 and EncryptionAES256 are available.
+	// If it is not specified, EncryptionAES256 is used.
+	Algorithm EncryptionAlgorithm
+	// Recipients are the recipients of the document.
+	Recipients []Recipient
+}
+
+func (pke *PublicKeyEncryption) newEncryption(id []byte, random io.Reader) (*encryption, error) {
+	if len(pke.Recipients) == 0 {
+		return nil, errors.New("encryption: no recipient is specified")
+	}
+	var filter cryptFilter
+	var h hash.Hash
+	switch pke.Algorithm {
+	case EncryptionAES128:
+		filter = cryptFilterAESV2
+		h = sha1.New()
+	case encryptionAlgorithmUndefined, EncryptionAES256:
+		filter = cryptFilterAESV3
+		h = sha256.New()
+	default:
+		return nil, fmt.Errorf("encryption: unsupported algorithm for public-key security handler: %d", pke.Algorithm)
+	}
+	seed := make([]byte, 20)
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, err
+	}
+	h.Write(seed)
+	recipients := make([]string, 0, len(pke.Recipients))
+	for _, r := range pke.Recipients {
+		if r.Certificate == nil {
+			return nil, errors.New("encryption: certificate of recipient is not specified")
+		}
+		// Each recipient receives the seed and its permissions.
+		content := make([]byte, 24)
+		copy(content, seed)
+		binary.BigEndian.PutUint32(content[20:], uint32(r.Permissions.p()))
+		env, err := envelope(content, []*x509.Certificate{r.Certificate}, random)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %s", err)
+		}
+		h.Write(env)
+		recipients = append(recipients, hexString(env))
+	}
+	key := h.Sum(nil)[:filter.keyLength()]
+	entries := append([]string{"/Filter /Adobe.PubSec /SubFilter /adbe.pkcs7.s5"},
+		filter.entries("/DefaultCryptFilter", fmt.Sprintf("/Recipients [%s]", strings.Join(recipients, " ")))...)
+	return newEncryption(key, filter, random, entries...), nil
+}
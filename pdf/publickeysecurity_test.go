@@ -0,0 +1,99 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// recipients returns the PKCS#7 objects of the recipients in the encryption dictionary.
+func recipients(t *testing.T, e *encryption) [][]byte {
+	m := regexp.MustCompile(`/Recipients \[([^\]]*)\]`).FindStringSubmatch(e.compile())
+	if m == nil {
+		t.Fatal("recipients: /Recipients is not found")
+	}
+	res := make([][]byte, 0)
+	for _, s := range strings.Fields(m[1]) {
+		b, _ := hex.DecodeString(strings.Trim(s, "<>"))
+		res = append(res, b)
+	}
+	return res
+}
+
+func TestPublicKeyEncryption(t *testing.T) {
+	cert1, key1 := newTestCertificate(t, "recipient1")
+	cert2, key2 := newTestCertificate(t, "recipient2")
+	pke := &PublicKeyEncryption{
+		Recipients: []Recipient{
+			{Certificate: cert1, Permissions: PermissionAll},
+			{Certificate: cert2, Permissions: PermissionPrint},
+		},
+	}
+	e, err := pke.newEncryption(nil, rand.Reader)
+	if err != nil {
+		t.Fatalf("newEncryption: unexpected error:%s", err)
+	}
+	dict := "/Filter /Adobe.PubSec /SubFilter /adbe.pkcs7.s5 /V 5 /Length 256 /CF <</DefaultCryptFilter <</AuthEvent /DocOpen /CFM /AESV3 /Length 32 /Recipients ["
+	if !strings.Contains(e.compile(), dict) || !strings.Contains(e.compile(), "/StmF /DefaultCryptFilter /StrF /DefaultCryptFilter") {
+		t.Errorf("newEncryption: unexpected dictionary:%s", e.compile())
+	}
+	pkcs7 := recipients(t, e)
+	if len(pkcs7) != 2 {
+		t.Fatalf("newEncryption: unexpected recipients:%d", len(pkcs7))
+	}
+	content1 := openEnvelope(t, pkcs7[0], cert1, key1)
+	content2 := openEnvelope(t, pkcs7[1], cert2, key2)
+	if len(content1) != 24 || !bytes.Equal(content1[:20], content2[:20]) {
+		t.Fatal("newEncryption: recipients must share the seed")
+	}
+	if int32(binary.BigEndian.Uint32(content1[20:])) != PermissionAll.p() || int32(binary.BigEndian.Uint32(content2[20:])) != PermissionPrint.p() {
+		t.Error("newEncryption: unexpected permissions")
+	}
+	// The file encryption key is the digest of the seed and the recipients.
+	h := sha256.New()
+	h.Write(content1[:20])
+	h.Write(pkcs7[0])
+	h.Write(pkcs7[1])
+	if !bytes.Equal(h.Sum(nil), e.key) {
+		t.Error("newEncryption: unexpected file key")
+	}
+}
+
+func TestPublicKeyEncryptionAES128(t *testing.T) {
+	cert, key := newTestCertificate(t, "recipient")
+	pke := &PublicKeyEncryption{Algorithm: EncryptionAES128, Recipients: []Recipient{{Certificate: cert}}}
+	e, err := pke.newEncryption(nil, rand.Reader)
+	if err != nil {
+		t.Fatalf("newEncryption: unexpected error:%s", err)
+	}
+	if e.filter != cryptFilterAESV2 || !strings.Contains(e.compile(), "/V 4 /Length 128") {
+		t.Errorf("newEncryption: unexpected dictionary:%s", e.compile())
+	}
+	pkcs7 := recipients(t, e)
+	content := openEnvelope(t, pkcs7[0], cert, key)
+	h := sha1.New()
+	h.Write(content[:20])
+	h.Write(pkcs7[0])
+	if !bytes.Equal(h.Sum(nil)[:16], e.key) {
+		t.Error("newEncryption: unexpected file key")
+	}
+}
+
+func TestPublicKeyEncryptionError(t *testing.T) {
+	cert, _ := newTestCertificate(t, "recipient")
+	for _, pke := range []*PublicKeyEncryption{
+		{},
+		{Recipients: []Recipient{{}}},
+		{Algorithm: EncryptionRC4, Recipients: []Recipient{{Certificate: cert}}},
+	} {
+		if _, err := pke.newEncryption(nil, rand.Reader); err == nil {
+			t.Error("newEncryption: invalid handler must be error")
+		}
+	}
+}
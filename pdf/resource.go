@@ -2,14 +2,18 @@ package pdf
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
 // resource is a resource of pdf page.
 type resource struct {
 	objectIdentifier
-	font    map[string]Font
-	xobject map[string]*stream
+	font      map[string]Font
+	xobject   map[string]*stream
+	extGState map[string]string
+	// properties are the property lists of marked contents, which are referred by their names.
+	properties map[string]pdfObject
 }
 
 func newResource() *resource {
@@ -17,6 +21,8 @@ func newResource() *resource {
 		objectIdentifier: objectIdentifier{},
 		font:             make(map[string]Font),
 		xobject:          make(map[string]*stream),
+		extGState:        make(map[string]string),
+		properties:       make(map[string]pdfObject),
 	}
 }
 
@@ -30,33 +36,97 @@ func (r *resource) addImage(i *ImageResource) {
 	r.xobject[i.name] = i.asStream()
 }
 
+// addExtGState adds the graphics state parameter dictionary to the page resource.
+func (r *resource) addExtGState(name, dict string) {
+	r.extGState[name] = dict
+}
+
+// addProperties adds the property list of marked contents to the page resource.
+func (r *resource) addProperties(name string, obj pdfObject) {
+	r.properties[name] = obj
+}
+
+// fontNames returns the sorted resource names of fonts.
+func (r *resource) fontNames() []string {
+	names := make([]string, 0, len(r.font))
+	for k := range r.font {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// xobjectNames returns the sorted resource names of external objects.
+func (r *resource) xobjectNames() []string {
+	names := make([]string, 0, len(r.xobject))
+	for k := range r.xobject {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (r *resource) compile() string {
 	fonts := make([]string, 0, len(r.font))
-	for k, f := range r.font {
-		fonts = append(fonts, fmt.Sprintf("%s %s", k, f.indirectReference()))
+	for _, k := range r.fontNames() {
+		fonts = append(fonts, fmt.Sprintf("%s %s", k, r.font[k].indirectReference()))
 	}
 	xobjects := make([]string, 0, len(r.xobject))
-	for k, xo := range r.xobject {
-		xobjects = append(xobjects, fmt.Sprintf("%s %s", k, xo.indirectReference()))
+	for _, k := range r.xobjectNames() {
+		xobjects = append(xobjects, fmt.Sprintf("%s %s", k, r.xobject[k].indirectReference()))
 	}
-	dict := make([]string, 0, 2)
+	extGStates := make([]string, 0, len(r.extGState))
+	for _, k := range sortedKeys(r.extGState) {
+		extGStates = append(extGStates, fmt.Sprintf("%s %s", k, r.extGState[k]))
+	}
+	properties := make([]string, 0, len(r.properties))
+	for _, k := range sortedPropertyNames(r.properties) {
+		properties = append(properties, fmt.Sprintf("%s %s", k, r.properties[k].indirectReference()))
+	}
+	dict := make([]string, 0, 4)
 	if len(fonts) > 0 {
 		dict = append(dict, fmt.Sprintf("/Font <<%s>>", strings.Join(fonts, " ")))
 	}
 	if len(xobjects) > 0 {
 		dict = append(dict, fmt.Sprintf("/XObject <<%s>>", strings.Join(xobjects, " ")))
 	}
+	if len(extGStates) > 0 {
+		dict = append(dict, fmt.Sprintf("/ExtGState <<%s>>", strings.Join(extGStates, " ")))
+	}
+	if len(properties) > 0 {
+		dict = append(dict, fmt.Sprintf("/Properties <<%s>>", strings.Join(properties, " ")))
+	}
 	return r.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
 }
 
 func (r *resource) walk(walker func(obj pdfObject)) {
 	if r != nil {
 		walker(r)
-		for _, f := range r.font {
-			f.walk(walker)
+		for _, k := range r.fontNames() {
+			r.font[k].walk(walker)
 		}
-		for _, xo := range r.xobject {
-			walker(xo)
+		for _, k := range r.xobjectNames() {
+			walker(r.xobject[k])
 		}
 	}
 }
+
+// sortedKeys returns the sorted keys of the map.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedPropertyNames returns the sorted names of the property lists.
+func sortedPropertyNames(m map[string]pdfObject) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
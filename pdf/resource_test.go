@@ -1,6 +1,9 @@
 package pdf
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 type mockFont struct {
 }
@@ -20,3 +23,35 @@ func TestResource(t *testing.T) {
 		t.Error("resource is not initial state: xobject is not empty")
 	}
 }
+
+func TestResourceCompile(t *testing.T) {
+	r := newResource()
+	for i := 9; i >= 0; i-- {
+		f := newFontType1(fmt.Sprintf("/F%d", i), "/Helvetica")
+		f.(*type1Font).objectNumber = 10 + i
+		r.addFont(f)
+	}
+	expected := "0 0 obj\n<</Font <</F0 10 0 R /F1 11 0 R /F2 12 0 R /F3 13 0 R /F4 14 0 R /F5 15 0 R /F6 16 0 R /F7 17 0 R /F8 18 0 R /F9 19 0 R>>>>\nendobj\n"
+	testCompillation(t, expected, r.compile())
+	nums := make([]int, 0)
+	r.walk(func(obj pdfObject) {
+		nums = append(nums, obj.refNo())
+	})
+	for i, num := range nums[1:] {
+		if num != 10+i {
+			t.Errorf("walk: fonts are not sorted: %v", nums)
+			break
+		}
+	}
+}
+
+func TestResourceProperties(t *testing.T) {
+	r := newResource()
+	for i := 1; i >= 0; i-- {
+		ocg := newOptionalContentGroup("layer", fmt.Sprintf("/OC%d", i), true)
+		ocg.objectNumber = 10 + i
+		r.addProperties(ocg.resourceName, ocg)
+	}
+	expected := "0 0 obj\n<</Properties <</OC0 10 0 R /OC1 11 0 R>>>>\nendobj\n"
+	testCompillation(t, expected, r.compile())
+}
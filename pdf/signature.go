@@ -0,0 +1,293 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// sigFlagSignaturesExist : The document contains at least one signature field.
+	sigFlagSignaturesExist = 1 << 0
+	// sigFlagAppendOnly : The document contains signatures that may be invalidated if the file is saved in a way that alters its previous contents.
+	sigFlagAppendOnly = 1 << 1
+)
+
+// byteRangePlaceholder is written in place of the byte range until the document is signed.
+// The byte range is padded with spaces to the same length.
+const byteRangePlaceholder = "/ByteRange [0 0000000000 0000000000 0000000000]"
+
+// SignatureField is a signature field of the interactive form.
+// If its width or height is zero, the signature is invisible.
+// Otherwise its appearance stream shows the signer, the date and the reason,
+// with a font registered by Builder.AddFont if a font is not specified.
+type SignatureField interface {
+	Annotation
+	// Reason specifies the reason for the signing, such as "I agree".
+	Reason(reason string) SignatureField
+	// Location specifies the location of the signing, such as the name of the city.
+	Location(location string) SignatureField
+	// ContactInfo specifies the information to enable a recipient to contact the signer.
+	ContactInfo(contactInfo string) SignatureField
+	// Font specifies the font and its size of the text in the appearance.
+	// The font must be registered by Builder.AddFont, otherwise Build returns an error.
+	Font(font Font, fontSize int) SignatureField
+}
+
+// signatureField is a implementation of a SignatureField interface.
+type signatureField struct {
+	field
+	variableText
+	reason      string
+	location    string
+	contactInfo string
+	// value is the signature dictionary, or nil if the field is not signed.
+	value *signatureValue
+}
+
+func newSignatureField(name string, page Page, rect *Box) *signatureField {
+	sf := &signatureField{
+		field:        newField("/Sig", name, page, rect),
+		variableText: variableText{fontSize: 8},
+	}
+	if sf.width() == 0 || sf.height() == 0 {
+		sf.appearance = nil
+	}
+	return sf
+}
+
+func (sf *signatureField) Reason(reason string) SignatureField {
+	sf.reason = reason
+	return sf
+}
+
+func (sf *signatureField) Location(location string) SignatureField {
+	sf.location = location
+	return sf
+}
+
+func (sf *signatureField) ContactInfo(contactInfo string) SignatureField {
+	sf.contactInfo = contactInfo
+	return sf
+}
+
+func (sf *signatureField) Font(font Font, fontSize int) SignatureField {
+	sf.font = font
+	sf.fontSize = fontSize
+	return sf
+}
+
+// sign makes the field have the signature dictionary.
+func (sf *signatureField) sign(name string, date time.Time, size int) {
+	sf.value = &signatureValue{
+		name:        name,
+		date:        date,
+		reason:      sf.reason,
+		location:    sf.location,
+		contactInfo: sf.contactInfo,
+		size:        size,
+	}
+}
+
+// text returns the text shown in the appearance.
+func (sf *signatureField) text() string {
+	if sf.value == nil {
+		return ""
+	}
+	lines := []string{
+		fmt.Sprintf("Digitally signed by %s", sf.value.name),
+		fmt.Sprintf("Date: %s", sf.value.date.Format("2006.01.02 15:04:05 -07'00'")),
+	}
+	if sf.reason != "" {
+		lines = append(lines, fmt.Sprintf("Reason: %s", sf.reason))
+	}
+	if sf.location != "" {
+		lines = append(lines, fmt.Sprintf("Location: %s", sf.location))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (sf *signatureField) buildField(dr *resource) error {
+	if sf.appearance == nil {
+		return nil
+	}
+	sf.appearance.reset()
+	sf.appearance.addStringDatum(newRectangle(nil, 0, 0, sf.width(), sf.height()).StrokeColor(NewColorGrayScale(0)).render(sf.bbox()))
+	text := sf.text()
+	if text == "" {
+		return nil
+	}
+	if err := sf.resolveFont("signature field", dr); err != nil {
+		// The text is omitted if no font is specified or registered.
+		if sf.font == nil {
+			return nil
+		}
+		return err
+	}
+	sf.appearance.resetResources()
+	sf.appearance.resources.addFont(sf.font)
+	sf.appearance.addStringDatum(sf.font.createText(textFieldPadding, sf.height()-textFieldPadding-sf.fontSize, sf.fontSize, text))
+	return nil
+}
+
+func (sf *signatureField) compile() string {
+	entries := make([]string, 0, 1)
+	if sf.value != nil {
+		entries = append(entries, fmt.Sprintf("/V %s", sf.value.indirectReference()))
+	}
+	return sf.compileHelper(entries...)
+}
+
+func (sf *signatureField) walk(walker func(obj pdfObject)) {
+	walker(sf)
+	if sf.appearance != nil {
+		sf.appearance.walk(walker)
+	}
+	if sf.value != nil {
+		walker(sf.value)
+	}
+}
+
+// signatureValue is a signature dictionary.
+// The byte range and the contents are written as placeholders, and replaced when the document is signed.
+type signatureValue struct {
+	objectIdentifier
+	// timestamp is true if the dictionary is a document timestamp.
+	timestamp   bool
+	name        string
+	date        time.Time
+	reason      string
+	location    string
+	contactInfo string
+	// size is the number of bytes reserved for the contents.
+	size int
+}
+
+// compileWith compiles the signature dictionary.
+// Strings except the contents are encrypted if e is not nil.
+func (sv *signatureValue) compileWith(e *encryption) ([]byte, error) {
+	dict := []string{"/Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached"}
+	if sv.timestamp {
+		dict[0] = "/Type /DocTimeStamp /Filter /Adobe.PPKLite /SubFilter /ETSI.RFC3161"
+	}
+	texts := []struct {
+		key   string
+		value string
+	}{
+		{"/Name", sv.name},
+		{"/Reason", sv.reason},
+		{"/Location", sv.location},
+		{"/ContactInfo", sv.contactInfo},
+	}
+	for _, t := range texts {
+		if t.value != "" {
+			dict = append(dict, fmt.Sprintf("%s %s", t.key, textString(t.value)))
+		}
+	}
+	if !sv.date.IsZero() {
+		dict = append(dict, fmt.Sprintf("/M %s", dateString(sv.date)))
+	}
+	entries := strings.Join(dict, " ")
+	if e != nil {
+		var err error
+		if entries, err = e.encryptStrings(sv, entries); err != nil {
+			return nil, err
+		}
+	}
+	contents := fmt.Sprintf("/Contents <%s>", strings.Repeat("0", sv.size*2))
+	return []byte(sv.bracket(fmt.Sprintf("<<%s %s %s>>", entries, byteRangePlaceholder, contents))), nil
+}
+
+// signature is a signing step applied to the written document.
+type signature struct {
+	field  *signatureField
+	signer crypto.Signer
+	chain  []*x509.Certificate
+}
+
+// WithSignature signs the document with the signature field.
+// The signature field must be added to a page of the builder.
+// The first certificate of the chain must be the certificate of the signer, followed by the intermediate certificates.
+// The signed document cannot be built again, and its later revisions are appended by Update.
+func WithSignature(field SignatureField, signer crypto.Signer, chain []*x509.Certificate) BuildOption {
+	return func(o *buildOptions) {
+		sf, _ := field.(*signatureField)
+		o.signature = &signature{
+			field:  sf,
+			signer: signer,
+			chain:  chain,
+		}
+	}
+}
+
+// prepare makes the field have the signature dictionary which reserves the space for the signature.
+func (s *signature) prepare(date time.Time) error {
+	if s.field == nil {
+		return errors.New("signature: signature field is not specified")
+	}
+	if s.signer == nil || len(s.chain) == 0 {
+		return errors.New("signature: signer and its certificate are not specified")
+	}
+	// The signature contains the certificates, the signed attributes and the signature value.
+	size := 4096
+	for _, c := range s.chain {
+		size += len(c.Raw)
+	}
+	s.field.sign(s.chain[0].Subject.CommonName, date, size)
+	return nil
+}
+
+// sign computes the byte range of the written document, and embeds the signature in the contents.
+func (s *signature) sign(pdf []byte, random io.Reader) ([]byte, error) {
+	return embedSignature(pdf, s.field.value, func(data []byte) ([]byte, error) {
+		return signDetached(data, s.signer, s.chain, s.field.value.date, random)
+	})
+}
+
+// embedSignature replaces the placeholders of the signature dictionary in the written document
+// with the byte range and the signature of the bytes in the range.
+// Argument sign returns the DER encoded signature of the data.
+func embedSignature(pdf []byte, sv *signatureValue, sign func(data []byte) ([]byte, error)) ([]byte, error) {
+	if sv.refNo() == 0 {
+		return nil, errors.New("signature: signature field is not added to the pages of the builder")
+	}
+	// The dictionary of the latest revision is signed, if the document has been updated.
+	start := bytes.LastIndex(pdf, []byte(fmt.Sprintf("%d %d obj\n<</Type /", sv.refNo(), sv.age())))
+	if start < 0 {
+		return nil, errors.New("signature: signature dictionary is not found")
+	}
+	br := bytes.Index(pdf[start:], []byte(byteRangePlaceholder))
+	if br < 0 {
+		return nil, errors.New("signature: byte range is not found")
+	}
+	br += start
+	contents := bytes.Index(pdf[start:], []byte("/Contents <"))
+	if contents < 0 {
+		return nil, errors.New("signature: contents are not found")
+	}
+	contentsStart := start + contents + len("/Contents ")
+	contentsEnd := contentsStart + 2*sv.size + 2
+	if contentsEnd > len(pdf) {
+		return nil, errors.New("signature: contents are truncated")
+	}
+	byteRange := fmt.Sprintf("/ByteRange [0 %d %d %d]", contentsStart, contentsEnd, len(pdf)-contentsEnd)
+	copy(pdf[br:], byteRange+strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange)))
+	signed := make([]byte, 0, len(pdf)-(contentsEnd-contentsStart))
+	signed = append(append(signed, pdf[:contentsStart]...), pdf[contentsEnd:]...)
+	cms, err := sign(signed)
+	if err != nil {
+		return nil, fmt.Errorf("signature: %s", err)
+	}
+	if len(cms) > sv.size {
+		return nil, fmt.Errorf("signature: signature exceeds the reserved size: %d > %d", len(cms), sv.size)
+	}
+	// The rest of the contents remains zeros.
+	hex.Encode(pdf[contentsStart+1:], cms)
+	return pdf, nil
+}
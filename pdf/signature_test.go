@@ -0,0 +1,154 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedBytes returns the bytes covered by the last signature and the signature in the signed document.
+func signedBytes(t *testing.T, pdf []byte) ([]byte, []byte) {
+	all := regexp.MustCompile(`/ByteRange \[(\d+) (\d+) (\d+) (\d+)\] *`).FindAllSubmatch(pdf, -1)
+	if len(all) == 0 {
+		t.Fatal("signedBytes: byte range is not found")
+	}
+	m := all[len(all)-1]
+	br := make([]int, 4)
+	for i := range br {
+		br[i], _ = strconv.Atoi(string(m[i+1]))
+	}
+	if br[0] != 0 || br[2]+br[3] != len(pdf) {
+		t.Fatalf("signedBytes: byte range does not cover the document:%v", br)
+	}
+	contents := pdf[br[1]:br[2]]
+	if contents[0] != '<' || contents[len(contents)-1] != '>' {
+		t.Fatalf("signedBytes: byte range does not exclude the contents")
+	}
+	der, err := hex.DecodeString(string(contents[1 : len(contents)-1]))
+	if err != nil {
+		t.Fatalf("signedBytes: unexpected error:%s", err)
+	}
+	return append(append([]byte{}, pdf[:br[1]]...), pdf[br[2]:]...), der
+}
+
+func TestSignatureField(t *testing.T) {
+	p := newTestPage()
+	f := newFontType1("/F0", "/Helvetica")
+	s := p.SignatureField("approval", 0, 0, 100, 40)
+	if len(p.annotations()) != 1 || p.annotations()[0] != s {
+		t.Fatal("SignatureField: field is not added to the page")
+	}
+	sf := s.Reason("I agree").Location("Tokyo").Font(f, 8).(*signatureField)
+	sf.objectNumber = 4
+	sf.appearance.objectNumber = 5
+	testCompillation(t, "4 0 obj\n<</Type /Annot /Subtype /Widget /Rect [10 140 110 180] /P 3 0 R /F 4 /FT /Sig /T (approval) /AP <</N 5 0 R>>>>\nendobj\n", sf.compile())
+	sf.sign("signer", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), 10)
+	sf.value.objectNumber = 6
+	if err := sf.buildField(newTestDefaultResource(f)); err != nil {
+		t.Fatalf("buildField: unexpected error:%s", err)
+	}
+	testCompillation(t, "4 0 obj\n<</Type /Annot /Subtype /Widget /Rect [10 140 110 180] /P 3 0 R /F 4 /FT /Sig /T (approval) /AP <</N 5 0 R>> /V 6 0 R>>\nendobj\n", sf.compile())
+	testRendering(t, "q 0.000000 G 0 0 100 40 re S Q\n", string(sf.appearance.data[0]))
+	text := "Digitally signed by signer\nDate: 2020.01.02 03:04:05 +00'00'\nReason: I agree\nLocation: Tokyo"
+	testRendering(t, f.createText(2, 30, 8, text), string(sf.appearance.data[1]))
+	data, _ := sf.value.compileWith(nil)
+	expected := "6 0 obj\n<</Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached /Name (signer) /Reason (I agree) /Location (Tokyo) /M (D:20200102030405Z) " +
+		byteRangePlaceholder + " /Contents <" + strings.Repeat("0", 20) + ">>>\nendobj\n"
+	testCompillation(t, expected, string(data))
+}
+
+func TestInvisibleSignatureField(t *testing.T) {
+	sf := newSignatureField("approval", newTestPage(), NewBox(0, 0, 0, 0))
+	if sf.appearance != nil {
+		t.Error("newSignatureField: invisible field must not have appearance")
+	}
+	sf.sign("signer", time.Now(), 10)
+	if err := sf.buildField(newResource()); err != nil {
+		t.Errorf("buildField: unexpected error:%s", err)
+	}
+	nums := 0
+	sf.walk(func(obj pdfObject) {
+		nums++
+	})
+	if nums != 2 {
+		t.Errorf("walk: unexpected objects:%d", nums)
+	}
+}
+
+func TestBuilderSignature(t *testing.T) {
+	cert, key := newTestCertificate(t, "signer")
+	for _, encryption := range []Encryption{nil, &PasswordEncryption{Algorithm: EncryptionAES128}} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		b.AddFont(b.NewFontType1("/Helvetica"))
+		sf := b.AddPage().SignatureField("approval", 10, 10, 200, 50).Reason("I agree")
+		options := []BuildOption{WithSignature(sf, key, []*x509.Certificate{cert})}
+		if encryption != nil {
+			options = append(options, WithEncryption(encryption))
+		}
+		var buf bytes.Buffer
+		if err := b.Build(&buf, options...); err != nil {
+			t.Fatalf("Build: unexpected error:%s", err)
+		}
+		out := buf.Bytes()
+		if !bytes.Contains(out, []byte("/SigFlags 3")) {
+			t.Error("Build: signature flags are not written")
+		}
+		if !bytes.Contains(out, []byte(fmt.Sprintf("/V %s", sf.(*signatureField).value.indirectReference()))) {
+			t.Error("Build: signature field does not refer the signature")
+		}
+		data, der := signedBytes(t, out)
+		verifyDetached(t, der, data)
+		// The signature field would be written with the placeholder of the byte range by a later build.
+		if err := b.Build(&bytes.Buffer{}); err == nil || err.Error() != "signature: document has been signed, use Update to append revisions" {
+			t.Errorf("Build: unexpected error:%v", err)
+		}
+	}
+}
+
+func TestBuilderSignatureError(t *testing.T) {
+	cert, key := newTestCertificate(t, "signer")
+	other := NewBuilder(NewBoxA4(), NewBoxA4())
+	sf := other.AddPage().SignatureField("approval", 0, 0, 0, 0)
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	if err := b.Build(&bytes.Buffer{}, WithSignature(sf, key, []*x509.Certificate{cert})); err == nil {
+		t.Error("Build: signature field on another builder must be error")
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	sf = b.AddPage().SignatureField("approval", 0, 0, 0, 0)
+	if err := b.Build(&bytes.Buffer{}, WithSignature(sf, key, nil)); err == nil {
+		t.Error("Build: signature without certificate must be error")
+	}
+}
+
+func TestEmbedSignatureError(t *testing.T) {
+	sv := &signatureValue{objectIdentifier: objectIdentifier{5, 0}, timestamp: true, size: 2}
+	sign := func(data []byte) ([]byte, error) {
+		return []byte{0x30}, nil
+	}
+	for _, c := range []struct {
+		pdf      string
+		expected string
+	}{
+		{"5 0 obj\n<</Type /Sig>>\nendobj\n", "signature: byte range is not found"},
+		{"5 0 obj\n<</Type /Sig " + byteRangePlaceholder + ">>\nendobj\n", "signature: contents are not found"},
+		{"5 0 obj\n<</Type /Sig " + byteRangePlaceholder + " /Contents <00", "signature: contents are truncated"},
+	} {
+		if _, err := embedSignature([]byte(c.pdf), sv, sign); err == nil || err.Error() != c.expected {
+			t.Errorf("embedSignature: unexpected error:%v", err)
+		}
+	}
+}
+
+func TestDocumentTimestampValue(t *testing.T) {
+	sv := &signatureValue{objectIdentifier: objectIdentifier{5, 0}, timestamp: true, size: 2}
+	data, _ := sv.compileWith(nil)
+	expected := "5 0 obj\n<</Type /DocTimeStamp /Filter /Adobe.PPKLite /SubFilter /ETSI.RFC3161 " + byteRangePlaceholder + " /Contents <0000>>>\nendobj\n"
+	testCompillation(t, expected, string(data))
+}
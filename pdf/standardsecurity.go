@@ -0,0 +1,274 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// EncryptionAlgorithm is the algorithm used by the standard security handler.
+type EncryptionAlgorithm int
+
+const (
+	encryptionAlgorithmUndefined EncryptionAlgorithm = iota
+	// EncryptionRC4 : RC4 with the 128-bit key (revision 3), readable by PDF 1.4 or later.
+	EncryptionRC4
+	// EncryptionAES128 : AES with the 128-bit key (revision 4), readable by PDF 1.6 or later.
+	EncryptionAES128
+	// EncryptionAES256 : AES with the 256-bit key (revision 6), readable by PDF 2.0 or PDF 1.7 with Adobe extension level 8.
+	EncryptionAES256
+)
+
+// Permission is a set of operations which are allowed when the document is opened with the user password.
+type Permission int
+
+const (
+	// PermissionPrint : Print the document.
+	PermissionPrint Permission = 1 << 2
+	// PermissionModify : Modify the contents of the document.
+	PermissionModify Permission = 1 << 3
+	// PermissionCopy : Copy or otherwise extract text and graphics from the document.
+	PermissionCopy Permission = 1 << 4
+	// PermissionAnnotate : Add or modify annotations, and fill in interactive form fields.
+	PermissionAnnotate Permission = 1 << 5
+	// PermissionFillForms : Fill in existing interactive form fields, even if PermissionAnnotate is not set.
+	PermissionFillForms Permission = 1 << 8
+	// PermissionExtract : Extract text and graphics in support of accessibility.
+	PermissionExtract Permission = 1 << 9
+	// PermissionAssemble : Insert, rotate, or delete pages and create bookmarks or thumbnail images.
+	PermissionAssemble Permission = 1 << 10
+	// PermissionPrintHighQuality : Print the document to a representation from which a faithful digital copy could be generated.
+	PermissionPrintHighQuality Permission = 1 << 11
+	// PermissionAll : All operations.
+	PermissionAll = PermissionPrint | PermissionModify | PermissionCopy | PermissionAnnotate |
+		PermissionFillForms | PermissionExtract | PermissionAssemble | PermissionPrintHighQuality
+)
+
+// p returns the value of the P entry.
+// Reserved bits 7, 8 and 13-32 are set, and bits 1-2 are cleared.
+func (p Permission) p() int32 {
+	return int32(uint32(p)&uint32(PermissionAll) | 0xFFFFF0C0)
+}
+
+// passwordPadding is the string used to pad passwords to 32 bytes.
+var passwordPadding = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// PasswordEncryption is the standard security handler.
+// The document is opened with either the user password or the owner password.
+// The user password may be empty, so that anyone can open the document with the permissions.
+type PasswordEncryption struct {
+	// Algorithm is the encryption algorithm.
+	// If it is not specified, EncryptionAES256 is used.
+	Algorithm EncryptionAlgorithm
+	// UserPassword is the password to open the document with the permissions.
+	UserPassword string
+	// OwnerPassword is the password to open the document with full access.
+	// If it is empty, a random password is used.
+	OwnerPassword string
+	// Permissions are the operations which are allowed when the document is opened with the user password.
+	Permissions Permission
+}
+
+func (pe *PasswordEncryption) newEncryption(id []byte, random io.Reader) (*encryption, error) {
+	owner := pe.OwnerPassword
+	if owner == "" {
+		b := make([]byte, 16)
+		if _, err := io.ReadFull(random, b); err != nil {
+			return nil, err
+		}
+		owner = hexString(b)
+	}
+	switch pe.Algorithm {
+	case EncryptionRC4:
+		return pe.newEncryptionR4(cryptFilterV2, 3, owner, id, random)
+	case EncryptionAES128:
+		return pe.newEncryptionR4(cryptFilterAESV2, 4, owner, id, random)
+	case encryptionAlgorithmUndefined, EncryptionAES256:
+		return pe.newEncryptionR6(owner, random)
+	default:
+		return nil, fmt.Errorf("encryption: unknown algorithm: %d", pe.Algorithm)
+	}
+}
+
+// newEncryptionR4 returns the encryption of revision 3 or 4.
+func (pe *PasswordEncryption) newEncryptionR4(filter cryptFilter, revision int, owner string, id []byte, random io.Reader) (*encryption, error) {
+	user, err := latin1Password(pe.UserPassword)
+	if err != nil {
+		return nil, err
+	}
+	ownerBytes, err := latin1Password(owner)
+	if err != nil {
+		return nil, err
+	}
+	p := pe.Permissions.p()
+	o := ownerPasswordValueR4(ownerBytes, user)
+	key := fileKeyR4(user, o, p, id)
+	u := userPasswordValueR4(key, id)
+	entries := append([]string{"/Filter /Standard"}, filter.entries("/StdCF")...)
+	entries = append(entries, fmt.Sprintf("/R %d /O %s /U %s /P %d", revision, hexString(o), hexString(u), p))
+	return newEncryption(key, filter, random, entries...), nil
+}
+
+// newEncryptionR6 returns the encryption of revision 6.
+func (pe *PasswordEncryption) newEncryptionR6(owner string, random io.Reader) (*encryption, error) {
+	user := utf8Password(pe.UserPassword)
+	ownerBytes := utf8Password(owner)
+	// The file encryption key, the salts of the user password and the salts of the owner password.
+	r := make([]byte, 32+16+16)
+	if _, err := io.ReadFull(random, r); err != nil {
+		return nil, err
+	}
+	key := r[:32]
+	u := append(hashR6(user, r[32:40], nil), r[32:48]...)
+	ue := encryptKeyR6(hashR6(user, r[40:48], nil), key)
+	o := append(hashR6(ownerBytes, r[48:56], u), r[48:64]...)
+	oe := encryptKeyR6(hashR6(ownerBytes, r[56:64], u), key)
+	p := pe.Permissions.p()
+	perms := make([]byte, 16)
+	binary.LittleEndian.PutUint32(perms[0:4], uint32(p))
+	copy(perms[4:], []byte{0xFF, 0xFF, 0xFF, 0xFF, 'T', 'a', 'd', 'b'})
+	if _, err := io.ReadFull(random, perms[12:]); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	block.Encrypt(perms, perms)
+	entries := append([]string{"/Filter /Standard"}, cryptFilterAESV3.entries("/StdCF")...)
+	entries = append(entries, fmt.Sprintf("/R 6 /O %s /U %s /OE %s /UE %s /P %d /Perms %s",
+		hexString(o), hexString(u), hexString(oe), hexString(ue), p, hexString(perms)))
+	return newEncryption(key, cryptFilterAESV3, random, entries...), nil
+}
+
+// latin1Password encodes the password of revision 3 or 4.
+func latin1Password(password string) ([]byte, error) {
+	res := make([]byte, 0, len(password))
+	for _, r := range password {
+		if r > 0xFF {
+			return nil, fmt.Errorf("encryption: password must consist of Latin-1 characters for RC4 and AES-128: %q", password)
+		}
+		res = append(res, byte(r))
+	}
+	return res, nil
+}
+
+// utf8Password encodes the password of revision 6.
+// The password is truncated to 127 bytes.
+func utf8Password(password string) []byte {
+	b := []byte(password)
+	if len(b) > 127 {
+		b = b[:127]
+	}
+	return b
+}
+
+// padPassword pads or truncates the password to exactly 32 bytes.
+func padPassword(password []byte) []byte {
+	res := make([]byte, 32)
+	n := copy(res, password)
+	copy(res[n:], passwordPadding)
+	return res
+}
+
+// md5R4 returns MD5 hash of the data, rehashed 50 times.
+func md5R4(data ...[]byte) []byte {
+	h := md5.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	sum := h.Sum(nil)
+	for i := 0; i < 50; i++ {
+		s := md5.Sum(sum)
+		sum = s[:]
+	}
+	return sum
+}
+
+// rc4R4 encrypts the data 20 times with the key whose bytes are XORed with the iteration counter.
+func rc4R4(key, data []byte) []byte {
+	res := append([]byte{}, data...)
+	k := make([]byte, len(key))
+	for i := 0; i < 20; i++ {
+		for j := range key {
+			k[j] = key[j] ^ byte(i)
+		}
+		c, _ := rc4.NewCipher(k)
+		c.XORKeyStream(res, res)
+	}
+	return res
+}
+
+// ownerPasswordValueR4 computes the O entry of revision 3 or 4.
+func ownerPasswordValueR4(owner, user []byte) []byte {
+	return rc4R4(md5R4(padPassword(owner)), padPassword(user))
+}
+
+// fileKeyR4 computes the file encryption key of revision 3 or 4.
+func fileKeyR4(user, o []byte, p int32, id []byte) []byte {
+	pBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(pBytes, uint32(p))
+	return md5R4(padPassword(user), o, pBytes, id)
+}
+
+// userPasswordValueR4 computes the U entry of revision 3 or 4.
+// The last 16 bytes are arbitrary padding.
+func userPasswordValueR4(key, id []byte) []byte {
+	h := md5.New()
+	h.Write(passwordPadding)
+	h.Write(id)
+	return append(rc4R4(key, h.Sum(nil)), make([]byte, 16)...)
+}
+
+// hashR6 computes the hash of the password of revision 6.
+// udata is the U entry when the owner password is hashed, otherwise it is empty.
+func hashR6(password, salt, udata []byte) []byte {
+	sum := sha256.Sum256(bytes.Join([][]byte{password, salt, udata}, nil))
+	k := sum[:]
+	for i := 0; ; i++ {
+		k1 := bytes.Repeat(bytes.Join([][]byte{password, k, udata}, nil), 64)
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+		// The first 16 bytes of e as a big-endian integer modulo 3 equals the sum of the bytes modulo 3.
+		mod := 0
+		for _, b := range e[:16] {
+			mod += int(b)
+		}
+		var h hash.Hash
+		switch mod % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+		// At least 64 rounds, until the last byte of e is not greater than the round number minus 32.
+		if i >= 63 && int(e[len(e)-1]) <= i-31 {
+			break
+		}
+	}
+	return k[:32]
+}
+
+// encryptKeyR6 encrypts the file encryption key by AES-256 in CBC mode with no padding and a zero initialization vector.
+func encryptKeyR6(intermediate, key []byte) []byte {
+	block, _ := aes.NewCipher(intermediate)
+	res := make([]byte, len(key))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(res, key)
+	return res
+}
@@ -0,0 +1,175 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rc4"
+	"encoding/binary"
+	"encoding/hex"
+	"regexp"
+	"testing"
+)
+
+// counterReader is a deterministic source of random bytes.
+type counterReader struct {
+	n byte
+}
+
+func (r *counterReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.n
+		r.n++
+	}
+	return len(p), nil
+}
+
+// entry returns the bytes of the hexadecimal string of the key in the encryption dictionary.
+func entry(t *testing.T, e *encryption, key string) []byte {
+	m := regexp.MustCompile(key + ` <([0-9A-F]*)>`).FindStringSubmatch(e.compile())
+	if m == nil {
+		t.Fatalf("entry: %s is not found", key)
+	}
+	b, _ := hex.DecodeString(m[1])
+	return b
+}
+
+func TestPermission(t *testing.T) {
+	if p := PermissionPrint.p(); p != -3900 {
+		t.Errorf("p: expected:-3900 actual:%d", p)
+	}
+	if p := PermissionAll.p(); p != -4 {
+		t.Errorf("p: expected:-4 actual:%d", p)
+	}
+	if p := Permission(0).p(); p != -3904 {
+		t.Errorf("p: expected:-3904 actual:%d", p)
+	}
+}
+
+func TestPadPassword(t *testing.T) {
+	if !bytes.Equal(passwordPadding, padPassword(nil)) {
+		t.Error("padPassword: empty password must be the padding string")
+	}
+	padded := padPassword([]byte("user"))
+	if string(padded[:4]) != "user" || !bytes.Equal(passwordPadding[:28], padded[4:]) {
+		t.Errorf("padPassword: unexpected result:%X", padded)
+	}
+	if len(padPassword(bytes.Repeat([]byte{'a'}, 40))) != 32 {
+		t.Error("padPassword: long password must be truncated")
+	}
+}
+
+func TestPasswordEncryptionR4(t *testing.T) {
+	id := []byte("0123456789abcdef")
+	for _, test := range []struct {
+		algorithm EncryptionAlgorithm
+		filter    cryptFilter
+		dict      string
+	}{
+		{EncryptionRC4, cryptFilterV2, "/Filter /Standard /V 2 /Length 128 /R 3 "},
+		{EncryptionAES128, cryptFilterAESV2, "/Filter /Standard /V 4 /Length 128 /CF <</StdCF <</AuthEvent /DocOpen /CFM /AESV2 /Length 16>>>> /StmF /StdCF /StrF /StdCF /R 4 "},
+	} {
+		pe := &PasswordEncryption{Algorithm: test.algorithm, UserPassword: "user", OwnerPassword: "owner", Permissions: PermissionPrint}
+		e, err := pe.newEncryption(id, &counterReader{})
+		if err != nil {
+			t.Fatalf("newEncryption: unexpected error:%s", err)
+		}
+		if e.filter != test.filter || !bytes.Contains([]byte(e.compile()), []byte(test.dict)) {
+			t.Errorf("newEncryption: unexpected dictionary:%s", e.compile())
+		}
+		o := entry(t, e, "/O")
+		u := entry(t, e, "/U")
+		// Authenticate the user password.
+		key := fileKeyR4([]byte("user"), o, -3900, id)
+		if !bytes.Equal(e.key, key) {
+			t.Error("newEncryption: user password is not authenticated")
+		}
+		if !bytes.Equal(u[:16], userPasswordValueR4(key, id)[:16]) {
+			t.Error("newEncryption: unexpected U")
+		}
+		// Authenticate the owner password, which decrypts the padded user password from O.
+		ownerKey := md5R4(padPassword([]byte("owner")))
+		user := o
+		for i := 19; i >= 0; i-- {
+			k := make([]byte, len(ownerKey))
+			for j := range ownerKey {
+				k[j] = ownerKey[j] ^ byte(i)
+			}
+			user = rc4Once(k, user)
+		}
+		if !bytes.Equal(padPassword([]byte("user")), user) {
+			t.Error("newEncryption: owner password is not authenticated")
+		}
+	}
+}
+
+// rc4Once encrypts or decrypts the data with RC4.
+func rc4Once(key, data []byte) []byte {
+	c, _ := rc4.NewCipher(key)
+	res := make([]byte, len(data))
+	c.XORKeyStream(res, data)
+	return res
+}
+
+func TestPasswordEncryptionR6(t *testing.T) {
+	pe := &PasswordEncryption{UserPassword: "ユーザー", OwnerPassword: "owner", Permissions: PermissionPrint | PermissionCopy}
+	e, err := pe.newEncryption(nil, &counterReader{})
+	if err != nil {
+		t.Fatalf("newEncryption: unexpected error:%s", err)
+	}
+	if e.filter != cryptFilterAESV3 || len(e.key) != 32 {
+		t.Fatalf("newEncryption: unexpected filter:%s", e.filter.compile())
+	}
+	u := entry(t, e, "/U")
+	o := entry(t, e, "/O")
+	decryptKey := func(intermediate, encrypted []byte) []byte {
+		block, _ := aes.NewCipher(intermediate)
+		res := make([]byte, len(encrypted))
+		cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(res, encrypted)
+		return res
+	}
+	user := []byte("ユーザー")
+	if len(u) != 48 || !bytes.Equal(u[:32], hashR6(user, u[32:40], nil)) {
+		t.Error("newEncryption: user password is not authenticated")
+	}
+	if !bytes.Equal(e.key, decryptKey(hashR6(user, u[40:48], nil), entry(t, e, "/UE"))) {
+		t.Error("newEncryption: UE does not have the file key")
+	}
+	owner := []byte("owner")
+	if len(o) != 48 || !bytes.Equal(o[:32], hashR6(owner, o[32:40], u)) {
+		t.Error("newEncryption: owner password is not authenticated")
+	}
+	if !bytes.Equal(e.key, decryptKey(hashR6(owner, o[40:48], u), entry(t, e, "/OE"))) {
+		t.Error("newEncryption: OE does not have the file key")
+	}
+	perms := entry(t, e, "/Perms")
+	block, _ := aes.NewCipher(e.key)
+	block.Decrypt(perms, perms)
+	if int32(binary.LittleEndian.Uint32(perms)) != (PermissionPrint|PermissionCopy).p() || string(perms[8:12]) != "Tadb" {
+		t.Errorf("newEncryption: unexpected Perms:%X", perms)
+	}
+}
+
+func TestPasswordEncryptionError(t *testing.T) {
+	pe := &PasswordEncryption{Algorithm: EncryptionRC4, UserPassword: "日本"}
+	if _, err := pe.newEncryption(nil, &counterReader{}); err == nil {
+		t.Error("newEncryption: non Latin-1 password must be error")
+	}
+	pe = &PasswordEncryption{Algorithm: EncryptionAlgorithm(10)}
+	if _, err := pe.newEncryption(nil, &counterReader{}); err == nil {
+		t.Error("newEncryption: unknown algorithm must be error")
+	}
+}
+
+func TestHashR6(t *testing.T) {
+	h := hashR6([]byte("password"), []byte("saltsalt"), nil)
+	if len(h) != 32 {
+		t.Fatalf("hashR6: unexpected length:%d", len(h))
+	}
+	if !bytes.Equal(h, hashR6([]byte("password"), []byte("saltsalt"), nil)) {
+		t.Error("hashR6: hash must be deterministic")
+	}
+	if bytes.Equal(h, hashR6([]byte("password"), []byte("saltsalt"), make([]byte, 48))) {
+		t.Error("hashR6: hash must depend on udata")
+	}
+}
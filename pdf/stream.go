@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/zlib"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -14,6 +15,8 @@ type stream struct {
 	dict   map[string]string
 	filter streamFilter
 	data   [][]byte
+	// resources is the resource dictionary of a form XObject.
+	resources *resource
 }
 
 // newDeflatedStream creates Stream that is deflated.
@@ -35,6 +38,21 @@ func newStream(f streamFilter) *stream {
 	}
 }
 
+// newFormXObject creates a deflated form XObject whose bounding box is [0 0 width height].
+func newFormXObject(width, height int) *stream {
+	s := newDeflatedStream()
+	s.dict["/Type"] = "/XObject"
+	s.dict["/Subtype"] = "/Form"
+	s.dict["/BBox"] = NewBox(0, 0, width, height).compile()
+	return s
+}
+
+// walk returns this stream and its resources.
+func (s *stream) walk(walker func(obj pdfObject)) {
+	walker(s)
+	s.resources.walk(walker)
+}
+
 func (s *stream) addStringDatum(datum string) {
 	s.data = append(s.data, []byte(datum))
 }
@@ -43,16 +61,50 @@ func (s *stream) addBinaryDatum(datum []byte) {
 	s.data = append(s.data, datum)
 }
 
-func (s *stream) compile() (res []byte, err error) {
+// reset removes the data.
+func (s *stream) reset() {
+	s.data = make([][]byte, 0)
+}
+
+// resetResources replaces the resources of the form XObject with an empty resource dictionary.
+// The object number of the resources is kept, so that the rebuilt resources are not written as a new object.
+func (s *stream) resetResources() {
+	r := newResource()
+	if s.resources != nil {
+		r.objectIdentifier = s.resources.objectIdentifier
+	}
+	s.resources = r
+}
+
+func (s *stream) compile() ([]byte, error) {
+	return s.compileWith(nil)
+}
+
+// compileWith compiles the stream whose data and strings in the dictionary are encrypted by e.
+// If e is nil, the stream is not encrypted.
+func (s *stream) compileWith(e *encryption) (res []byte, err error) {
 	if s.filter.name() != "" {
 		s.dict["/Filter"] = s.filter.name()
 	}
+	if s.resources != nil {
+		s.dict["/Resources"] = s.resources.indirectReference()
+	}
 	data, err := s.filter.compress(s.data)
 	if err != nil {
 		return
 	}
+	if e != nil {
+		if data, err = e.encrypt(s, data); err != nil {
+			return
+		}
+	}
 	s.dict["/Length"] = strconv.Itoa(len(data))
 	dict := s.dict2pdf()
+	if e != nil {
+		if dict, err = e.encryptStrings(s, dict); err != nil {
+			return
+		}
+	}
 	b := bytes.NewBuffer(make([]byte, 0, len(data)+len(dict)+100))
 	fmt.Fprintf(b, "%d %d obj\n<<%s>>\nstream\n", s.objectNumber, s.generationNumber, dict)
 	b.Write(data)
@@ -62,10 +114,16 @@ func (s *stream) compile() (res []byte, err error) {
 }
 
 // dict2pdf creates the pdf expression of the stream object dictionary.
+// Entries are sorted by the key so that the expression is reproducible.
 func (s *stream) dict2pdf() string {
+	keys := make([]string, 0, len(s.dict))
+	for k := range s.dict {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 	dict := make([]string, 0, len(s.dict))
-	for k, v := range s.dict {
-		dict = append(dict, fmt.Sprintf("%s %s", k, v))
+	for _, k := range keys {
+		dict = append(dict, fmt.Sprintf("%s %s", k, s.dict[k]))
 	}
 	return strings.Join(dict, " ")
 }
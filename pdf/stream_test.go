@@ -106,3 +106,33 @@ func TestDeflatedStream(t *testing.T) {
 	testCompillation(t, expected1, actual[1])
 	testCompillation(t, expected3, string(actual3))
 }
+
+func TestStreamDictSorted(t *testing.T) {
+	s := newFlatStream()
+	s.dict["/C"] = "c"
+	s.dict["/A"] = "a"
+	s.dict["/B"] = "b"
+	testCompillation(t, "/A a /B b /C c", s.dict2pdf())
+}
+
+func TestFormXObject(t *testing.T) {
+	s := newFormXObject(10, 20)
+	s.resources = newResource()
+	s.resources.objectNumber = 7
+	s.addStringDatum("0 0 10 20 re f")
+	if _, err := s.compile(); err != nil {
+		t.Fatalf("compillation failed: unexpected error:%s", err)
+	}
+	testCompillation(t, "/BBox [0 0 10 20] /Filter /FlateDecode /Length 27 /Resources 7 0 R /Subtype /Form /Type /XObject", s.dict2pdf())
+	nums := make([]int, 0)
+	s.walk(func(obj pdfObject) {
+		nums = append(nums, obj.refNo())
+	})
+	if len(nums) != 2 || nums[1] != 7 {
+		t.Errorf("walk: resources are not walked: %v", nums)
+	}
+	s.reset()
+	if len(s.data) != 0 {
+		t.Error("reset: data is not removed")
+	}
+}
@@ -0,0 +1,215 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamState is the state of the builder which writes the pages as soon as they are completed.
+type streamState struct {
+	w *writer
+	// leaf is the Pages node to which the new pages are added.
+	leaf *pageList
+	// pending are the pages which have not been written, in the page order.
+	pending []*page
+	// written is the number of the written pages.
+	written int
+	// fonts are the fonts used by the written pages.
+	// They are written at the end, since their subsets depend on all the pages.
+	fonts []Font
+	// deferred are the fonts and their descendants, which are not written with the pages.
+	deferred map[pdfObject]bool
+	// targets are the named destinations to which the links on the written pages jump.
+	// They are checked at the end, since the destinations are registered on the following pages.
+	targets []namedTarget
+}
+
+// namedTarget is a named destination to which a link on the page of the number jumps.
+type namedTarget struct {
+	name   string
+	number int
+}
+
+func newStreamState(w *writer) *streamState {
+	return &streamState{
+		w:        w,
+		pending:  make([]*page, 0),
+		fonts:    make([]Font, 0),
+		deferred: make(map[pdfObject]bool),
+	}
+}
+
+func (s *streamState) walk(walker func(obj pdfObject)) {
+	if s != nil {
+		for _, f := range s.fonts {
+			f.walk(walker)
+		}
+	}
+}
+
+// StartStreaming writes the header of the document to w, and makes the builder write the pages as soon as WritePage is called,
+// so that the memory does not grow with the number of the pages.
+// The page tree, the fonts and the cross-reference table are written by FinishStreaming.
+// Signatures, document timestamps, linearization and conformance are not supported.
+func (b *Builder) StartStreaming(w io.Writer, options ...BuildOption) error {
+	if b.stream != nil {
+		return errors.New("streaming: streaming has already been started")
+	}
+	o := &buildOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	if o.signature != nil || o.timestamp != nil {
+		return errors.New("streaming: signatures are not supported")
+	}
+	if o.linearized {
+		return errors.New("streaming: linearization is not supported")
+	}
+	if b.conformance != conformanceUndefined {
+		return fmt.Errorf("streaming: %s is not supported", b.conformance)
+	}
+	if o.encryption != nil {
+		if err := b.buildEncryption(o.encryption); err != nil {
+			return err
+		}
+	}
+	// The digests of the objects are not kept, since the streamed document cannot be updated.
+	b.stream = newStreamState(b.newWriter(w).discardDigests().start(b.headerVersion()))
+	// The pages added before streaming are also written by WritePage.
+	pages := b.dc.pages.pages
+	b.dc.pages.pages = nil
+	for _, p := range pages {
+		b.addStreamedPage(p.(*page))
+	}
+	return b.stream.w.err
+}
+
+// WritePage writes the page and the preceding pages which have not been written, and releases them from the memory.
+// The pages must not be modified after they are written.
+// Named destinations, form fields and structure elements are not supported on the written pages.
+func (b *Builder) WritePage(p Page) error {
+	s := b.stream
+	if s == nil {
+		return errors.New("streaming: streaming is not started")
+	}
+	found := false
+	for _, pg := range s.pending {
+		if pg == p {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.New("streaming: page is not in the document or has already been written")
+	}
+	for {
+		pg := s.pending[0]
+		s.pending[0] = nil
+		s.pending = s.pending[1:]
+		if err := b.writeStreamedPage(pg); err != nil {
+			return err
+		}
+		if pg == p {
+			return nil
+		}
+	}
+}
+
+// FinishStreaming writes the pages which have not been written, the rest of the document and the trailer.
+func (b *Builder) FinishStreaming() error {
+	if b.stream == nil {
+		return errors.New("streaming: streaming is not started")
+	}
+	if b.conformance != conformanceUndefined {
+		return fmt.Errorf("streaming: %s is not supported", b.conformance)
+	}
+	if err := b.build(); err != nil {
+		return err
+	}
+	for _, t := range b.stream.targets {
+		if !b.dc.hasDestination(t.name) {
+			return fmt.Errorf("link: named destination is not registered on the page %d: %s", t.number, t.name)
+		}
+	}
+	return b.finish(b.stream.w)
+}
+
+// addStreamedPage adds the page to the leaf of the page tree, which is made for every order pages.
+// The page is numbered at once, so that the written pages can refer to it.
+func (b *Builder) addStreamedPage(p *page) {
+	s := b.stream
+	if s.leaf == nil || len(s.leaf.written)+len(s.leaf.pages) >= b.order {
+		s.leaf = b.dc.pages.newPageList(nil, nil, nil)
+		s.leaf.number(b.c)
+	}
+	s.leaf.addPage(p)
+	p.number(b.c)
+	s.pending = append(s.pending, p)
+}
+
+// writeStreamedPage writes the page with its contents, resources and annotations, and releases the page.
+// The fonts are only numbered, and written at the end.
+func (b *Builder) writeStreamedPage(pg *page) error {
+	s := b.stream
+	number := s.written + 1
+	if err := validatePage(pg, number); err != nil {
+		return err
+	}
+	switch {
+	case b.dc.structTree != nil && pg.untaggedFigures > 0:
+		return fmt.Errorf("tagged: image with the alternate description is not in a figure structure element on the page %d", number)
+	case len(pg.mcids) > 0:
+		return fmt.Errorf("streaming: structure elements are not supported on the written page %d", number)
+	case len(pg.dests) > 0:
+		return fmt.Errorf("streaming: named destinations are not supported on the written page %d", number)
+	}
+	now := b.now()
+	for _, a := range pg.annots {
+		switch o := a.(type) {
+		case *radioButton, formField:
+			return fmt.Errorf("streaming: form fields are not supported on the written page %d", number)
+		case *fileAttachment:
+			o.file.build(now)
+		}
+		if ab, ok := a.(annotationBuilder); ok {
+			ab.build(now)
+		}
+	}
+	for _, name := range namedTargets(pg) {
+		s.targets = append(s.targets, namedTarget{name, number})
+	}
+	if b.dc.labels != nil {
+		b.dc.labels.resolvePage(pg, s.written)
+	}
+	objs := make([]pdfObject, 0)
+	pg.walk(func(obj pdfObject) {
+		objs = append(objs, obj)
+		if f, ok := obj.(Font); ok && !s.deferred[f] {
+			f.walk(func(obj pdfObject) {
+				s.deferred[obj] = true
+			})
+			s.fonts = append(s.fonts, f)
+		}
+	})
+	for _, obj := range objs {
+		if _, ok := obj.(Font); ok || !s.deferred[obj] {
+			obj.number(b.c)
+		}
+	}
+	if pg.resource != nil {
+		// The layers are written with the document catalog.
+		for _, name := range sortedPropertyNames(pg.resource.properties) {
+			pg.resource.properties[name].number(b.c)
+		}
+	}
+	for _, obj := range objs {
+		if !s.deferred[obj] {
+			s.w.writeObj(obj)
+		}
+	}
+	pg.contents.reset()
+	pg.parent.release(pg)
+	s.written++
+	return s.w.err
+}
@@ -0,0 +1,230 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkReferences checks that all indirect references in the uncompressed objects refer to the written objects.
+func checkReferences(t *testing.T, pdf []byte) {
+	t.Helper()
+	for _, m := range regexp.MustCompile(`\b(\d+) 0 R\b`).FindAllSubmatch(pdf, -1) {
+		if !bytes.Contains(pdf, []byte(fmt.Sprintf("\n%s 0 obj\n", m[1]))) {
+			t.Errorf("object %s is referred, but not written", m[1])
+		}
+	}
+}
+
+func TestBuilderStreaming(t *testing.T) {
+	b := NewBuilder(NewBox(0, 0, 200, 200), NewBox(0, 0, 200, 200))
+	b.SetReproducible(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	font := b.NewFontType1("/Helvetica")
+	b.AddFont(font)
+	first := b.AddPage()
+	var buf bytes.Buffer
+	if err := b.StartStreaming(&buf); err != nil {
+		t.Fatalf("StartStreaming: unexpected error:%s", err)
+	}
+	last := b.AddPage()
+	// The written page can refer to the page which has not been written.
+	first.Link(0, 0, 10, 10, LinkPage(last, OutlineDestinationBasic()))
+	first.Note(20, 20, "note")
+	pages := []Page{first, last}
+	for i := 0; i < 20; i++ {
+		p := b.AddPage()
+		pages = append(pages, p)
+		p.WriteText(10, 10, font, 10, fmt.Sprintf("page%d", i))
+		if i == 3 {
+			own := b.NewFontType1("/Courier")
+			p.AddFont(own)
+			p.WriteText(10, 30, own, 10, "own")
+			b.AddPageLabel(p, PageLabelRomanLower, "", 1)
+		}
+	}
+	if err := b.WritePage(pages[5]); err != nil {
+		t.Fatalf("WritePage: unexpected error:%s", err)
+	}
+	// The preceding pages are also written, and released from the memory.
+	written := buf.Len()
+	for _, p := range pages[:6] {
+		if len(p.(*page).contents.data) != 0 {
+			t.Error("WritePage: contents are not released")
+		}
+		if !bytes.Contains(buf.Bytes(), []byte(fmt.Sprintf("%d 0 obj\n<</Type /Page /Parent ", p.refNo()))) {
+			t.Errorf("WritePage: page %d is not written", p.refNo())
+		}
+	}
+	if n := len(b.dc.pages.allPages()); n != len(pages)-6 {
+		t.Errorf("WritePage: pages are not released:%d", n)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("/BaseFont /Courier")) {
+		t.Error("WritePage: fonts must be written at the end")
+	}
+	if b.stream.w.digests != nil {
+		t.Error("WritePage: digests of the written objects must not be kept")
+	}
+	if err := b.WritePage(pages[len(pages)-2]); err != nil {
+		t.Fatalf("WritePage: unexpected error:%s", err)
+	}
+	if err := b.FinishStreaming(); err != nil {
+		t.Fatalf("FinishStreaming: unexpected error:%s", err)
+	}
+	if b.revision != nil {
+		t.Error("FinishStreaming: digests of the objects must not be kept")
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.7\n")) || written == 0 {
+		t.Errorf("StartStreaming: header is not written:%q", out[:9])
+	}
+	checkLastXRef(t, out)
+	checkReferences(t, out)
+	for _, expected := range []string{
+		"2 0 obj\n<</Type /Pages /MediaBox [0 0 200 200] /CropBox [0 0 200 200] /Resources ",
+		fmt.Sprintf("/Count %d>>", len(pages)),
+		"/BaseFont /Courier",
+		fmt.Sprintf("/D [%s /Fit]", last.indirectReference()),
+		"/PageLabels <</Nums [0 <</S /D>> 5 <</S /r>>]>>",
+	} {
+		if !bytes.Contains(out, []byte(expected)) {
+			t.Errorf("FinishStreaming: output does not contain:%q", expected)
+		}
+	}
+	if n := bytes.Count(out, []byte("<</Type /Page /Parent ")); n != len(pages) {
+		t.Errorf("FinishStreaming: unexpected number of pages:%d", n)
+	}
+	// The page tree is balanced with the leaves made while streaming.
+	var kids []string
+	for _, m := range regexp.MustCompile(`/Kids \[([^\]]*)\]`).FindAllSubmatch(out, -1) {
+		kids = append(kids, string(m[1]))
+	}
+	for _, k := range kids {
+		if n := len(strings.Fields(k)) / 3; n > pageTreeOrder {
+			t.Errorf("FinishStreaming: too many kids:%s", k)
+		}
+	}
+}
+
+func TestBuilderStreamingObjectStreams(t *testing.T) {
+	for _, encryption := range []Encryption{nil, &PasswordEncryption{Algorithm: EncryptionAES128}} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		b.SetObjectStreams(true)
+		b.SetInfo(DocumentInfo{Title: "Statement"})
+		options := make([]BuildOption, 0)
+		if encryption != nil {
+			options = append(options, WithEncryption(encryption))
+		}
+		var buf bytes.Buffer
+		if err := b.StartStreaming(&buf, options...); err != nil {
+			t.Fatalf("StartStreaming: unexpected error:%s", err)
+		}
+		for i := 0; i < 3*objectStreamCapacity; i++ {
+			p := b.AddPage()
+			p.Rectangle(0, 0, 10, 10).Render()
+			if err := b.WritePage(p); err != nil {
+				t.Fatalf("WritePage: unexpected error:%s", err)
+			}
+		}
+		// Full object streams are written while streaming.
+		if !bytes.Contains(buf.Bytes(), []byte("/Type /ObjStm")) {
+			t.Error("WritePage: object stream is not written")
+		}
+		if err := b.FinishStreaming(); err != nil {
+			t.Fatalf("FinishStreaming: unexpected error:%s", err)
+		}
+		out := buf.Bytes()
+		dict := checkXRefStream(t, out)
+		if (encryption != nil) != strings.Contains(dict, "/Encrypt ") {
+			t.Errorf("FinishStreaming: unexpected trailer:%s", dict)
+		}
+		if bytes.Contains(out, []byte("(Statement)")) {
+			t.Error("FinishStreaming: information is not packed")
+		}
+	}
+}
+
+func TestBuilderStreamingError(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	p := b.AddPage()
+	if err := b.WritePage(p); err == nil || err.Error() != "streaming: streaming is not started" {
+		t.Errorf("WritePage: unexpected error:%v", err)
+	}
+	if err := b.FinishStreaming(); err == nil {
+		t.Error("FinishStreaming: streaming must be started")
+	}
+	cert, key := newTestCertificate(t, "signer")
+	sf := p.SignatureField("approval", 0, 0, 0, 0)
+	var buf bytes.Buffer
+	if err := b.StartStreaming(&buf, WithSignature(sf, key, []*x509.Certificate{cert})); err == nil || err.Error() != "streaming: signatures are not supported" {
+		t.Errorf("StartStreaming: unexpected error:%v", err)
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetConformance(ConformancePDFA2B)
+	if err := b.StartStreaming(&buf); err == nil || err.Error() != "streaming: PDF/A-2b is not supported" {
+		t.Errorf("StartStreaming: unexpected error:%v", err)
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	if err := b.StartStreaming(&buf); err != nil {
+		t.Fatalf("StartStreaming: unexpected error:%s", err)
+	}
+	if err := b.StartStreaming(&buf); err == nil {
+		t.Error("StartStreaming: streaming must not be started twice")
+	}
+	if err := b.Build(&buf); err == nil || err.Error() != "streaming: document is being written, use FinishStreaming" {
+		t.Errorf("Build: unexpected error:%v", err)
+	}
+	p = b.AddPage()
+	if err := b.WritePage(p); err != nil {
+		t.Fatalf("WritePage: unexpected error:%s", err)
+	}
+	if err := b.WritePage(p); err == nil || err.Error() != "streaming: page is not in the document or has already been written" {
+		t.Errorf("WritePage: unexpected error:%v", err)
+	}
+	for _, c := range []struct {
+		prepare  func(p Page)
+		expected string
+	}{
+		{func(p Page) { p.AddDestination("dest", 0, 0) }, "streaming: named destinations are not supported on the written page 2"},
+		{func(p Page) { p.TextField("name", 0, 0, 10, 10) }, "streaming: form fields are not supported on the written page 2"},
+		{func(p Page) { p.BeginArtifact() }, "page: marked content is not ended on the page 2"},
+		{func(p Page) {
+			p.BeginStructure(b.StructureTree().AddElement(StructureRoleP))
+			p.EndMarkedContent()
+		}, "streaming: structure elements are not supported on the written page 2"},
+		{func(p Page) { p.SetRotate(45) }, "page: rotation must be a multiple of 90 on the page 2: 45"},
+	} {
+		p := b.AddPage()
+		c.prepare(p)
+		if err := b.WritePage(p); err == nil || err.Error() != c.expected {
+			t.Errorf("WritePage: unexpected error:%v", err)
+		}
+	}
+}
+
+func TestBuilderStreamingNamedLink(t *testing.T) {
+	for _, name := range []string{"summary", "missing"} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		var buf bytes.Buffer
+		if err := b.StartStreaming(&buf); err != nil {
+			t.Fatalf("StartStreaming: unexpected error:%s", err)
+		}
+		p := b.AddPage()
+		p.Link(0, 0, 10, 10, LinkNamed(name))
+		if err := b.WritePage(p); err != nil {
+			t.Fatalf("WritePage: unexpected error:%s", err)
+		}
+		// The destination is registered on the page which has not been written.
+		b.AddPage().AddDestination("summary", 0, 0)
+		err := b.FinishStreaming()
+		if name == "summary" && err != nil {
+			t.Errorf("FinishStreaming: unexpected error:%s", err)
+		}
+		if name == "missing" && (err == nil || err.Error() != "link: named destination is not registered on the page 1: missing") {
+			t.Errorf("FinishStreaming: unexpected error:%v", err)
+		}
+	}
+}
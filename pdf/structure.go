@@ -0,0 +1,262 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StructureRole is a standard structure type of a structure element.
+type StructureRole int
+
+const (
+	structureRoleUndefined StructureRole = iota
+	// StructureRoleDocument : The whole document.
+	StructureRoleDocument
+	// StructureRoleSect : A section, a generic container of related elements.
+	StructureRoleSect
+	// StructureRoleH1 : A level 1 heading.
+	StructureRoleH1
+	// StructureRoleH2 : A level 2 heading.
+	StructureRoleH2
+	// StructureRoleH3 : A level 3 heading.
+	StructureRoleH3
+	// StructureRoleH4 : A level 4 heading.
+	StructureRoleH4
+	// StructureRoleH5 : A level 5 heading.
+	StructureRoleH5
+	// StructureRoleH6 : A level 6 heading.
+	StructureRoleH6
+	// StructureRoleP : A paragraph.
+	StructureRoleP
+	// StructureRoleSpan : A generic inline portion of text.
+	StructureRoleSpan
+	// StructureRoleL : A list, whose children are list items.
+	StructureRoleL
+	// StructureRoleLI : A list item, whose children are a label and a list body.
+	StructureRoleLI
+	// StructureRoleLbl : A label of a list item, such as a bullet or a number.
+	StructureRoleLbl
+	// StructureRoleLBody : A body of a list item.
+	StructureRoleLBody
+	// StructureRoleTable : A table, whose children are table rows.
+	StructureRoleTable
+	// StructureRoleTR : A table row, whose children are table headers and table data cells.
+	StructureRoleTR
+	// StructureRoleTH : A table header cell.
+	StructureRoleTH
+	// StructureRoleTD : A table data cell.
+	StructureRoleTD
+	// StructureRoleFigure : A graphic, which requires the alternate description.
+	StructureRoleFigure
+)
+
+func (r StructureRole) compile() string {
+	switch r {
+	case StructureRoleDocument:
+		return "/Document"
+	case StructureRoleSect:
+		return "/Sect"
+	case StructureRoleH1:
+		return "/H1"
+	case StructureRoleH2:
+		return "/H2"
+	case StructureRoleH3:
+		return "/H3"
+	case StructureRoleH4:
+		return "/H4"
+	case StructureRoleH5:
+		return "/H5"
+	case StructureRoleH6:
+		return "/H6"
+	case StructureRoleP:
+		return "/P"
+	case StructureRoleSpan:
+		return "/Span"
+	case StructureRoleL:
+		return "/L"
+	case StructureRoleLI:
+		return "/LI"
+	case StructureRoleLbl:
+		return "/Lbl"
+	case StructureRoleLBody:
+		return "/LBody"
+	case StructureRoleTable:
+		return "/Table"
+	case StructureRoleTR:
+		return "/TR"
+	case StructureRoleTH:
+		return "/TH"
+	case StructureRoleTD:
+		return "/TD"
+	case StructureRoleFigure:
+		return "/Figure"
+	default:
+		return ""
+	}
+}
+
+// StructureElement is a node of the logical structure of the document.
+// Contents of pages are associated with the element by Page.BeginStructure.
+type StructureElement interface {
+	// AddElement adds a new structure element to its children.
+	AddElement(role StructureRole) StructureElement
+	// Title specifies the title of the element.
+	Title(title string) StructureElement
+	// Alt specifies the alternate description of the element, which is required for figures.
+	Alt(text string) StructureElement
+	// element returns the implementation, so that the interface is not implemented outside the package.
+	element() *structElem
+}
+
+// markedContentReference is a marked-content sequence on a page which belongs to a structure element.
+type markedContentReference struct {
+	page Page
+	mcid int
+}
+
+// structElem is a structure element.
+type structElem struct {
+	objectIdentifier
+	role   StructureRole
+	parent pdfObject
+	title  string
+	alt    string
+	// kids are the children of the element, which are *structElem or markedContentReference.
+	kids []interface{}
+}
+
+func newStructElem(role StructureRole, parent pdfObject) *structElem {
+	return &structElem{
+		objectIdentifier: objectIdentifier{},
+		role:             role,
+		parent:           parent,
+		kids:             make([]interface{}, 0),
+	}
+}
+
+func (se *structElem) AddElement(role StructureRole) StructureElement {
+	child := newStructElem(role, se)
+	se.kids = append(se.kids, child)
+	return child
+}
+
+func (se *structElem) Title(title string) StructureElement {
+	se.title = title
+	return se
+}
+
+func (se *structElem) Alt(text string) StructureElement {
+	se.alt = text
+	return se
+}
+
+func (se *structElem) element() *structElem {
+	return se
+}
+
+// page returns the page of the first marked content of the element, or nil.
+func (se *structElem) page() Page {
+	for _, k := range se.kids {
+		if mcr, ok := k.(markedContentReference); ok {
+			return mcr.page
+		}
+	}
+	return nil
+}
+
+func (se *structElem) compile() string {
+	dict := make([]string, 0, 6)
+	dict = append(dict, fmt.Sprintf("/Type /StructElem /S %s /P %s", se.role.compile(), se.parent.indirectReference()))
+	pg := se.page()
+	if pg != nil {
+		dict = append(dict, fmt.Sprintf("/Pg %s", pg.indirectReference()))
+	}
+	if se.title != "" {
+		dict = append(dict, fmt.Sprintf("/T %s", textString(se.title)))
+	}
+	if se.alt != "" {
+		dict = append(dict, fmt.Sprintf("/Alt %s", textString(se.alt)))
+	}
+	if len(se.kids) > 0 {
+		kids := make([]string, 0, len(se.kids))
+		for _, k := range se.kids {
+			switch kid := k.(type) {
+			case *structElem:
+				kids = append(kids, kid.indirectReference())
+			case markedContentReference:
+				// Marked contents on the other page than /Pg are referred with their page.
+				if kid.page == pg {
+					kids = append(kids, fmt.Sprintf("%d", kid.mcid))
+				} else {
+					kids = append(kids, fmt.Sprintf("<</Type /MCR /Pg %s /MCID %d>>", kid.page.indirectReference(), kid.mcid))
+				}
+			}
+		}
+		dict = append(dict, fmt.Sprintf("/K [%s]", strings.Join(kids, " ")))
+	}
+	return se.bracket(fmt.Sprintf("<<%s>>", strings.Join(dict, " ")))
+}
+
+func (se *structElem) walk(walker func(obj pdfObject)) {
+	walker(se)
+	for _, k := range se.kids {
+		if child, ok := k.(*structElem); ok {
+			child.walk(walker)
+		}
+	}
+}
+
+// structTreeRoot is the root of the structure hierarchy.
+type structTreeRoot struct {
+	objectIdentifier
+	document *structElem
+	// pages are the pages which have marked contents, indexed by their /StructParents.
+	pages []*page
+}
+
+func newStructTreeRoot() *structTreeRoot {
+	st := &structTreeRoot{
+		objectIdentifier: objectIdentifier{},
+	}
+	st.document = newStructElem(StructureRoleDocument, st)
+	return st
+}
+
+// build assigns the keys of the parent tree to the pages which have marked contents.
+func (st *structTreeRoot) build(pages []Page) error {
+	st.pages = make([]*page, 0)
+	for i, p := range pages {
+		pg, ok := p.(*page)
+		if !ok {
+			continue
+		}
+		if pg.untaggedFigures > 0 {
+			return fmt.Errorf("tagged: image with the alternate description is not in a figure structure element on the page %d", i+1)
+		}
+		if len(pg.mcids) > 0 {
+			pg.structParents = len(st.pages)
+			st.pages = append(st.pages, pg)
+		}
+	}
+	return nil
+}
+
+func (st *structTreeRoot) compile() string {
+	nums := make([]string, 0, len(st.pages))
+	for key, p := range st.pages {
+		parents := make([]string, 0, len(p.mcids))
+		for _, se := range p.mcids {
+			parents = append(parents, se.indirectReference())
+		}
+		nums = append(nums, fmt.Sprintf("%d [%s]", key, strings.Join(parents, " ")))
+	}
+	return st.bracket(fmt.Sprintf("<</Type /StructTreeRoot /K %s /ParentTree <</Nums [%s]>> /ParentTreeNextKey %d>>",
+		st.document.indirectReference(), strings.Join(nums, " "), len(st.pages)))
+}
+
+func (st *structTreeRoot) walk(walker func(obj pdfObject)) {
+	if st != nil {
+		walker(st)
+		st.document.walk(walker)
+	}
+}
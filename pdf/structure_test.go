@@ -0,0 +1,154 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStructElem(t *testing.T) {
+	st := newStructTreeRoot()
+	st.objectNumber = 4
+	doc := st.document
+	doc.objectNumber = 5
+	h1 := doc.AddElement(StructureRoleH1).Title("Chapter 1").(*structElem)
+	h1.objectNumber = 6
+	fig := doc.AddElement(StructureRoleFigure).Alt("Logo").(*structElem)
+	fig.objectNumber = 7
+	if len(doc.kids) != 2 || doc.kids[0] != h1 || doc.kids[1] != fig {
+		t.Fatalf("AddElement: unexpected children:%v", doc.kids)
+	}
+	testCompillation(t, "5 0 obj\n<</Type /StructElem /S /Document /P 4 0 R /K [6 0 R 7 0 R]>>\nendobj\n", doc.compile())
+	p1, p2 := newTestPage(), newTestPage()
+	p2.objectNumber = 8
+	p1.BeginStructure(h1)
+	p1.EndMarkedContent()
+	p2.BeginStructure(h1)
+	p2.EndMarkedContent()
+	expected := "6 0 obj\n<</Type /StructElem /S /H1 /P 5 0 R /Pg 3 0 R /T (Chapter 1) /K [0 <</Type /MCR /Pg 8 0 R /MCID 0>>]>>\nendobj\n"
+	testCompillation(t, expected, h1.compile())
+	testCompillation(t, "7 0 obj\n<</Type /StructElem /S /Figure /P 5 0 R /Alt (Logo)>>\nendobj\n", fig.compile())
+	walked := make([]pdfObject, 0)
+	st.walk(func(obj pdfObject) {
+		walked = append(walked, obj)
+	})
+	if len(walked) != 4 || walked[0] != st || walked[1] != doc || walked[2] != h1 || walked[3] != fig {
+		t.Errorf("walk: unexpected objects:%v", walked)
+	}
+}
+
+func TestStructTreeRoot(t *testing.T) {
+	st := newStructTreeRoot()
+	st.objectNumber = 4
+	st.document.objectNumber = 5
+	p := st.document.AddElement(StructureRoleP).(*structElem)
+	p.objectNumber = 6
+	span := p.AddElement(StructureRoleSpan).(*structElem)
+	span.objectNumber = 7
+	untagged, tagged := newTestPage(), newTestPage()
+	tagged.BeginStructure(p)
+	tagged.BeginStructure(span)
+	tagged.EndMarkedContent()
+	tagged.EndMarkedContent()
+	if err := st.build([]Page{untagged, tagged}); err != nil {
+		t.Fatalf("build: unexpected error:%s", err)
+	}
+	if tagged.structParents != 0 || len(st.pages) != 1 {
+		t.Errorf("build: unexpected key:%d", tagged.structParents)
+	}
+	expected := "4 0 obj\n<</Type /StructTreeRoot /K 5 0 R /ParentTree <</Nums [0 [6 0 R 7 0 R]]>> /ParentTreeNextKey 1>>\nendobj\n"
+	testCompillation(t, expected, st.compile())
+	tagged.BeginArtifact()
+	if err := validatePages([]Page{untagged, tagged}, 0); err == nil || err.Error() != "page: marked content is not ended on the page 2" {
+		t.Errorf("validatePages: unexpected error:%v", err)
+	}
+}
+
+func TestPageMarkedContent(t *testing.T) {
+	p := newTestPage()
+	st := newStructTreeRoot()
+	p.BeginArtifact()
+	p.Line(0, 0, 10, 0, 1).Render()
+	p.EndMarkedContent()
+	p.EndMarkedContent()
+	p.BeginStructure(st.document.AddElement(StructureRoleP))
+	p.EndMarkedContent()
+	actual := string(bytes.Join(p.contents.data, nil))
+	expected := "/Artifact BMC\nq 10 180 m 20 180 l 1 w  S Q\nEMC\n/P <</MCID 0>> BDC\nEMC\n"
+	testRendering(t, expected, actual)
+	if p.untaggedContents != 0 {
+		t.Errorf("render: artifact is counted as the untagged content:%d", p.untaggedContents)
+	}
+	p.BeginLayer(newOptionalContentGroup("Grid", "/OC0", true))
+	p.Line(0, 0, 10, 0, 1).Render()
+	p.EndMarkedContent()
+	if p.untaggedContents != 1 {
+		t.Errorf("render: content in the layer is not counted as the untagged content:%d", p.untaggedContents)
+	}
+	if !strings.Contains(p.compile(), "/StructParents 0") {
+		t.Error("compile: StructParents is not found")
+	}
+}
+
+func TestImageAlt(t *testing.T) {
+	p := newTestPage()
+	ir := newImageResource("/Im1", 10, 10, 8, make([]byte, 100))
+	p.Image(ir, 20, 20).Alt("Chart").Render()
+	if actual := string(p.contents.data[0]); !strings.HasPrefix(actual, "/Figure <</Alt (Chart)>> BDC\nq ") || !strings.HasSuffix(actual, "Do Q\nEMC\n") {
+		t.Errorf("render: figure is not marked:%q", actual)
+	}
+	fig := newStructTreeRoot().document.AddElement(StructureRoleFigure).(*structElem)
+	p.BeginStructure(fig)
+	p.Image(ir, 20, 20).Alt("Photo").Render()
+	p.EndMarkedContent()
+	if fig.alt != "Photo" {
+		t.Errorf("render: alternate description is not given to the figure:%s", fig.alt)
+	}
+	if actual := string(p.contents.data[2]); !strings.HasPrefix(actual, "q ") {
+		t.Errorf("render: figure in the figure element must not be marked:%q", actual)
+	}
+	// The marked figure is not in the logical structure of the tagged document.
+	if err := newStructTreeRoot().build([]Page{p}); err == nil || err.Error() != "tagged: image with the alternate description is not in a figure structure element on the page 1" {
+		t.Errorf("build: unexpected error:%v", err)
+	}
+}
+
+func TestPageBeginStructureNil(t *testing.T) {
+	var se *structElem
+	for _, e := range []StructureElement{nil, se} {
+		p := newTestPage()
+		p.BeginStructure(e)
+		p.EndMarkedContent()
+		if len(p.mcids) != 0 || len(p.markedContents) != 0 {
+			t.Error("BeginStructure: nil element must not be marked")
+		}
+		if err := validatePage(p, 1); err == nil || err.Error() != "tagged: structure element is not specified on the page 1" {
+			t.Errorf("validatePage: unexpected error:%v", err)
+		}
+	}
+}
+
+func TestBuilderStructureTree(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetReproducible(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	b.SetLanguage("en-US")
+	doc := b.StructureTree()
+	if b.StructureTree() != doc {
+		t.Error("StructureTree: document element is dup")
+	}
+	p := b.AddPage()
+	p.BeginStructure(doc.AddElement(StructureRoleH1))
+	p.Rectangle(0, 0, 10, 10).Render()
+	p.EndMarkedContent()
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.String()
+	for _, entry := range []string{"/MarkInfo <</Marked true>> /StructTreeRoot ", "/Lang (en-US)", "/StructParents 0", "/Type /StructElem /S /H1"} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+}
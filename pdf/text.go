@@ -26,6 +26,8 @@ type Font interface {
 	// build setup a embeded font program for writing pdf document.
 	build() error
 	createText(x, y int, fontSize int, text string) string
+	// embedded returns whether the font program is embedded.
+	embedded() bool
 }
 
 // defaultFont provides a common functionality of pdf font dictionary.
@@ -69,6 +71,10 @@ func (f *type1Font) subtype() string {
 	return "/Type1"
 }
 
+func (f *type1Font) embedded() bool {
+	return false
+}
+
 func (f *type1Font) walk(walker func(obj pdfObject)) {
 	walker(f)
 }
@@ -148,6 +154,10 @@ func (f *compositeFont) subtype() string {
 	return "/Type0"
 }
 
+func (f *compositeFont) embedded() bool {
+	return f.descendantFont.embedded()
+}
+
 func (f *compositeFont) walk(walker func(obj pdfObject)) {
 	walker(f)
 	f.descendantFont.walk(walker)
@@ -201,6 +211,8 @@ type CIDFont interface {
 	parentBaseFont(cmapName string) string
 	// build embeded font program
 	build() error
+	// embedded returns whether the font program is embedded.
+	embedded() bool
 	// create text operator
 	createText(fontName string, x, y int, fontSize int, text string) string
 	// The PostScript name of the CIDFont.
@@ -322,6 +334,11 @@ func (f *cidFontSubType0) compile() string {
 	return f.compileHelper(f.SubType())
 }
 
+// embedded returns false, since CFF font programs are not embedded yet.
+func (f *cidFontSubType0) embedded() bool {
+	return false
+}
+
 func (f *cidFontSubType0) build() error {
 	return nil
 }
@@ -371,6 +388,10 @@ func (f *cidFontSubType2) compile() string {
 	return f.compileHelper(f.SubType())
 }
 
+func (f *cidFontSubType2) embedded() bool {
+	return true
+}
+
 func (f *cidFontSubType2) build() error {
 	list := make([]uint16, len(f.newGIDMap))
 	for base, new := range f.newGIDMap {
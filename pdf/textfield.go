@@ -0,0 +1,159 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// textFieldFlagMultiline : The field may contain multiple lines of text.
+	textFieldFlagMultiline fieldFlag = 1 << 12
+	// textFieldFlagPassword : The field is intended for entering a secure password that should not be echoed visibly to the screen.
+	textFieldFlagPassword fieldFlag = 1 << 13
+)
+
+// textFieldPadding is the space between the border and the text of a text field.
+const textFieldPadding = 2
+
+// TextField is a text field of the interactive form.
+// Its appearance stream is generated when the document is built.
+type TextField interface {
+	Annotation
+	// Value specifies the value of the field.
+	Value(value string) TextField
+	// DefaultValue specifies the value to which the field reverts when a reset-form action is executed.
+	DefaultValue(value string) TextField
+	// MaxLength specifies the maximum length of the field’s text, in characters.
+	MaxLength(maxLength int) TextField
+	// Multiline makes the field contain multiple lines of text.
+	Multiline() TextField
+	// Password makes the field hide the text.
+	Password() TextField
+	// ReadOnly prohibits the user from changing the value of the field.
+	ReadOnly() TextField
+	// Required makes the field have a value when the form is submitted.
+	Required() TextField
+	// Font specifies the font and its size of the text.
+	// The font must be registered by Builder.AddFont, otherwise Build returns an error.
+	// If the font is not specified, a font registered by Builder.AddFont is used.
+	Font(font Font, fontSize int) TextField
+}
+
+// textField is a implementation of a TextField interface.
+type textField struct {
+	field
+	variableText
+	value        string
+	defaultValue string
+	maxLength    int
+}
+
+func newTextField(name string, page Page, rect *Box) *textField {
+	return &textField{
+		field:        newField("/Tx", name, page, rect),
+		variableText: variableText{fontSize: 12},
+	}
+}
+
+func (tf *textField) Value(value string) TextField {
+	tf.value = value
+	return tf
+}
+
+func (tf *textField) DefaultValue(value string) TextField {
+	tf.defaultValue = value
+	return tf
+}
+
+func (tf *textField) MaxLength(maxLength int) TextField {
+	tf.maxLength = maxLength
+	return tf
+}
+
+func (tf *textField) Multiline() TextField {
+	tf.flags |= textFieldFlagMultiline
+	return tf
+}
+
+func (tf *textField) Password() TextField {
+	tf.flags |= textFieldFlagPassword
+	return tf
+}
+
+func (tf *textField) ReadOnly() TextField {
+	tf.flags |= fieldFlagReadOnly
+	return tf
+}
+
+func (tf *textField) Required() TextField {
+	tf.flags |= fieldFlagRequired
+	return tf
+}
+
+func (tf *textField) Font(font Font, fontSize int) TextField {
+	tf.font = font
+	tf.fontSize = fontSize
+	return tf
+}
+
+// displayedText is the text painted in the appearance stream.
+// The text of a password field is replaced by asterisks.
+func (tf *textField) displayedText() string {
+	text := tf.value
+	if tf.maxLength > 0 {
+		runes := []rune(text)
+		if len(runes) > tf.maxLength {
+			text = string(runes[:tf.maxLength])
+		}
+	}
+	if tf.flags&textFieldFlagPassword != 0 {
+		return strings.Repeat("*", len([]rune(text)))
+	}
+	if tf.flags&textFieldFlagMultiline == 0 {
+		return strings.Replace(text, "\n", " ", -1)
+	}
+	return text
+}
+
+func (tf *textField) buildField(dr *resource) error {
+	if err := tf.resolveFont("text field", dr); err != nil {
+		return err
+	}
+	tf.appearance.reset()
+	tf.appearance.resetResources()
+	tf.appearance.resources.addFont(tf.font)
+	// The variable text is enclosed by the marked-content so that viewers can regenerate it.
+	tf.appearance.addStringDatum("/Tx BMC\n")
+	if text := tf.displayedText(); text != "" {
+		y := tf.height() - tf.fontSize - textFieldPadding
+		if tf.flags&textFieldFlagMultiline == 0 {
+			// A single line is vertically centered.
+			y = (tf.height() - tf.fontSize) / 2
+		}
+		tf.appearance.addStringDatum(tf.font.createText(textFieldPadding, y, tf.fontSize, text))
+	}
+	tf.appearance.addStringDatum("EMC\n")
+	return nil
+}
+
+func (tf *textField) compile() string {
+	entries := make([]string, 0, 4)
+	if tf.font != nil {
+		entries = append(entries, tf.da())
+	}
+	if tf.value != "" {
+		entries = append(entries, fmt.Sprintf("/V %s", textString(tf.value)))
+	}
+	if tf.defaultValue != "" {
+		entries = append(entries, fmt.Sprintf("/DV %s", textString(tf.defaultValue)))
+	}
+	if tf.maxLength > 0 {
+		entries = append(entries, fmt.Sprintf("/MaxLen %d", tf.maxLength))
+	}
+	return tf.compileHelper(entries...)
+}
+
+func (tf *textField) walk(walker func(obj pdfObject)) {
+	walker(tf)
+	tf.appearance.walk(walker)
+}
@@ -0,0 +1,60 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextField(t *testing.T) {
+	p := newTestPage()
+	f := newFontType1("/F0", "/Helvetica")
+	field := p.TextField("name", 0, 0, 100, 20)
+	if len(p.annotations()) != 1 || p.annotations()[0] != field {
+		t.Fatal("TextField: field is not added to the page")
+	}
+	tf := field.Value("value").DefaultValue("default").MaxLength(10).ReadOnly().Required().Font(f, 10).(*textField)
+	if err := tf.buildField(newTestDefaultResource(f)); err != nil {
+		t.Fatalf("buildField: unexpected error:%s", err)
+	}
+	tf.objectNumber = 4
+	tf.appearance.objectNumber = 5
+	expected := "4 0 obj\n<</Type /Annot /Subtype /Widget /Rect [10 160 110 180] /P 3 0 R /F 4 /FT /Tx /T (name) /Ff 3 /AP <</N 5 0 R>> /DA (/F0 10 Tf 0 g) /V (value) /DV (default) /MaxLen 10>>\nendobj\n"
+	testCompillation(t, expected, tf.compile())
+	testRendering(t, "/Tx BMC\n", string(tf.appearance.data[0]))
+	testRendering(t, f.createText(2, 5, 10, "value"), string(tf.appearance.data[1]))
+	testRendering(t, "EMC\n", string(tf.appearance.data[2]))
+	if tf.appearance.resources.font["/F0"] != f {
+		t.Error("buildField: font is not added to the appearance resources")
+	}
+}
+
+func TestTextFieldDisplayedText(t *testing.T) {
+	tf := newTextField("name", newTestPage(), NewBox(0, 0, 10, 10))
+	tf.Value("a\nb")
+	testCompillation(t, "a b", tf.displayedText())
+	tf.Multiline()
+	testCompillation(t, "a\nb", tf.displayedText())
+	tf.Password()
+	testCompillation(t, "***", tf.displayedText())
+	tf.MaxLength(2)
+	testCompillation(t, "**", tf.displayedText())
+	if tf.flags != textFieldFlagMultiline|textFieldFlagPassword {
+		t.Errorf("flags: unexpected flags:%d", tf.flags)
+	}
+}
+
+func TestTextFieldWithoutFont(t *testing.T) {
+	tf := newTextField("name", newTestPage(), NewBox(0, 0, 10, 10))
+	if err := tf.buildField(newResource()); err == nil {
+		t.Error("buildField: field without font must be error")
+	}
+	tf.Value("")
+	f := newFontType1("/F0", "/Helvetica")
+	tf.Font(f, 10)
+	if err := tf.buildField(newTestDefaultResource(f)); err != nil {
+		t.Fatalf("buildField: unexpected error:%s", err)
+	}
+	if strings.Contains(string(tf.appearance.data[1]), "BT") {
+		t.Error("buildField: empty value must not be painted")
+	}
+}
@@ -0,0 +1,73 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// textString returns the pdf expression of a text string.
+// A text string which consists of printable ASCII characters is written as a literal string,
+// otherwise it is encoded in UTF-16BE with a leading byte order marker.
+func textString(s string) string {
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			return utf16String(s)
+		}
+	}
+	return literalString(s)
+}
+
+// literalString returns the literal string expression of s.
+// Parentheses and backslashes are escaped.
+func literalString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+	return "(" + r.Replace(s) + ")"
+}
+
+// utf16String returns the hexadecimal string expression of s encoded in UTF-16BE.
+func utf16String(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, w := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", w)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// hexString returns the hexadecimal string expression of data.
+func hexString(data []byte) string {
+	return fmt.Sprintf("<%X>", data)
+}
+
+// dateString returns the pdf expression of a date, that is (D:YYYYMMDDHHmmSSOHH'mm').
+func dateString(t time.Time) string {
+	_, offset := t.Zone()
+	tz := "Z"
+	if offset != 0 {
+		sign := "+"
+		if offset < 0 {
+			sign = "-"
+			offset = -offset
+		}
+		tz = fmt.Sprintf("%s%02d'%02d'", sign, offset/3600, offset%3600/60)
+	}
+	return fmt.Sprintf("(D:%s%s)", t.Format("20060102150405"), tz)
+}
+
+// nameObject returns the pdf expression of a name object whose value is s.
+// Regular characters are written as they are, and the others are written as #xx.
+func nameObject(s string) string {
+	var b strings.Builder
+	b.WriteString("/")
+	for _, c := range []byte(s) {
+		if c > 0x20 && c < 0x7f && !strings.ContainsRune("#()<>[]{}/%", rune(c)) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "#%02X", c)
+		}
+	}
+	return b.String()
+}
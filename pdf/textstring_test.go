@@ -0,0 +1,32 @@
+package pdf
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTextString(t *testing.T) {
+	testCompillation(t, "(abc)", textString("abc"))
+	testCompillation(t, `(a\(b\)c\\)`, textString(`a(b)c\`))
+	testCompillation(t, "<FEFF3042>", textString("あ"))
+	testCompillation(t, "<FEFF0061000A>", textString("a\n"))
+}
+
+func TestHexString(t *testing.T) {
+	testCompillation(t, "<00FF10>", hexString([]byte{0, 255, 16}))
+}
+
+func TestDateString(t *testing.T) {
+	utc := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	testCompillation(t, "(D:20180102030405Z)", dateString(utc))
+	jst := time.Date(2018, 1, 2, 3, 4, 5, 0, time.FixedZone("JST", 9*60*60))
+	testCompillation(t, "(D:20180102030405+09'00')", dateString(jst))
+	nst := time.Date(2018, 1, 2, 3, 4, 5, 0, time.FixedZone("NST", -(3*60*60+30*60)))
+	testCompillation(t, "(D:20180102030405-03'30')", dateString(nst))
+}
+
+func TestNameObject(t *testing.T) {
+	testCompillation(t, "/Yes", nameObject("Yes"))
+	testCompillation(t, "/A#20B#23C", nameObject("A B#C"))
+	testCompillation(t, "/#C3#A9t#C3#A9", nameObject("été"))
+}
@@ -0,0 +1,205 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// Object identifiers of Time-Stamp Protocol (RFC 3161).
+var (
+	oidTSTInfo = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+// TimestampAuthority issues time-stamp tokens of RFC 3161.
+//
+// ex. HTTPTimestampAuthority
+type TimestampAuthority interface {
+	// Timestamp returns the DER encoded time-stamp token for the SHA-256 digest of the data.
+	// The token is a ContentInfo of SignedData whose content is a TSTInfo.
+	Timestamp(digest []byte) ([]byte, error)
+}
+
+// HTTPTimestampAuthority requests time-stamp tokens from a time-stamp authority over HTTP.
+type HTTPTimestampAuthority struct {
+	// URL is the address of the time-stamp authority.
+	URL string
+	// Client is the client used to send requests.
+	// If it is nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// messageImprint is the digest of the time-stamped data.
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+// timeStampReq is a time-stamp request.
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	Nonce          *big.Int
+	CertReq        bool
+}
+
+// timeStampResp is a time-stamp response.
+// TimeStampToken is absent unless the request is granted.
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// pkiStatusInfo is the status of a time-stamp response.
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional,utf8"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+// Timestamp sends the request of the digest, and returns the token in the response.
+// The authority is requested to include its certificate in the token.
+func (ta *HTTPTimestampAuthority) Timestamp(digest []byte) ([]byte, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	req, err := asn1.Marshal(timeStampReq{
+		Version:        1,
+		MessageImprint: newMessageImprint(digest),
+		Nonce:          nonce,
+		CertReq:        true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client := ta.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Post(ta.URL, "application/timestamp-query", bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("timestamp: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp: unexpected status: %s", res.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("timestamp: %s", err)
+	}
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("timestamp: invalid response: %s", err)
+	}
+	// 0: granted, 1: granted with modifications.
+	if resp.Status.Status > 1 || len(resp.TimeStampToken.FullBytes) == 0 {
+		return nil, fmt.Errorf("timestamp: request is rejected: status %d %v", resp.Status.Status, resp.Status.StatusString)
+	}
+	info, err := parseTimestampToken(resp.TimeStampToken.FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	if info.nonce == nil || info.nonce.Cmp(nonce) != 0 {
+		return nil, errors.New("timestamp: nonce of the response does not match the request")
+	}
+	return resp.TimeStampToken.FullBytes, nil
+}
+
+func newMessageImprint(digest []byte) messageImprint {
+	return messageImprint{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+		HashedMessage: digest,
+	}
+}
+
+// tstInfo is the information of a time-stamp token which is used to check the token.
+type tstInfo struct {
+	messageImprint messageImprint
+	genTime        time.Time
+	nonce          *big.Int
+}
+
+// parseTimestampToken returns the information of the DER encoded time-stamp token.
+func parseTimestampToken(token []byte) (*tstInfo, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, fmt.Errorf("timestamp: invalid token: %s", err)
+	}
+	var sd signedData
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("timestamp: token is not signed data")
+	}
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("timestamp: invalid token: %s", err)
+	}
+	if !sd.EncapContentInfo.ContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("timestamp: token does not contain TSTInfo")
+	}
+	var content []byte
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.Content.Bytes, &content); err != nil {
+		return nil, fmt.Errorf("timestamp: invalid TSTInfo: %s", err)
+	}
+	return parseTSTInfo(content)
+}
+
+// parseTSTInfo parses the TSTInfo.
+// It consists of version, policy, messageImprint, serialNumber and genTime,
+// followed by the optional accuracy, ordering, nonce, tsa and extensions.
+func parseTSTInfo(der []byte) (*tstInfo, error) {
+	var seq asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &seq); err != nil || seq.Tag != asn1.TagSequence {
+		return nil, errors.New("timestamp: invalid TSTInfo")
+	}
+	info := &tstInfo{}
+	rest := seq.Bytes
+	for i := 0; len(rest) > 0; i++ {
+		var elem asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &elem); err != nil {
+			return nil, fmt.Errorf("timestamp: invalid TSTInfo: %s", err)
+		}
+		switch {
+		case i == 2:
+			_, err = asn1.Unmarshal(elem.FullBytes, &info.messageImprint)
+		case i == 4:
+			_, err = asn1.Unmarshal(elem.FullBytes, &info.genTime)
+		case i > 4 && elem.Class == asn1.ClassUniversal && elem.Tag == asn1.TagInteger:
+			_, err = asn1.Unmarshal(elem.FullBytes, &info.nonce)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("timestamp: invalid TSTInfo: %s", err)
+		}
+	}
+	if info.genTime.IsZero() {
+		return nil, errors.New("timestamp: invalid TSTInfo")
+	}
+	return info, nil
+}
+
+// timestampData requests the time-stamp token of the data, and checks that the token is issued for the data.
+func timestampData(tsa TimestampAuthority, data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	token, err := tsa.Timestamp(digest[:])
+	if err != nil {
+		return nil, err
+	}
+	info, err := parseTimestampToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if !info.messageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) || !bytes.Equal(info.messageImprint.HashedMessage, digest[:]) {
+		return nil, errors.New("timestamp: token is not issued for the document")
+	}
+	return token, nil
+}
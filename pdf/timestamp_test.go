@@ -0,0 +1,243 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testTSTInfo is a TSTInfo issued by testTimestampAuthority.
+type testTSTInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+// testTimestampAuthority is an in-process time-stamp authority.
+type testTimestampAuthority struct {
+	t       *testing.T
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+	genTime time.Time
+	// digests are the digests which have been time-stamped.
+	digests [][]byte
+}
+
+func newTestTimestampAuthority(t *testing.T) *testTimestampAuthority {
+	cert, key := newTestCertificate(t, "tsa")
+	return &testTimestampAuthority{
+		t:       t,
+		cert:    cert,
+		key:     key,
+		genTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func (ta *testTimestampAuthority) Timestamp(digest []byte) ([]byte, error) {
+	ta.digests = append(ta.digests, digest)
+	return ta.token(digest, nil), nil
+}
+
+// token returns the time-stamp token of the digest, which is signed with the signed attributes.
+func (ta *testTimestampAuthority) token(digest []byte, nonce *big.Int) []byte {
+	info, err := asn1.Marshal(testTSTInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3},
+		MessageImprint: newMessageImprint(digest),
+		SerialNumber:   big.NewInt(1),
+		GenTime:        ta.genTime,
+		Nonce:          nonce,
+	})
+	if err != nil {
+		ta.t.Fatalf("token: unexpected error:%s", err)
+	}
+	content, _ := asn1.Marshal(info)
+	infoDigest := sha256.Sum256(info)
+	contentType, _ := newAttribute(oidContentType, oidTSTInfo)
+	messageDigest, _ := newAttribute(oidMessageDigest, infoDigest[:])
+	attrs := [][]byte{contentType, messageDigest}
+	signed, _ := asn1.Marshal(attributeSet(asn1.ClassUniversal, asn1.TagSet, attrs))
+	signedDigest := sha256.Sum256(signed)
+	signature, err := ta.key.Sign(rand.Reader, signedDigest[:], crypto.SHA256)
+	if err != nil {
+		ta.t.Fatalf("token: unexpected error:%s", err)
+	}
+	sigAlg, _ := signatureAlgorithm(ta.key)
+	sha256Alg := newMessageImprint(nil).HashAlgorithm
+	token, err := newContentInfo(oidSignedData, signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Alg},
+		EncapContentInfo: encapsulatedContentInfo{
+			ContentType: oidTSTInfo,
+			Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+		},
+		Certificates: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: ta.cert.Raw},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SignerIdentifier:   newIssuerAndSerialNumber(ta.cert),
+			DigestAlgorithm:    sha256Alg,
+			SignedAttributes:   attributeSet(asn1.ClassContextSpecific, 0, attrs),
+			SignatureAlgorithm: sigAlg,
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		ta.t.Fatalf("token: unexpected error:%s", err)
+	}
+	return token
+}
+
+// ServeHTTP responds to the time-stamp request with the token.
+func (ta *testTimestampAuthority) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var req timeStampReq
+	if _, err := asn1.Unmarshal(body, &req); err != nil || r.Header.Get("Content-Type") != "application/timestamp-query" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if !req.CertReq {
+		ta.t.Error("ServeHTTP: certificate is not requested")
+	}
+	token := ta.token(req.MessageImprint.HashedMessage, req.Nonce)
+	res, _ := asn1.Marshal(timeStampResp{
+		Status:         pkiStatusInfo{Status: 0},
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	})
+	w.Header().Set("Content-Type", "application/timestamp-reply")
+	w.Write(res)
+}
+
+func TestParseTimestampToken(t *testing.T) {
+	ta := newTestTimestampAuthority(t)
+	digest := sha256.Sum256([]byte("data"))
+	info, err := parseTimestampToken(ta.token(digest[:], big.NewInt(42)))
+	if err != nil {
+		t.Fatalf("parseTimestampToken: unexpected error:%s", err)
+	}
+	if !bytes.Equal(info.messageImprint.HashedMessage, digest[:]) {
+		t.Error("parseTimestampToken: unexpected message imprint")
+	}
+	if !info.genTime.Equal(ta.genTime) {
+		t.Errorf("parseTimestampToken: unexpected time:%s", info.genTime)
+	}
+	if info.nonce == nil || info.nonce.Int64() != 42 {
+		t.Errorf("parseTimestampToken: unexpected nonce:%v", info.nonce)
+	}
+	info, err = parseTimestampToken(ta.token(digest[:], nil))
+	if err != nil || info.nonce != nil {
+		t.Errorf("parseTimestampToken: nonce must be absent:%v %v", info, err)
+	}
+	if _, err := parseTimestampToken([]byte{0x30, 0x00}); err == nil {
+		t.Error("parseTimestampToken: invalid token must be error")
+	}
+}
+
+func TestTimestampData(t *testing.T) {
+	ta := newTestTimestampAuthority(t)
+	token, err := timestampData(ta, []byte("data"))
+	if err != nil {
+		t.Fatalf("timestampData: unexpected error:%s", err)
+	}
+	digest := sha256.Sum256([]byte("data"))
+	if len(ta.digests) != 1 || !bytes.Equal(ta.digests[0], digest[:]) {
+		t.Error("timestampData: digest of the data is not requested")
+	}
+	verifyTimestamp(t, token, []byte("data"))
+	other := timestampAuthorityFunc(func(digest []byte) ([]byte, error) {
+		d := sha256.Sum256([]byte("other"))
+		return ta.token(d[:], nil), nil
+	})
+	if _, err := timestampData(other, []byte("data")); err == nil {
+		t.Error("timestampData: token for the other data must be error")
+	}
+}
+
+// timestampAuthorityFunc is a function which implements TimestampAuthority.
+type timestampAuthorityFunc func(digest []byte) ([]byte, error)
+
+func (f timestampAuthorityFunc) Timestamp(digest []byte) ([]byte, error) {
+	return f(digest)
+}
+
+// verifyTimestamp verifies that the token is issued for the data and signed by its certificate.
+// The token may be followed by the padding of the contents.
+func verifyTimestamp(t *testing.T, token []byte, data []byte) {
+	t.Helper()
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		t.Fatalf("verifyTimestamp: unexpected error:%s", err)
+	}
+	var sd signedData
+	asn1.Unmarshal(ci.Content.Bytes, &sd)
+	cert, err := x509.ParseCertificate(sd.Certificates.Bytes)
+	if err != nil {
+		t.Fatalf("verifyTimestamp: unexpected error:%s", err)
+	}
+	si := sd.SignerInfos[0]
+	signed := append([]byte{0x31}, si.SignedAttributes.FullBytes[1:]...)
+	if err := cert.CheckSignature(x509.SHA256WithRSA, signed, si.Signature); err != nil {
+		t.Errorf("verifyTimestamp: invalid signature:%s", err)
+	}
+	info, err := parseTimestampToken(token)
+	if err != nil {
+		t.Fatalf("verifyTimestamp: unexpected error:%s", err)
+	}
+	digest := sha256.Sum256(data)
+	if !bytes.Equal(info.messageImprint.HashedMessage, digest[:]) {
+		t.Error("verifyTimestamp: token is not issued for the data")
+	}
+}
+
+func TestHTTPTimestampAuthority(t *testing.T) {
+	ta := newTestTimestampAuthority(t)
+	server := httptest.NewServer(ta)
+	defer server.Close()
+	client := &HTTPTimestampAuthority{URL: server.URL, Client: server.Client()}
+	token, err := timestampData(client, []byte("data"))
+	if err != nil {
+		t.Fatalf("Timestamp: unexpected error:%s", err)
+	}
+	verifyTimestamp(t, token, []byte("data"))
+	// The nonce of the response must match the request.
+	replay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, _ := asn1.Marshal(timeStampResp{
+			Status:         pkiStatusInfo{Status: 0},
+			TimeStampToken: asn1.RawValue{FullBytes: token},
+		})
+		w.Write(res)
+	}))
+	defer replay.Close()
+	client.URL = replay.URL
+	if _, err := client.Timestamp(make([]byte, 32)); err == nil {
+		t.Error("Timestamp: response without the nonce must be error")
+	}
+	rejection := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, _ := asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 2, StatusString: []string{"rejected"}}})
+		w.Write(res)
+	}))
+	defer rejection.Close()
+	client.URL = rejection.URL
+	if _, err := client.Timestamp(make([]byte, 32)); err == nil {
+		t.Error("Timestamp: rejection must be error")
+	}
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	client.URL = notFound.URL
+	if _, err := client.Timestamp(make([]byte, 32)); err == nil {
+		t.Error("Timestamp: HTTP error must be error")
+	}
+}
@@ -1,21 +1,63 @@
 package pdf
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // trailer is a trailer dictionary.
 type trailer struct {
 	root      objectIdentifier
 	size      int
 	startXRef int
+	info      pdfObject
+	encrypt   pdfObject
+	id        [][]byte
+	// prev is the offset of the previous cross-reference section of an incremental update, or 0.
+	prev int
 }
 
 // newTrailer returns a trailer.
 func newTrailer(root objectIdentifier, size int, startXRef int) *trailer {
-	return &trailer{root, size, startXRef}
+	return &trailer{
+		root:      root,
+		size:      size,
+		startXRef: startXRef,
+	}
+}
+
+// entries returns the keys and the values of the trailer dictionary.
+func (t *trailer) entries() [][2]string {
+	entries := make([][2]string, 2, 6)
+	entries[0] = [2]string{"/Root", t.root.indirectReference()}
+	entries[1] = [2]string{"/Size", strconv.Itoa(t.size)}
+	if t.info != nil {
+		entries = append(entries, [2]string{"/Info", t.info.indirectReference()})
+	}
+	if t.encrypt != nil {
+		entries = append(entries, [2]string{"/Encrypt", t.encrypt.indirectReference()})
+	}
+	if t.prev > 0 {
+		entries = append(entries, [2]string{"/Prev", strconv.Itoa(t.prev)})
+	}
+	if len(t.id) == 2 {
+		entries = append(entries, [2]string{"/ID", fmt.Sprintf("[%s %s]", hexString(t.id[0]), hexString(t.id[1]))})
+	}
+	return entries
+}
+
+// dict returns the entries of the trailer dictionary.
+func (t *trailer) dict() string {
+	dict := make([]string, 0, 6)
+	for _, e := range t.entries() {
+		dict = append(dict, fmt.Sprintf("%s %s", e[0], e[1]))
+	}
+	return strings.Join(dict, " ")
 }
 
 func (t *trailer) compile() string {
 	return fmt.Sprintf(
-		"trailer\n<</Root %s /Size %d>>\nstartxref\n%d\n%%%%EOF",
-		t.root.indirectReference(), t.size, t.startXRef)
+		"trailer\n<<%s>>\nstartxref\n%d\n%%%%EOF",
+		t.dict(), t.startXRef)
 }
@@ -16,3 +16,27 @@ func TestTrailer(t *testing.T) {
 	expected := "trailer\n<</Root 1 2 R /Size 3>>\nstartxref\n4\n%%EOF"
 	testCompillation(t, expected, actual)
 }
+
+func TestTrailerInfo(t *testing.T) {
+	r := objectIdentifier{1, 0}
+	tr := newTrailer(r, 3, 4)
+	tr.info = &objectIdentifier{5, 0}
+	expected := "trailer\n<</Root 1 0 R /Size 3 /Info 5 0 R>>\nstartxref\n4\n%%EOF"
+	testCompillation(t, expected, tr.compile())
+}
+
+func TestTrailerID(t *testing.T) {
+	r := objectIdentifier{1, 0}
+	tr := newTrailer(r, 3, 4)
+	tr.id = [][]byte{{0x01, 0xAB}, {0xCD, 0x02}}
+	expected := "trailer\n<</Root 1 0 R /Size 3 /ID [<01AB> <CD02>]>>\nstartxref\n4\n%%EOF"
+	testCompillation(t, expected, tr.compile())
+}
+
+func TestTrailerPrev(t *testing.T) {
+	r := objectIdentifier{1, 0}
+	tr := newTrailer(r, 3, 40)
+	tr.prev = 4
+	expected := "trailer\n<</Root 1 0 R /Size 3 /Prev 4>>\nstartxref\n40\n%%EOF"
+	testCompillation(t, expected, tr.compile())
+}
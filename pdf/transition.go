@@ -0,0 +1,199 @@
+package pdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TransitionStyle is the visual effect used when moving to a page during a presentation.
+type TransitionStyle int
+
+const (
+	transitionStyleUndefined TransitionStyle = iota
+	// TransitionStyleSplit : Two lines sweep across the screen, revealing the new page.
+	TransitionStyleSplit
+	// TransitionStyleBlinds : Multiple lines sweep across the screen, revealing the new page.
+	TransitionStyleBlinds
+	// TransitionStyleBox : A rectangular box sweeps inward from the edges of the page or outward from the center.
+	TransitionStyleBox
+	// TransitionStyleWipe : A single line sweeps across the screen from one edge to the other.
+	TransitionStyleWipe
+	// TransitionStyleDissolve : The old page dissolves gradually to reveal the new one.
+	TransitionStyleDissolve
+	// TransitionStyleGlitter : Similar to Dissolve, except that the effect sweeps across the page in a wide band.
+	TransitionStyleGlitter
+	// TransitionStyleReplace : The new page simply replaces the old one with no special effect.
+	TransitionStyleReplace
+	// TransitionStyleFly : Changes are flown out or in to or from a location that is offscreen.
+	TransitionStyleFly
+	// TransitionStylePush : The old page slides off the screen while the new page slides in, pushing the old page out.
+	TransitionStylePush
+	// TransitionStyleCover : The new page slides on to the screen, covering the old page.
+	TransitionStyleCover
+	// TransitionStyleUncover : The old page slides off the screen, uncovering the new page.
+	TransitionStyleUncover
+	// TransitionStyleFade : The new page gradually becomes visible through the old one.
+	TransitionStyleFade
+)
+
+func (ts TransitionStyle) compile() string {
+	switch ts {
+	case TransitionStyleSplit:
+		return "/Split"
+	case TransitionStyleBlinds:
+		return "/Blinds"
+	case TransitionStyleBox:
+		return "/Box"
+	case TransitionStyleWipe:
+		return "/Wipe"
+	case TransitionStyleDissolve:
+		return "/Dissolve"
+	case TransitionStyleGlitter:
+		return "/Glitter"
+	case TransitionStyleReplace:
+		return "/R"
+	case TransitionStyleFly:
+		return "/Fly"
+	case TransitionStylePush:
+		return "/Push"
+	case TransitionStyleCover:
+		return "/Cover"
+	case TransitionStyleUncover:
+		return "/Uncover"
+	case TransitionStyleFade:
+		return "/Fade"
+	default:
+		return ""
+	}
+}
+
+// TransitionDimension is the dimension in which the Split and Blinds effects occur.
+type TransitionDimension int
+
+const (
+	transitionDimensionUndefined TransitionDimension = iota
+	// TransitionDimensionHorizontal : The effect occurs horizontally.
+	TransitionDimensionHorizontal
+	// TransitionDimensionVertical : The effect occurs vertically.
+	TransitionDimensionVertical
+)
+
+func (td TransitionDimension) compile() string {
+	switch td {
+	case TransitionDimensionHorizontal:
+		return "/H"
+	case TransitionDimensionVertical:
+		return "/V"
+	default:
+		return ""
+	}
+}
+
+// TransitionMotion is the direction of motion of the Split, Box and Fly effects.
+type TransitionMotion int
+
+const (
+	transitionMotionUndefined TransitionMotion = iota
+	// TransitionMotionInward : The effect moves from the edges of the page toward the center.
+	TransitionMotionInward
+	// TransitionMotionOutward : The effect moves from the center of the page toward the edges.
+	TransitionMotionOutward
+)
+
+func (tm TransitionMotion) compile() string {
+	switch tm {
+	case TransitionMotionInward:
+		return "/I"
+	case TransitionMotionOutward:
+		return "/O"
+	default:
+		return ""
+	}
+}
+
+// TransitionDirection is the direction in which the Wipe, Glitter, Fly, Cover, Uncover and Push effects move.
+type TransitionDirection int
+
+const (
+	transitionDirectionUndefined TransitionDirection = iota
+	// TransitionDirectionLeftToRight : The effect moves from left to right.
+	TransitionDirectionLeftToRight
+	// TransitionDirectionBottomToTop : The effect moves from bottom to top. Wipe only.
+	TransitionDirectionBottomToTop
+	// TransitionDirectionRightToLeft : The effect moves from right to left. Wipe only.
+	TransitionDirectionRightToLeft
+	// TransitionDirectionTopToBottom : The effect moves from top to bottom.
+	TransitionDirectionTopToBottom
+	// TransitionDirectionTopLeftToBottomRight : The effect moves from top-left to bottom-right. Glitter only.
+	TransitionDirectionTopLeftToBottomRight
+	// TransitionDirectionNone : The effect has no direction. Fly only, which is meaningful only when Scale is not 1.
+	TransitionDirectionNone
+)
+
+func (td TransitionDirection) compile() string {
+	switch td {
+	case TransitionDirectionLeftToRight:
+		return "0"
+	case TransitionDirectionBottomToTop:
+		return "90"
+	case TransitionDirectionRightToLeft:
+		return "180"
+	case TransitionDirectionTopToBottom:
+		return "270"
+	case TransitionDirectionTopLeftToBottomRight:
+		return "315"
+	case TransitionDirectionNone:
+		return "/None"
+	default:
+		return ""
+	}
+}
+
+// Transition is the transition effect used when moving to the page during a presentation.
+// Zero values are not written, so that viewers use their defaults.
+type Transition struct {
+	// Style is the transition style.
+	Style TransitionStyle
+	// Duration is the duration of the transition effect in seconds.
+	Duration float64
+	// Dimension is the dimension in which the Split and Blinds effects occur.
+	Dimension TransitionDimension
+	// Motion is the direction of motion of the Split, Box and Fly effects.
+	Motion TransitionMotion
+	// Direction is the direction in which the Wipe, Glitter, Fly, Cover, Uncover and Push effects move.
+	Direction TransitionDirection
+	// Scale is the starting or ending scale at which the Fly effect changes, which is 1 by default.
+	Scale float64
+	// Opaque specifies whether the area to be flown in by the Fly effect is rectangular and opaque.
+	Opaque bool
+}
+
+func (t *Transition) compile() string {
+	dict := make([]string, 0, 8)
+	dict = append(dict, "/Type /Trans")
+	entries := []struct {
+		key   string
+		value string
+	}{
+		{"/S", t.Style.compile()},
+		{"/Dm", t.Dimension.compile()},
+		{"/M", t.Motion.compile()},
+		{"/Di", t.Direction.compile()},
+	}
+	for _, e := range entries {
+		if e.value != "" {
+			dict = append(dict, fmt.Sprintf("%s %s", e.key, e.value))
+		}
+	}
+	if t.Duration > 0 {
+		dict = append(dict, fmt.Sprintf("/D %s", strconv.FormatFloat(t.Duration, 'f', -1, 64)))
+	}
+	if t.Scale > 0 {
+		dict = append(dict, fmt.Sprintf("/SS %s", strconv.FormatFloat(t.Scale, 'f', -1, 64)))
+	}
+	if t.Opaque {
+		dict = append(dict, "/B true")
+	}
+	return fmt.Sprintf("<<%s>>", strings.Join(dict, " "))
+}
@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	cases := []struct {
+		transition Transition
+		expected   string
+	}{
+		{Transition{}, "<</Type /Trans>>"},
+		{Transition{Style: TransitionStyleReplace}, "<</Type /Trans /S /R>>"},
+		{Transition{Style: TransitionStyleSplit, Dimension: TransitionDimensionVertical, Motion: TransitionMotionOutward, Duration: 1.5},
+			"<</Type /Trans /S /Split /Dm /V /M /O /D 1.5>>"},
+		{Transition{Style: TransitionStyleWipe, Direction: TransitionDirectionLeftToRight}, "<</Type /Trans /S /Wipe /Di 0>>"},
+		{Transition{Style: TransitionStyleGlitter, Direction: TransitionDirectionTopLeftToBottomRight}, "<</Type /Trans /S /Glitter /Di 315>>"},
+		{Transition{Style: TransitionStyleFly, Direction: TransitionDirectionNone}, "<</Type /Trans /S /Fly /Di /None>>"},
+		{Transition{Style: TransitionStyleFly, Direction: TransitionDirectionNone, Scale: 0.25, Opaque: true}, "<</Type /Trans /S /Fly /Di /None /SS 0.25 /B true>>"},
+		{Transition{Style: TransitionStyleFade, Duration: 2}, "<</Type /Trans /S /Fade /D 2>>"},
+	}
+	for _, c := range cases {
+		testCompillation(t, c.expected, c.transition.compile())
+	}
+}
+
+func TestPageTransition(t *testing.T) {
+	p := newTestPage()
+	p.SetTransition(Transition{Style: TransitionStyleDissolve})
+	p.SetDuration(3.5)
+	expected := "/Trans <</Type /Trans /S /Dissolve>> /Dur 3.5"
+	if actual := p.compile(); !strings.Contains(actual, expected) {
+		t.Errorf("compile: expected:%s, actual:%s", expected, actual)
+	}
+}
+
+func TestBuilderPresentation(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetPageMode(PageModeFullScreen)
+	b.SetViewerPreferences(ViewerPreferences{NonFullScreenPageMode: PageModeUseThumbs})
+	for _, style := range []TransitionStyle{TransitionStyleBlinds, TransitionStyleBox} {
+		p := b.AddPage()
+		p.SetTransition(Transition{Style: style, Duration: 0.5})
+		p.SetDuration(5)
+	}
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	out := buf.String()
+	for _, entry := range []string{
+		"/PageMode /FullScreen",
+		"/ViewerPreferences <</NonFullScreenPageMode /UseThumbs>>",
+		"/Trans <</Type /Trans /S /Blinds /D 0.5>> /Dur 5",
+		"/Trans <</Type /Trans /S /Box /D 0.5>> /Dur 5",
+	} {
+		if !strings.Contains(out, entry) {
+			t.Errorf("Build: document does not contain:%s", entry)
+		}
+	}
+}
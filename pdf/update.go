@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Update writes the document written by the builder, followed by an incremental update
+// which contains the objects added or changed after the document was written by Build or Update.
+// The previous revisions are kept intact, so that their signatures remain valid.
+// The update is signed with WithSignature, and time-stamped with WithDocumentTimestamp.
+// It is encrypted in the same way as the document, and linearization is not supported.
+func (b *Builder) Update(w io.Writer, pdf []byte, options ...BuildOption) error {
+	if b.stream != nil {
+		return errors.New("update: streamed documents are not supported")
+	}
+	if b.trailer == nil || b.revision == nil {
+		return errors.New("update: document has not been written")
+	}
+	o := &buildOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	if o.encryption != nil {
+		return errors.New("update: encryption cannot be changed")
+	}
+	if o.linearized {
+		return errors.New("update: linearization is not supported")
+	}
+	if o.timestamp != nil && o.timestamp.tsa == nil {
+		return errors.New("timestamp: time-stamp authority is not specified")
+	}
+	if !bytes.HasSuffix(bytes.TrimRight(pdf, "\r\n"), []byte(fmt.Sprintf("startxref\n%d\n%%%%EOF", b.trailer.startXRef))) {
+		return errors.New("update: document is not the last revision written by the builder")
+	}
+	if o.signature != nil {
+		if err := o.signature.prepare(b.now()); err != nil {
+			return err
+		}
+	}
+	if err := b.build(); err != nil {
+		return err
+	}
+	// The document is copied, since the signature is embedded in the buffer.
+	buf := bytes.NewBuffer(append(make([]byte, 0, len(pdf)), pdf...))
+	if err := b.appendUpdate(buf); err != nil {
+		return err
+	}
+	out := buf.Bytes()
+	var err error
+	if o.signature != nil {
+		if out, err = o.signature.sign(out, b.random); err != nil {
+			return err
+		}
+		b.signed = true
+	}
+	if o.timestamp != nil {
+		if out, err = o.timestamp.update(b, out); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// appendUpdate appends the incremental update to the written document in buf.
+// The update contains the changed objects and the new objects,
+// and its cross-reference section refers to the previous section.
+func (b *Builder) appendUpdate(buf *bytes.Buffer) error {
+	objs := make([]pdfObject, 0)
+	walker := func(obj pdfObject) {
+		if b.changed(obj) {
+			objs = append(objs, obj)
+		}
+	}
+	b.dc.walk(walker)
+	b.info.walk(walker)
+	buf.WriteString("\n")
+	pw := b.newWriter(buf).resume(buf.Len())
+	for _, obj := range objs {
+		pw.writeObj(obj)
+	}
+	t := *b.trailer
+	if b.info != nil {
+		t.info = b.info
+	}
+	t.prev = b.trailer.startXRef
+	// The second identifier changes with each revision, while the first one is permanent.
+	t.id = [][]byte{t.id[0], b.fileID(pw.digest.Sum(t.id[1]))}
+	if err := pw.finishWith(&t); err != nil {
+		return err
+	}
+	b.trailer = &t
+	for n, d := range pw.digests {
+		b.revision[n] = d
+	}
+	return nil
+}
+
+// changed returns whether the object has been added or changed since the document was written.
+// The unencrypted expression of the object is compared with the digest of the written one.
+func (b *Builder) changed(obj pdfObject) bool {
+	d, ok := b.revision[obj.refNo()]
+	if !ok {
+		return true
+	}
+	data, err := newWriter(ioutil.Discard).compile(obj)
+	return err != nil || md5.Sum(data) != d
+}
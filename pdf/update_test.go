@@ -0,0 +1,202 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// updatedObjects returns the numbers of the objects written in the last update.
+func updatedObjects(updated []byte, original []byte) []string {
+	numbers := make([]string, 0)
+	for _, m := range regexp.MustCompile(`\n(\d+) 0 obj\n`).FindAllSubmatch(updated[len(original):], -1) {
+		numbers = append(numbers, string(m[1]))
+	}
+	return numbers
+}
+
+func TestBuilderUpdate(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.SetReproducible(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	b.SetInfo(DocumentInfo{Title: "Application"})
+	font := b.NewFontType1("/Helvetica")
+	b.AddFont(font)
+	p := b.AddPage()
+	p.WriteText(10, 10, font, 10, "name")
+	field := p.TextField("name", 50, 10, 100, 20)
+	p.Note(10, 40, "note")
+	// The non-terminal fields, the radio button groups and the named destinations are rebuilt by each update.
+	p.TextField("applicant.name", 50, 40, 100, 20)
+	p.RadioButton("plan", "basic", 50, 70, 10, 10)
+	p.RadioButton("plan", "premium", 70, 70, 10, 10)
+	p.AddDestination("top", 0, 800)
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	original := buf.Bytes()
+	// Nothing is written unless the document is changed.
+	var unchanged bytes.Buffer
+	if err := b.Update(&unchanged, original); err != nil {
+		t.Fatalf("Update: unexpected error:%s", err)
+	}
+	if n := updatedObjects(unchanged.Bytes(), original); len(n) != 0 {
+		t.Errorf("Update: unchanged objects are written:%v", n)
+	}
+	id := regexp.MustCompile(`/ID \[(<\w+>) (<\w+>)\]`)
+	before, after := id.FindAllSubmatch(original, -1), id.FindAllSubmatch(unchanged.Bytes(), -1)
+	if !bytes.Equal(before[0][1], after[1][1]) || bytes.Equal(before[0][2], after[1][2]) {
+		t.Errorf("Update: unexpected file identifier:%s", after[1][0])
+	}
+	field.Value("filled")
+	stamp := p.FreeText(10, 70, 100, 20, font, 10, "APPROVED")
+	added := b.AddPage()
+	var out bytes.Buffer
+	if err := b.Update(&out, unchanged.Bytes()); err != nil {
+		t.Fatalf("Update: unexpected error:%s", err)
+	}
+	updated := out.Bytes()
+	if !bytes.HasPrefix(updated, unchanged.Bytes()) {
+		t.Fatal("Update: previous revisions are changed")
+	}
+	trailer := checkLastXRef(t, updated)
+	if !strings.Contains(trailer, fmt.Sprintf("/Prev %d", b.trailer.prev)) || b.trailer.prev != bytes.LastIndex(unchanged.Bytes(), []byte("\nxref\n"))+1 {
+		t.Errorf("Update: trailer does not refer the previous section:%s", trailer)
+	}
+	checkReferences(t, updated)
+	written := strings.Join(updatedObjects(updated, unchanged.Bytes()), " ")
+	for _, obj := range []pdfObject{field.(*textField), field.(*textField).appearance, p, stamp.(*freeText), added} {
+		if !regexp.MustCompile(fmt.Sprintf(`\b%d\b`, obj.refNo())).MatchString(written) {
+			t.Errorf("Update: object %d is not written:%s", obj.refNo(), written)
+		}
+	}
+	for _, obj := range []pdfObject{font, b.dc.metadata, b.info, p.(*page).contents} {
+		if regexp.MustCompile(fmt.Sprintf(`\b%d\b`, obj.refNo())).MatchString(written) {
+			t.Errorf("Update: unchanged object %d is written:%s", obj.refNo(), written)
+		}
+	}
+	if !bytes.Contains(updated[len(unchanged.Bytes()):], []byte("/V (filled)")) {
+		t.Error("Update: field value is not updated")
+	}
+}
+
+func TestBuilderUpdateSignature(t *testing.T) {
+	cert, key := newTestCertificate(t, "signer")
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	p := b.AddPage()
+	author := p.SignatureField("author", 0, 0, 0, 0)
+	var buf bytes.Buffer
+	if err := b.Build(&buf, WithSignature(author, key, []*x509.Certificate{cert})); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	original := buf.Bytes()
+	approval := p.SignatureField("approval", 0, 0, 0, 0)
+	var out bytes.Buffer
+	if err := b.Update(&out, original, WithSignature(approval, key, []*x509.Certificate{cert})); err != nil {
+		t.Fatalf("Update: unexpected error:%s", err)
+	}
+	updated := out.Bytes()
+	// The signature of the first revision remains valid.
+	if !bytes.HasPrefix(updated, original) {
+		t.Fatal("Update: signed revision is changed")
+	}
+	data, der := signedBytes(t, original)
+	verifyDetached(t, der, data)
+	data, der = signedBytes(t, updated)
+	verifyDetached(t, der, data)
+	checkLastXRef(t, updated)
+	if n := bytes.Count(updated, []byte("/ByteRange [0 ")); n != 2 {
+		t.Errorf("Update: unexpected number of signatures:%d", n)
+	}
+}
+
+func TestBuilderUpdateOutputs(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		prepare func(b *Builder) []BuildOption
+	}{
+		{"encryption", func(b *Builder) []BuildOption {
+			return []BuildOption{WithEncryption(&PasswordEncryption{Algorithm: EncryptionAES128})}
+		}},
+		{"object streams", func(b *Builder) []BuildOption {
+			b.SetObjectStreams(true)
+			return nil
+		}},
+		{"linearization", func(b *Builder) []BuildOption {
+			return []BuildOption{WithLinearization()}
+		}},
+	} {
+		b := NewBuilder(NewBoxA4(), NewBoxA4())
+		b.SetInfo(DocumentInfo{Title: "Report"})
+		p := b.AddPage()
+		p.Rectangle(0, 0, 10, 10).Render()
+		b.AddPage()
+		var buf bytes.Buffer
+		if err := b.Build(&buf, c.prepare(b)...); err != nil {
+			t.Fatalf("Build: unexpected error with %s:%s", c.name, err)
+		}
+		original := buf.Bytes()
+		startXRef := b.trailer.startXRef
+		note := p.Note(10, 10, "reviewed")
+		var out bytes.Buffer
+		if err := b.Update(&out, original); err != nil {
+			t.Fatalf("Update: unexpected error with %s:%s", c.name, err)
+		}
+		updated := out.Bytes()
+		var trailer string
+		if b.objectStreams {
+			trailer = checkXRefStream(t, updated)
+		} else {
+			trailer = checkLastXRef(t, updated)
+		}
+		if !strings.Contains(trailer, fmt.Sprintf("/Prev %d", startXRef)) {
+			t.Errorf("Update: trailer does not refer the previous section with %s:%s", c.name, trailer)
+		}
+		if (c.name == "encryption") != strings.Contains(trailer, "/Encrypt ") {
+			t.Errorf("Update: unexpected trailer with %s:%s", c.name, trailer)
+		}
+		if !bytes.HasPrefix(updated, original) || b.trailer.size <= note.(pdfObject).refNo() {
+			t.Errorf("Update: unexpected update with %s", c.name)
+		}
+		if bytes.Contains(updated, []byte("(reviewed)")) != (c.name == "linearization") {
+			t.Errorf("Update: note is not written as the document with %s", c.name)
+		}
+	}
+}
+
+func TestBuilderUpdateError(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	b.AddPage()
+	var out bytes.Buffer
+	if err := b.Update(&out, nil); err == nil || err.Error() != "update: document has not been written" {
+		t.Errorf("Update: unexpected error:%v", err)
+	}
+	var buf bytes.Buffer
+	if err := b.Build(&buf); err != nil {
+		t.Fatalf("Build: unexpected error:%s", err)
+	}
+	for _, c := range []struct {
+		pdf      []byte
+		options  []BuildOption
+		expected string
+	}{
+		{buf.Bytes(), []BuildOption{WithEncryption(&PasswordEncryption{})}, "update: encryption cannot be changed"},
+		{buf.Bytes(), []BuildOption{WithLinearization()}, "update: linearization is not supported"},
+		{buf.Bytes()[:buf.Len()-1], nil, "update: document is not the last revision written by the builder"},
+	} {
+		if err := b.Update(&out, c.pdf, c.options...); err == nil || err.Error() != c.expected {
+			t.Errorf("Update: unexpected error:%v", err)
+		}
+	}
+	b = NewBuilder(NewBoxA4(), NewBoxA4())
+	if err := b.StartStreaming(&buf); err != nil {
+		t.Fatalf("StartStreaming: unexpected error:%s", err)
+	}
+	if err := b.Update(&out, buf.Bytes()); err == nil || err.Error() != "update: streamed documents are not supported" {
+		t.Errorf("Update: unexpected error:%v", err)
+	}
+}
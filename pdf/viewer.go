@@ -0,0 +1,201 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PageMode specifies how the document is displayed when opened.
+type PageMode int
+
+const (
+	// pageModeUndefined is the default PageMode.
+	pageModeUndefined PageMode = iota
+	// PageModeUseNone : Neither document outline nor thumbnail images visible.
+	PageModeUseNone
+	// PageModeUseOutlines : Document outline visible.
+	PageModeUseOutlines
+	// PageModeUseThumbs : Thumbnail images visible.
+	PageModeUseThumbs
+	// PageModeFullScreen : Full-screen mode, with no menu bar, window controls, or any other window visible.
+	PageModeFullScreen
+	// PageModeUseOC : Optional content group panel visible.
+	PageModeUseOC
+	// PageModeUseAttachments : Attachments panel visible.
+	PageModeUseAttachments
+)
+
+func (pm PageMode) compile() string {
+	switch pm {
+	case PageModeUseNone:
+		return "/UseNone"
+	case PageModeUseOutlines:
+		return "/UseOutlines"
+	case PageModeUseThumbs:
+		return "/UseThumbs"
+	case PageModeFullScreen:
+		return "/FullScreen"
+	case PageModeUseOC:
+		return "/UseOC"
+	case PageModeUseAttachments:
+		return "/UseAttachments"
+	default:
+		return ""
+	}
+}
+
+// PageLayout specifies the page layout when the document is opened.
+type PageLayout int
+
+const (
+	// pageLayoutUndefined is the default PageLayout.
+	pageLayoutUndefined PageLayout = iota
+	// PageLayoutSinglePage : Display one page at a time.
+	PageLayoutSinglePage
+	// PageLayoutOneColumn : Display the pages in one column.
+	PageLayoutOneColumn
+	// PageLayoutTwoColumnLeft : Display the pages in two columns, with odd-numbered pages on the left.
+	PageLayoutTwoColumnLeft
+	// PageLayoutTwoColumnRight : Display the pages in two columns, with odd-numbered pages on the right.
+	PageLayoutTwoColumnRight
+	// PageLayoutTwoPageLeft : Display the pages two at a time, with odd-numbered pages on the left.
+	PageLayoutTwoPageLeft
+	// PageLayoutTwoPageRight : Display the pages two at a time, with odd-numbered pages on the right.
+	PageLayoutTwoPageRight
+)
+
+func (pl PageLayout) compile() string {
+	switch pl {
+	case PageLayoutSinglePage:
+		return "/SinglePage"
+	case PageLayoutOneColumn:
+		return "/OneColumn"
+	case PageLayoutTwoColumnLeft:
+		return "/TwoColumnLeft"
+	case PageLayoutTwoColumnRight:
+		return "/TwoColumnRight"
+	case PageLayoutTwoPageLeft:
+		return "/TwoPageLeft"
+	case PageLayoutTwoPageRight:
+		return "/TwoPageRight"
+	default:
+		return ""
+	}
+}
+
+// PrintScaling is the page scaling option for the print dialog.
+type PrintScaling int
+
+const (
+	// printScalingUndefined is the default PrintScaling.
+	printScalingUndefined PrintScaling = iota
+	// PrintScalingNone : No page scaling.
+	PrintScalingNone
+	// PrintScalingAppDefault : The conforming reader’s default print scaling.
+	PrintScalingAppDefault
+)
+
+func (ps PrintScaling) compile() string {
+	switch ps {
+	case PrintScalingNone:
+		return "/None"
+	case PrintScalingAppDefault:
+		return "/AppDefault"
+	default:
+		return ""
+	}
+}
+
+// Duplex is the paper handling option for the print dialog.
+type Duplex int
+
+const (
+	// duplexUndefined is the default Duplex.
+	duplexUndefined Duplex = iota
+	// DuplexSimplex : Print single-sided.
+	DuplexSimplex
+	// DuplexFlipShortEdge : Duplex and flip on the short edge of the sheet.
+	DuplexFlipShortEdge
+	// DuplexFlipLongEdge : Duplex and flip on the long edge of the sheet.
+	DuplexFlipLongEdge
+)
+
+func (d Duplex) compile() string {
+	switch d {
+	case DuplexSimplex:
+		return "/Simplex"
+	case DuplexFlipShortEdge:
+		return "/DuplexFlipShortEdge"
+	case DuplexFlipLongEdge:
+		return "/DuplexFlipLongEdge"
+	default:
+		return ""
+	}
+}
+
+// ViewerPreferences controls the way the document is to be presented on the screen or in print.
+// False and undefined values are not written, so that viewers use their defaults.
+type ViewerPreferences struct {
+	// HideToolbar specifies whether to hide the viewer’s tool bars.
+	HideToolbar bool
+	// HideMenubar specifies whether to hide the viewer’s menu bar.
+	HideMenubar bool
+	// HideWindowUI specifies whether to hide user interface elements in the document’s window.
+	HideWindowUI bool
+	// FitWindow specifies whether to resize the document’s window to fit the size of the first displayed page.
+	FitWindow bool
+	// CenterWindow specifies whether to position the document’s window in the center of the screen.
+	CenterWindow bool
+	// DisplayDocTitle specifies whether the window’s title bar should display the document title.
+	DisplayDocTitle bool
+	// PrintScaling is the page scaling option that shall be selected when a print dialog is displayed.
+	PrintScaling PrintScaling
+	// Duplex is the paper handling option that shall be used when printing the file from the print dialog.
+	Duplex Duplex
+	// NonFullScreenPageMode specifies how to display the document on exiting full-screen mode.
+	// It is meaningful only if the page mode is PageModeFullScreen,
+	// and must be one of PageModeUseNone, PageModeUseOutlines, PageModeUseThumbs and PageModeUseOC.
+	// The other page modes are omitted.
+	NonFullScreenPageMode PageMode
+}
+
+func (vp *ViewerPreferences) compile() string {
+	dict := make([]string, 0, 9)
+	flags := []struct {
+		key   string
+		value bool
+	}{
+		{"/HideToolbar", vp.HideToolbar},
+		{"/HideMenubar", vp.HideMenubar},
+		{"/HideWindowUI", vp.HideWindowUI},
+		{"/FitWindow", vp.FitWindow},
+		{"/CenterWindow", vp.CenterWindow},
+		{"/DisplayDocTitle", vp.DisplayDocTitle},
+	}
+	for _, f := range flags {
+		if f.value {
+			dict = append(dict, fmt.Sprintf("%s true", f.key))
+		}
+	}
+	if ps := vp.PrintScaling.compile(); ps != "" {
+		dict = append(dict, fmt.Sprintf("/PrintScaling %s", ps))
+	}
+	if d := vp.Duplex.compile(); d != "" {
+		dict = append(dict, fmt.Sprintf("/Duplex %s", d))
+	}
+	switch vp.NonFullScreenPageMode {
+	case PageModeUseNone, PageModeUseOutlines, PageModeUseThumbs, PageModeUseOC:
+		dict = append(dict, fmt.Sprintf("/NonFullScreenPageMode %s", vp.NonFullScreenPageMode.compile()))
+	}
+	return fmt.Sprintf("<<%s>>", strings.Join(dict, " "))
+}
+
+// openAction is a destination that shall be displayed when the document is opened.
+type openAction struct {
+	page     Page
+	destType OutlineDestination
+}
+
+func (oa *openAction) compile() string {
+	return explicitDestination(oa.page, oa.destType)
+}
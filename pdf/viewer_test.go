@@ -0,0 +1,73 @@
+package pdf
+
+import "testing"
+
+func TestPageMode(t *testing.T) {
+	modes := map[PageMode]string{
+		pageModeUndefined:      "",
+		PageModeUseNone:        "/UseNone",
+		PageModeUseOutlines:    "/UseOutlines",
+		PageModeUseThumbs:      "/UseThumbs",
+		PageModeFullScreen:     "/FullScreen",
+		PageModeUseOC:          "/UseOC",
+		PageModeUseAttachments: "/UseAttachments",
+	}
+	for pm, expected := range modes {
+		testCompillation(t, expected, pm.compile())
+	}
+}
+
+func TestPageLayout(t *testing.T) {
+	layouts := map[PageLayout]string{
+		pageLayoutUndefined:      "",
+		PageLayoutSinglePage:     "/SinglePage",
+		PageLayoutOneColumn:      "/OneColumn",
+		PageLayoutTwoColumnLeft:  "/TwoColumnLeft",
+		PageLayoutTwoColumnRight: "/TwoColumnRight",
+		PageLayoutTwoPageLeft:    "/TwoPageLeft",
+		PageLayoutTwoPageRight:   "/TwoPageRight",
+	}
+	for pl, expected := range layouts {
+		testCompillation(t, expected, pl.compile())
+	}
+}
+
+func TestViewerPreferences(t *testing.T) {
+	vp := &ViewerPreferences{}
+	testCompillation(t, "<<>>", vp.compile())
+	vp = &ViewerPreferences{
+		HideToolbar:     true,
+		FitWindow:       true,
+		DisplayDocTitle: true,
+		PrintScaling:    PrintScalingNone,
+		Duplex:          DuplexFlipLongEdge,
+	}
+	expected := "<</HideToolbar true /FitWindow true /DisplayDocTitle true /PrintScaling /None /Duplex /DuplexFlipLongEdge>>"
+	testCompillation(t, expected, vp.compile())
+	vp = &ViewerPreferences{NonFullScreenPageMode: PageModeUseOutlines}
+	expected = "<</NonFullScreenPageMode /UseOutlines>>"
+	testCompillation(t, expected, vp.compile())
+	for _, pm := range []PageMode{PageModeFullScreen, PageModeUseAttachments} {
+		vp = &ViewerPreferences{NonFullScreenPageMode: pm}
+		testCompillation(t, "<<>>", vp.compile())
+	}
+}
+
+func TestOpenAction(t *testing.T) {
+	p := &mockPage{}
+	p.objectNumber = 3
+	oa := &openAction{p, OutlineDestinationVertical(100)}
+	testCompillation(t, "[3 0 R /FitH 100]", oa.compile())
+}
+
+func TestBuilderViewer(t *testing.T) {
+	b := NewBuilder(NewBoxA4(), NewBoxA4())
+	p := b.AddPage()
+	p.(*page).objectNumber = 3
+	b.SetPageMode(PageModeUseOutlines)
+	b.SetPageLayout(PageLayoutTwoColumnLeft)
+	b.SetViewerPreferences(ViewerPreferences{HideToolbar: true})
+	b.SetOpenAction(p, OutlineDestinationBasic())
+	expected := "1 0 obj\n<</Type /Catalog /Pages 2 0 R /PageMode /UseOutlines /PageLayout /TwoColumnLeft /ViewerPreferences <</HideToolbar true>> /OpenAction [3 0 R /Fit]>>\nendobj\n"
+	testCompillation(t, expected, b.dc.compile())
+}
@@ -1,8 +1,13 @@
 package pdf
 
 import (
+	"bytes"
+	"crypto/md5"
 	"fmt"
+	"hash"
 	"io"
+	"strconv"
+	"strings"
 )
 
 // writer is a pdf writer.
@@ -10,15 +15,37 @@ type writer struct {
 	w      io.Writer
 	offset int
 	crt    *crossRefTable
-	err    error
+	digest hash.Hash
+	// encryption encrypts the strings and the streams, or nil.
+	encryption *encryption
+	// counter numbers the object streams and the cross-reference stream, or nil if they are not used.
+	counter *counter
+	// packed are the objects waiting to be packed into an object stream.
+	packed []packedObject
+	// digests are the digests of the unencrypted objects written by the writer, by their numbers, or nil if they are not recorded.
+	// An incremental update compares the objects with them to find the changed objects.
+	digests map[int][md5.Size]byte
+	err     error
+}
+
+// objectStreamCapacity is the maximum number of objects packed into an object stream.
+const objectStreamCapacity = 100
+
+// packedObject is an object packed into an object stream.
+type packedObject struct {
+	number int
+	body   string
+	entry  *crossRefEntry
 }
 
 func newWriter(w io.Writer) *writer {
 	return &writer{
-		w:      w,
-		crt:    newCrossRefTable(),
-		offset: 0,
-		err:    nil,
+		w:       w,
+		crt:     newCrossRefTable(),
+		offset:  0,
+		digest:  md5.New(),
+		digests: make(map[int][md5.Size]byte),
+		err:     nil,
 	}
 }
 
@@ -29,6 +56,33 @@ func (w *writer) hasError() bool {
 	return false
 }
 
+// encrypt makes the writer encrypt the objects with the encryption.
+func (w *writer) encrypt(e *encryption) *writer {
+	w.encryption = e
+	return w
+}
+
+// resume makes the writer append an incremental update to the written document of the length.
+func (w *writer) resume(offset int) *writer {
+	w.offset = offset
+	return w
+}
+
+// discardDigests makes the writer not record the digests of the objects,
+// which are not used if the document is not updated.
+func (w *writer) discardDigests() *writer {
+	w.digests = nil
+	return w
+}
+
+// packObjects makes the writer pack the objects other than streams into object streams,
+// and write the cross-reference stream instead of the cross-reference table.
+// The streams are numbered by c.
+func (w *writer) packObjects(c *counter) *writer {
+	w.counter = c
+	return w
+}
+
 // start writes pdf file header.
 func (w *writer) start(version string) *writer {
 	// The first line of a PDF file is a header
@@ -47,13 +101,97 @@ func (w *writer) start(version string) *writer {
 
 // finish writes cross reference table and trailer.
 func (w *writer) finish(root objectIdentifier) error {
-	startXRef := w.offset
+	return w.finishWith(newTrailer(root, 0, 0))
+}
+
+// finishWith writes cross reference table and the trailer.
+// StartXRef of the trailer is set by the writer,
+// and its size is extended to cover the objects written by the writer.
+func (w *writer) finishWith(t *trailer) error {
+	if w.counter != nil {
+		return w.finishWithStream(t)
+	}
+	t.startXRef = w.offset
 	w.writeStr(w.crt.compile())
-	t := newTrailer(root, len(w.crt.entries), startXRef)
+	if size := w.crt.size(); size > t.size {
+		t.size = size
+	}
 	w.writeStr(t.compile())
 	return w.err
 }
 
+// finishWithStream writes the object streams, and the cross-reference stream which contains the trailer.
+func (w *writer) finishWithStream(t *trailer) error {
+	w.writeObjectStreams()
+	xref := newDeflatedStream()
+	xref.objectNumber = w.counter.next()
+	w.crt.addNewEntry(xref, w.offset)
+	t.startXRef = w.offset
+	if size := w.crt.size(); size > t.size {
+		t.size = size
+	}
+	for _, e := range t.entries() {
+		xref.dict[e[0]] = e[1]
+	}
+	index, data := w.crt.compileStream()
+	xref.dict["/Type"] = "/XRef"
+	xref.dict["/W"] = "[1 4 2]"
+	xref.dict["/Index"] = index
+	xref.addBinaryDatum(data)
+	// The cross-reference stream is not encrypted.
+	compiled, err := xref.compile()
+	if err != nil {
+		return err
+	}
+	w.write(compiled)
+	w.writeStr(fmt.Sprintf("startxref\n%d\n%%%%EOF", t.startXRef))
+	return w.err
+}
+
+// writeObjectStreams writes the packed objects into object streams.
+func (w *writer) writeObjectStreams() {
+	for len(w.packed) > 0 {
+		n := len(w.packed)
+		if n > objectStreamCapacity {
+			n = objectStreamCapacity
+		}
+		s := newDeflatedStream()
+		s.objectNumber = w.counter.next()
+		var header, body bytes.Buffer
+		for i, p := range w.packed[:n] {
+			p.entry.stream = s.objectNumber
+			p.entry.index = i
+			fmt.Fprintf(&header, "%d %d ", p.number, body.Len())
+			body.WriteString(p.body)
+			body.WriteString("\n")
+		}
+		s.dict["/Type"] = "/ObjStm"
+		s.dict["/N"] = strconv.Itoa(n)
+		s.dict["/First"] = strconv.Itoa(header.Len())
+		s.addBinaryDatum(header.Bytes())
+		s.addBinaryDatum(body.Bytes())
+		w.packed = w.packed[n:]
+		w.writeObj(s)
+	}
+}
+
+// pack reserves the object to be packed into an object stream.
+// The strings in the object are encrypted with the object stream.
+// An object stream is written as soon as it is full, so that the objects waiting are bounded.
+func (w *writer) pack(obj pdfObject, compiled string) {
+	body := strings.TrimPrefix(compiled, fmt.Sprintf("%d %d obj\n", obj.refNo(), obj.age()))
+	body = strings.TrimSuffix(body, "\nendobj\n")
+	w.record(obj, []byte(compiled))
+	w.packed = append(w.packed, packedObject{
+		number: obj.refNo(),
+		body:   body,
+		entry:  w.crt.addCompressedEntry(obj),
+	})
+	if len(w.packed) >= objectStreamCapacity {
+		w.writeObjectStreams()
+	}
+}
+
 func (w *writer) writeTraversable(traversable traversableObject) *writer {
 	if !w.hasError() {
 		traversable.walk(func(obj pdfObject) {
@@ -66,25 +204,79 @@ func (w *writer) writeTraversable(traversable traversableObject) *writer {
 // writeObj writes byte expression of pdf object.
 func (w *writer) writeObj(obj pdfObject) {
 	if !w.crt.hasEntry(obj) {
+		if o, ok := obj.(stringObject); ok && w.counter != nil && obj.age() == 0 && !isEncryption(obj) {
+			w.pack(obj, o.compile())
+			return
+		}
 		w.crt.addNewEntry(obj, w.offset)
-		switch o := obj.(type) {
-		case stringObject:
-			w.writeStr(o.compile())
-		case binaryObject:
-			data, err := o.compile()
-			if err != nil {
-				w.err = fmt.Errorf("failed to write binary object: %s", err)
-			} else {
-				w.write(data)
-			}
-		default:
+		data, err := w.compile(obj)
+		if err != nil {
 			if w.err == nil {
-				w.err = fmt.Errorf("unknwon type of pdfobjct, no:%d", obj.refNo())
+				w.err = err
+			}
+			return
+		}
+		w.write(data)
+	}
+}
+
+// compile returns byte expression of pdf object, whose strings and data are encrypted if the writer encrypts them.
+// The digest of the unencrypted expression is recorded.
+func (w *writer) compile(obj pdfObject) ([]byte, error) {
+	switch o := obj.(type) {
+	case *encryption:
+		// The encryption dictionary itself is not encrypted.
+		data := []byte(o.compile())
+		w.record(obj, data)
+		return data, nil
+	case stringObject:
+		s := o.compile()
+		w.record(obj, []byte(s))
+		if w.encryption != nil {
+			var err error
+			if s, err = w.encryption.encryptStrings(obj, s); err != nil {
+				return nil, err
 			}
 		}
+		return []byte(s), nil
+	case encryptableObject:
+		data, err := o.compileWith(w.encryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write binary object: %s", err)
+		}
+		plain := data
+		if w.encryption != nil && w.digests != nil {
+			if plain, err = o.compileWith(nil); err != nil {
+				return nil, fmt.Errorf("failed to write binary object: %s", err)
+			}
+		}
+		w.record(obj, plain)
+		return data, nil
+	case binaryObject:
+		data, err := o.compile()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write binary object: %s", err)
+		}
+		w.record(obj, data)
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknwon type of pdfobjct, no:%d", obj.refNo())
+	}
+}
+
+// record records the digest of the unencrypted expression of the object.
+func (w *writer) record(obj pdfObject, data []byte) {
+	if w.digests != nil {
+		w.digests[obj.refNo()] = md5.Sum(data)
 	}
 }
 
+// isEncryption returns whether the object is the encryption dictionary, which must not be packed.
+func isEncryption(obj pdfObject) bool {
+	_, ok := obj.(*encryption)
+	return ok
+}
+
 // write writes data.
 func (w *writer) write(data []byte) {
 	if !w.hasError() {
@@ -92,6 +284,7 @@ func (w *writer) write(data []byte) {
 		if err != nil {
 			w.err = err
 		} else {
+			w.digest.Write(data[:n])
 			w.offset += n
 		}
 	}
@@ -104,6 +297,7 @@ func (w *writer) writeStr(s string) {
 		if err != nil {
 			w.err = err
 		} else {
+			io.WriteString(w.digest, s[:n])
 			w.offset += n
 		}
 	}
@@ -2,9 +2,15 @@ package pdf
 
 import (
 	"bytes"
+	"compress/zlib"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -123,3 +129,133 @@ func TestWriterError2(t *testing.T) {
 	w.writeStr("a")
 	testWriterError(t, b, w)
 }
+
+func TestWriterResume(t *testing.T) {
+	b := bytes.NewBuffer([]byte{})
+	w := newWriter(b).resume(100)
+	w.writeObj(&mockPDFObject{objectIdentifier: objectIdentifier{4, 0}, res: "first"})
+	tr := newTrailer(objectIdentifier{1, 0}, 3, 0)
+	tr.prev = 50
+	if err := w.finishWith(tr); err != nil {
+		t.Fatalf("finishWith: unexpected error:%s", err)
+	}
+	expected := "firstxref\n0 1\n0000000000 65535 f \n4 1\n0000000100 00000 n \ntrailer\n<</Root 1 0 R /Size 5 /Prev 50>>\nstartxref\n105\n%%EOF"
+	if actual := b.String(); expected != actual {
+		t.Errorf("written string is unexpected\nexpected:%s\nactual  :%s\n", expected, actual)
+	}
+	if w.offset != 100+b.Len() {
+		t.Errorf("offset is unexpected\nexpected:%d\nactual  :%d\n", 100+b.Len(), w.offset)
+	}
+}
+
+// readStream returns the dictionary and the inflated data of the stream object at the offset.
+func readStream(t *testing.T, pdf []byte, offset int) (string, []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`^\d+ \d+ obj\n<<(.*?)>>\nstream\n`).FindSubmatch(pdf[offset:])
+	if m == nil {
+		t.Fatalf("readStream: stream is not found at %d", offset)
+	}
+	dict := string(m[1])
+	length, _ := strconv.Atoi(regexp.MustCompile(`/Length (\d+)`).FindStringSubmatch(dict)[1])
+	start := offset + len(m[0])
+	r, err := zlib.NewReader(bytes.NewReader(pdf[start : start+length]))
+	if err != nil {
+		t.Fatalf("readStream: data is not deflated:%s", err)
+	}
+	data, _ := ioutil.ReadAll(r)
+	return dict, data
+}
+
+// checkXRefStream verifies that the last cross-reference stream locates all objects in use,
+// and returns the dictionary of the stream.
+// Object streams are verified unless the document is encrypted.
+func checkXRefStream(t *testing.T, pdf []byte) string {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("checkXRefStream: startxref is not found")
+	}
+	start, _ := strconv.Atoi(string(m[1]))
+	dict, data := readStream(t, pdf, start)
+	if !strings.Contains(dict, "/Type /XRef") || !strings.Contains(dict, "/W [1 4 2]") {
+		t.Fatalf("checkXRefStream: unexpected dictionary:%s", dict)
+	}
+	index := strings.Fields(regexp.MustCompile(`/Index \[([\d ]+)\]`).FindStringSubmatch(dict)[1])
+	offsets := make(map[int]int)
+	packed := make(map[int][2]int)
+	for i := 0; i < len(index); i += 2 {
+		first, _ := strconv.Atoi(index[i])
+		count, _ := strconv.Atoi(index[i+1])
+		for num := first; num < first+count; num++ {
+			if len(data) < 7 {
+				t.Fatal("checkXRefStream: entries are short")
+			}
+			entry := data[:7]
+			data = data[7:]
+			field := int(binary.BigEndian.Uint32(entry[1:]))
+			switch entry[0] {
+			case 1:
+				offsets[num] = field
+				if !bytes.HasPrefix(pdf[field:], []byte(fmt.Sprintf("%d 0 obj\n", num))) {
+					t.Errorf("checkXRefStream: entry does not point to the object %d", num)
+				}
+			case 2:
+				packed[num] = [2]int{field, int(binary.BigEndian.Uint16(entry[5:]))}
+			}
+		}
+	}
+	if strings.Contains(dict, "/Encrypt ") {
+		return dict
+	}
+	for num, p := range packed {
+		offset, ok := offsets[p[0]]
+		if !ok {
+			t.Fatalf("checkXRefStream: object stream %d is not found", p[0])
+		}
+		sdict, sdata := readStream(t, pdf, offset)
+		if !strings.Contains(sdict, "/Type /ObjStm") {
+			t.Fatalf("checkXRefStream: unexpected object stream:%s", sdict)
+		}
+		header := strings.Fields(string(sdata))
+		if actual, _ := strconv.Atoi(header[2*p[1]]); actual != num {
+			t.Errorf("checkXRefStream: object %d is not found at the index %d of the object stream %d", num, p[1], p[0])
+		}
+	}
+	return dict
+}
+
+func TestWriterObjectStreams(t *testing.T) {
+	var buf bytes.Buffer
+	c := newCounter()
+	w := newWriter(&buf).packObjects(c).start("1.5")
+	objs := make([]pdfObject, 0)
+	for i := 0; i < objectStreamCapacity+1; i++ {
+		info := newDocumentInfo(DocumentInfo{Title: fmt.Sprintf("title%d", i)})
+		info.number(c)
+		objs = append(objs, info)
+		w.writeObj(info)
+	}
+	s := newDeflatedStream()
+	s.number(c)
+	s.addStringDatum("data")
+	w.writeObj(s)
+	if err := w.finishWith(newTrailer(objectIdentifier{3, 0}, 0, 0)); err != nil {
+		t.Fatalf("finishWith: unexpected error:%s", err)
+	}
+	pdf := buf.Bytes()
+	if bytes.Contains(pdf, []byte("\nxref\n")) || bytes.Contains(pdf, []byte("title0")) {
+		t.Error("finishWith: objects must be packed and compressed")
+	}
+	dict := checkXRefStream(t, pdf)
+	if !strings.Contains(dict, "/Root 3 0 R") || !strings.Contains(dict, fmt.Sprintf("/Size %d", c.count+1)) {
+		t.Errorf("finishWith: unexpected trailer:%s", dict)
+	}
+	// The objects exceeding the capacity are packed into the second object stream.
+	if e := w.crt.entries[objs[objectStreamCapacity].refNo()]; e.stream == w.crt.entries[objs[0].refNo()].stream || e.index != 0 {
+		t.Errorf("writeObjectStreams: unexpected entry:%v", e)
+	}
+	_, data := readStream(t, pdf, w.crt.entries[w.crt.entries[objs[1].refNo()].stream].offset)
+	if !bytes.Contains(data, []byte("<</Title (title1)>>\n")) {
+		t.Errorf("writeObjectStreams: object is not packed:%q", data)
+	}
+}